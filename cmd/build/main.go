@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/convox/rack/pkg/build"
@@ -27,6 +28,8 @@ var (
 	flagAuth        string
 	flagBuildArgs   StringSlice
 	flagCache       string
+	flagCacheRepo   string
+	flagConcurrency int
 	flagDevelopment string
 	flagEnvWrapper  string
 	flagGeneration  string
@@ -35,6 +38,9 @@ var (
 	flagMethod      string
 	flagPush        string
 	flagRack        string
+	flagSbom        string
+	flagSbomDeny    string
+	flagSigningKey  string
 	flagUrl         string
 	flagRuntime     string
 
@@ -59,6 +65,8 @@ func execute() error {
 	fs.StringVar(&flagAuth, "auth", "", "docker auth data (json)")
 	fs.Var(&flagBuildArgs, "build-args", "docker build time args")
 	fs.StringVar(&flagCache, "cache", "true", "use docker cache")
+	fs.StringVar(&flagCacheRepo, "cache-repo", "", "registry repository prefix for the daemonless layer cache")
+	fs.IntVar(&flagConcurrency, "concurrency", 4, "number of images to build, pull or push at once")
 	fs.StringVar(&flagDevelopment, "development", "false", "create a development build")
 	fs.StringVar(&flagEnvWrapper, "env-wrapper", "false", "wrap with convox-env")
 	fs.StringVar(&flagGeneration, "generation", "", "app generation")
@@ -67,6 +75,9 @@ func execute() error {
 	fs.StringVar(&flagMethod, "method", "", "source method")
 	fs.StringVar(&flagPush, "push", "", "push to registry")
 	fs.StringVar(&flagRack, "rack", "convox", "rack name")
+	fs.StringVar(&flagSbom, "sbom", "false", "generate an sbom for each service image")
+	fs.StringVar(&flagSbomDeny, "sbom-denylist", "", "fail the build on packages listed in this file from the source")
+	fs.StringVar(&flagSigningKey, "signing-key", "", "kms key to sign pushed images with")
 	fs.StringVar(&flagUrl, "url", "", "source url")
 	fs.StringVar(&flagRuntime, "runtime", "ec2", "source runtime")
 
@@ -82,6 +93,18 @@ func execute() error {
 		flagAuth = v
 	}
 
+	if v := os.Getenv("BUILD_CACHE_REPO"); v != "" {
+		flagCacheRepo = v
+	}
+
+	if v := os.Getenv("BUILD_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid build concurrency: %s", v)
+		}
+		flagConcurrency = n
+	}
+
 	if v := os.Getenv("BUILD_DEVELOPMENT"); v != "" {
 		flagDevelopment = v
 	}
@@ -110,6 +133,18 @@ func execute() error {
 		flagRack = v
 	}
 
+	if v := os.Getenv("BUILD_SBOM"); v != "" {
+		flagSbom = v
+	}
+
+	if v := os.Getenv("BUILD_SBOM_DENYLIST"); v != "" {
+		flagSbomDeny = v
+	}
+
+	if v := os.Getenv("BUILD_SIGNING_KEY"); v != "" {
+		flagSigningKey = v
+	}
+
 	if v := os.Getenv("BUILD_URL"); v != "" {
 		flagUrl = v
 	}
@@ -123,6 +158,8 @@ func execute() error {
 		Auth:        flagAuth,
 		BuildArgs:   flagBuildArgs,
 		Cache:       flagCache == "true",
+		CacheRepo:   flagCacheRepo,
+		Concurrency: flagConcurrency,
 		Development: flagDevelopment == "true",
 		EnvWrapper:  flagEnvWrapper == "true",
 		Generation:  flagGeneration,
@@ -130,6 +167,9 @@ func execute() error {
 		Manifest:    flagManifest,
 		Push:        flagPush,
 		Rack:        flagRack,
+		Sbom:        flagSbom == "true",
+		SbomDeny:    flagSbomDeny,
+		SigningKey:  flagSigningKey,
 		Source:      flagUrl,
 		Runtime:     flagRuntime,
 	}
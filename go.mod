@@ -3,7 +3,6 @@ module github.com/convox/rack
 go 1.24.11
 
 require (
-	github.com/RackSec/srslog v0.0.0-20170920152354-4d2c753a4ee1
 	github.com/adhocore/gronx v1.6.5
 	github.com/aws/aws-lambda-go v1.37.0
 	github.com/aws/aws-sdk-go v1.55.8
@@ -26,6 +25,7 @@ require (
 	github.com/gorilla/websocket v1.5.0
 	github.com/inconshreveable/go-update v0.0.0-20160112193335-8152e7eb6ccf
 	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51
+	github.com/klauspost/compress v1.17.11
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/moby/patternmatcher v0.6.0
 	github.com/mweagle/Sparta v0.8.1-0.20171126182155-ead2872585dc
@@ -60,7 +60,6 @@ require (
 	github.com/gorilla/sessions v1.4.0 // indirect
 	github.com/jehiah/go-strftime v0.0.0-20171201141054-1d33003b3869 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
-	github.com/klauspost/compress v1.17.11 // indirect
 	github.com/kr/pretty v0.3.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.17 // indirect
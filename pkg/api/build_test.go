@@ -29,6 +29,22 @@ var fxBuild = structs.Build{
 	Ended:       time.Now().UTC(),
 }
 
+func TestBuildCancel(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		p.On("BuildCancel", "app1", "build1").Return(nil)
+		err := c.Post("/apps/app1/builds/build1/cancel", stdsdk.RequestOptions{}, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestBuildCancelError(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		p.On("BuildCancel", "app1", "build1").Return(fmt.Errorf("err1"))
+		err := c.Post("/apps/app1/builds/build1/cancel", stdsdk.RequestOptions{}, nil)
+		require.EqualError(t, err, "err1")
+	})
+}
+
 func TestBuildCreate(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
 		b1 := fxBuild
@@ -87,7 +103,7 @@ func TestBuildCreateError(t *testing.T) {
 
 func TestBuildExport(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
-		p.On("BuildExport", "app1", "build1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		p.On("BuildExport", "app1", "build1", mock.Anything, structs.BuildExportOptions{}).Return(nil).Run(func(args mock.Arguments) {
 			args.Get(2).(io.Writer).Write([]byte("data"))
 		})
 		res, err := c.GetStream("/apps/app1/builds/build1.tgz", stdsdk.RequestOptions{})
@@ -101,7 +117,7 @@ func TestBuildExport(t *testing.T) {
 
 func TestBuildExportError(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
-		p.On("BuildExport", "app1", "build1", mock.Anything).Return(fmt.Errorf("err1"))
+		p.On("BuildExport", "app1", "build1", mock.Anything, structs.BuildExportOptions{}).Return(fmt.Errorf("err1"))
 		res, err := c.GetStream("/apps/app1/builds/build1.tgz", stdsdk.RequestOptions{})
 		require.EqualError(t, err, "err1")
 		require.Nil(t, res)
@@ -136,7 +152,7 @@ func TestBuildImport(t *testing.T) {
 		ro := stdsdk.RequestOptions{
 			Body: strings.NewReader("data"),
 		}
-		p.On("BuildImport", "app1", mock.Anything).Return(&b1, nil).Run(func(args mock.Arguments) {
+		p.On("BuildImport", "app1", mock.Anything, structs.BuildImportOptions{}).Return(&b1, nil).Run(func(args mock.Arguments) {
 			data, err := io.ReadAll(args.Get(1).(io.Reader))
 			require.NoError(t, err)
 			require.Equal(t, "data", string(data))
@@ -150,7 +166,7 @@ func TestBuildImport(t *testing.T) {
 func TestBuildImportError(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
 		var b1 *structs.Build
-		p.On("BuildImport", "app1", mock.Anything).Return(nil, fmt.Errorf("err1"))
+		p.On("BuildImport", "app1", mock.Anything, structs.BuildImportOptions{}).Return(nil, fmt.Errorf("err1"))
 		err := c.Post("/apps/app1/builds/import", stdsdk.RequestOptions{}, b1)
 		require.EqualError(t, err, "err1")
 		require.Nil(t, b1)
@@ -213,6 +229,36 @@ func TestBuildListError(t *testing.T) {
 	})
 }
 
+func TestBuildSbom(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		d1 := []byte(`{"bomFormat":"CycloneDX"}`)
+		r1 := io.NopCloser(bytes.NewReader(d1))
+		opts := structs.BuildSbomOptions{Service: options.String("web")}
+		ro := stdsdk.RequestOptions{
+			Query: stdsdk.Query{
+				"service": "web",
+			},
+		}
+		p.On("BuildSbom", "app1", "build1", opts).Return(r1, nil)
+		res, err := c.GetStream("/apps/app1/builds/build1/sbom", ro)
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		defer res.Body.Close()
+		d2, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, d1, d2)
+	})
+}
+
+func TestBuildSbomError(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		p.On("BuildSbom", "app1", "build1", structs.BuildSbomOptions{}).Return(nil, fmt.Errorf("err1"))
+		res, err := c.GetStream("/apps/app1/builds/build1/sbom", stdsdk.RequestOptions{})
+		require.EqualError(t, err, "err1")
+		require.Nil(t, res)
+	})
+}
+
 func TestBuildUpdate(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
 		b1 := fxBuild
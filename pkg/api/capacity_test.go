@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 	"github.com/convox/stdsdk"
 	"github.com/stretchr/testify/require"
@@ -40,3 +41,45 @@ func TestCapacityGetError(t *testing.T) {
 		require.EqualError(t, err, "err1")
 	})
 }
+
+func TestCapacityPlan(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		p1 := &structs.CapacityPlan{
+			Fits:              false,
+			Instances:         3,
+			InstancesRequired: 5,
+			Pending: structs.CapacityPendings{
+				{App: "app1", Service: "web", Count: 2},
+			},
+		}
+		p2 := &structs.CapacityPlan{}
+		opts := structs.CapacityPlanOptions{
+			App:     options.String("app1"),
+			Count:   options.Int(30),
+			Memory:  options.Int(1024),
+			Service: options.String("web"),
+		}
+		ro := stdsdk.RequestOptions{
+			Query: stdsdk.Query{
+				"app":     "app1",
+				"count":   "30",
+				"memory":  "1024",
+				"service": "web",
+			},
+		}
+		p.On("CapacityPlan", opts).Return(p1, nil)
+		err := c.Get("/system/capacity/plan", ro, p2)
+		require.NoError(t, err)
+		require.Equal(t, p1, p2)
+	})
+}
+
+func TestCapacityPlanError(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		var p1 *structs.CapacityPlan
+		p.On("CapacityPlan", structs.CapacityPlanOptions{}).Return(nil, fmt.Errorf("err1"))
+		err := c.Get("/system/capacity/plan", stdsdk.RequestOptions{}, p1)
+		require.Nil(t, p1)
+		require.EqualError(t, err, "err1")
+	})
+}
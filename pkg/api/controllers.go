@@ -178,6 +178,22 @@ func (s *Server) AppUpdate(c *stdapi.Context) error {
 	return c.RenderOK()
 }
 
+func (s *Server) BuildCancel(c *stdapi.Context) error {
+	if err := s.hook("BuildCancelValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	id := c.Var("id")
+
+	err := s.provider(c).WithContext(c.Context()).BuildCancel(app, id)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
 func (s *Server) BuildCreate(c *stdapi.Context) error {
 	if err := s.hook("BuildCreateValidate", c); err != nil {
 		return err
@@ -212,7 +228,12 @@ func (s *Server) BuildExport(c *stdapi.Context) error {
 	id := c.Var("id")
 	w := c
 
-	err := s.provider(c).WithContext(c.Context()).BuildExport(app, id, w)
+	var opts structs.BuildExportOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	err := s.provider(c).WithContext(c.Context()).BuildExport(app, id, w, opts)
 	if err != nil {
 		return err
 	}
@@ -248,7 +269,12 @@ func (s *Server) BuildImport(c *stdapi.Context) error {
 	app := c.Var("app")
 	r := c
 
-	v, err := s.provider(c).WithContext(c.Context()).BuildImport(app, r)
+	var opts structs.BuildImportOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).BuildImport(app, r, opts)
 	if err != nil {
 		return err
 	}
@@ -317,6 +343,39 @@ func (s *Server) BuildLogs(c *stdapi.Context) error {
 	return nil
 }
 
+func (s *Server) BuildSbom(c *stdapi.Context) error {
+	if err := s.hook("BuildSbomValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	id := c.Var("id")
+
+	var opts structs.BuildSbomOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).BuildSbom(app, id, opts)
+	if err != nil {
+		return err
+	}
+
+	if c, ok := interface{}(v).(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if _, err := io.Copy(c, v); err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return nil
+}
+
 func (s *Server) BuildUpdate(c *stdapi.Context) error {
 	if err := s.hook("BuildUpdateValidate", c); err != nil {
 		return err
@@ -359,6 +418,28 @@ func (s *Server) CapacityGet(c *stdapi.Context) error {
 	return c.RenderJSON(v)
 }
 
+func (s *Server) CapacityPlan(c *stdapi.Context) error {
+	if err := s.hook("CapacityPlanValidate", c); err != nil {
+		return err
+	}
+
+	var opts structs.CapacityPlanOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).CapacityPlan(opts)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
 func (s *Server) CertificateApply(c *stdapi.Context) error {
 	if err := s.hook("CertificateApplyValidate", c); err != nil {
 		return err
@@ -457,6 +538,105 @@ func (s *Server) CertificateList(c *stdapi.Context) error {
 	return c.RenderJSON(v)
 }
 
+func (s *Server) DrainCreate(c *stdapi.Context) error {
+	if err := s.hook("DrainCreateValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	name := c.Value("name")
+	url := c.Value("url")
+
+	var opts structs.DrainCreateOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).DrainCreate(app, name, url, opts)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) DrainDelete(c *stdapi.Context) error {
+	if err := s.hook("DrainDeleteValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	name := c.Var("name")
+
+	err := s.provider(c).WithContext(c.Context()).DrainDelete(app, name)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
+func (s *Server) DrainList(c *stdapi.Context) error {
+	if err := s.hook("DrainListValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+
+	v, err := s.provider(c).WithContext(c.Context()).DrainList(app)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) DrainTest(c *stdapi.Context) error {
+	if err := s.hook("DrainTestValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	name := c.Var("name")
+
+	err := s.provider(c).WithContext(c.Context()).DrainTest(app, name)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
+func (s *Server) EventList(c *stdapi.Context) error {
+	if err := s.hook("EventListValidate", c); err != nil {
+		return err
+	}
+
+	var opts structs.EventListOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).EventList(opts)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
 func (s *Server) EventSend(c *stdapi.Context) error {
 	if err := s.hook("EventSendValidate", c); err != nil {
 		return err
@@ -544,6 +724,26 @@ func (s *Server) Initialize(c *stdapi.Context) error {
 	return stdapi.Errorf(404, "not available via api")
 }
 
+func (s *Server) InstanceDrain(c *stdapi.Context) error {
+	if err := s.hook("InstanceDrainValidate", c); err != nil {
+		return err
+	}
+
+	id := c.Var("id")
+
+	var opts structs.InstanceDrainOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	err := s.provider(c).WithContext(c.Context()).InstanceDrain(id, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
 func (s *Server) InstanceKeyroll(c *stdapi.Context) error {
 	if err := s.hook("InstanceKeyrollValidate", c); err != nil {
 		return err
@@ -1488,6 +1688,87 @@ func (s *Server) SystemUpdate(c *stdapi.Context) error {
 	return c.RenderOK()
 }
 
+func (s *Server) SystemWorkers(c *stdapi.Context) error {
+	if err := s.hook("SystemWorkersValidate", c); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).SystemWorkers()
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) WebhookDeliveryList(c *stdapi.Context) error {
+	if err := s.hook("WebhookDeliveryListValidate", c); err != nil {
+		return err
+	}
+
+	name := c.Var("name")
+
+	var opts structs.WebhookDeliveryListOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).WebhookDeliveryList(name, opts)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) WebhookPreview(c *stdapi.Context) error {
+	if err := s.hook("WebhookPreviewValidate", c); err != nil {
+		return err
+	}
+
+	name := c.Var("name")
+
+	var opts structs.WebhookPreviewOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(c.Context()).WebhookPreview(name, opts)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) WebhookRedeliver(c *stdapi.Context) error {
+	if err := s.hook("WebhookRedeliverValidate", c); err != nil {
+		return err
+	}
+
+	name := c.Var("name")
+	id := c.Var("id")
+
+	err := s.provider(c).WithContext(c.Context()).WebhookRedeliver(name, id)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
 func (s *Server) Workers(c *stdapi.Context) error {
 	return stdapi.Errorf(404, "not available via api")
 }
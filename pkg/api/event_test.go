@@ -3,6 +3,7 @@ package api_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
@@ -10,6 +11,43 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestEventList(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		e1 := structs.Events{
+			{Id: "event1", Action: "release:promote", Data: map[string]string{"app": "app1"}, Status: "error", Timestamp: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)},
+		}
+		e2 := structs.Events{}
+		opts := structs.EventListOptions{
+			Action: options.String("release:*"),
+			App:    options.String("app1"),
+			Since:  options.Duration(2 * time.Hour),
+			Status: options.String("error"),
+		}
+		ro := stdsdk.RequestOptions{
+			Query: stdsdk.Query{
+				"action": "release:*",
+				"app":    "app1",
+				"since":  "2h0m0s",
+				"status": "error",
+			},
+		}
+		p.On("EventList", opts).Return(e1, nil)
+		err := c.Get("/events", ro, &e2)
+		require.NoError(t, err)
+		require.Equal(t, e1, e2)
+	})
+}
+
+func TestEventListError(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		var e1 structs.Events
+		p.On("EventList", structs.EventListOptions{Since: options.Duration(24 * time.Hour)}).Return(nil, fmt.Errorf("err1"))
+		err := c.Get("/events", stdsdk.RequestOptions{}, &e1)
+		require.EqualError(t, err, "err1")
+		require.Nil(t, e1)
+	})
+}
+
 func TestEventSend(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
 		opts := structs.EventSendOptions{
@@ -26,6 +26,32 @@ var fxInstance = structs.Instance{
 	Started:   time.Now().UTC(),
 }
 
+func TestInstanceDrain(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		opts := structs.InstanceDrainOptions{
+			Terminate: options.Bool(true),
+			Timeout:   options.Int(600),
+		}
+		ro := stdsdk.RequestOptions{
+			Params: stdsdk.Params{
+				"terminate": "true",
+				"timeout":   "600",
+			},
+		}
+		p.On("InstanceDrain", "instance1", opts).Return(nil)
+		err := c.Post("/instances/instance1/drain", ro, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestInstanceDrainError(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		p.On("InstanceDrain", "instance1", structs.InstanceDrainOptions{}).Return(fmt.Errorf("err1"))
+		err := c.Post("/instances/instance1/drain", stdsdk.RequestOptions{}, nil)
+		require.EqualError(t, err, "err1")
+	})
+}
+
 func TestInstanceKeyroll(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
 		p.On("InstanceKeyroll").Return(nil)
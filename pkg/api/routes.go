@@ -13,24 +13,33 @@ func (s *Server) setupRoutes(r stdapi.Router) {
 	r.Route("SOCKET", "/apps/{name}/logs", s.AppLogs)
 	r.Route("GET", "/apps/{name}/metrics", s.AppMetrics)
 	r.Route("PUT", "/apps/{name}", s.AppUpdate)
+	r.Route("POST", "/apps/{app}/builds/{id}/cancel", s.BuildCancel)
 	r.Route("POST", "/apps/{app}/builds", s.BuildCreate)
 	r.Route("GET", "/apps/{app}/builds/{id}.tgz", s.BuildExport)
 	r.Route("GET", "/apps/{app}/builds/{id}", s.BuildGet)
 	r.Route("POST", "/apps/{app}/builds/import", s.BuildImport)
 	r.Route("GET", "/apps/{app}/builds", s.BuildList)
 	r.Route("SOCKET", "/apps/{app}/builds/{id}/logs", s.BuildLogs)
+	r.Route("GET", "/apps/{app}/builds/{id}/sbom", s.BuildSbom)
 	r.Route("PUT", "/apps/{app}/builds/{id}", s.BuildUpdate)
 	r.Route("GET", "/system/capacity", s.CapacityGet)
+	r.Route("GET", "/system/capacity/plan", s.CapacityPlan)
 	r.Route("PUT", "/apps/{app}/ssl/{service}/{port}", s.CertificateApply)
 	r.Route("POST", "/certificates", s.CertificateCreate)
 	r.Route("DELETE", "/certificates/{id}", s.CertificateDelete)
 	r.Route("POST", "/certificates/generate", s.CertificateGenerate)
 	r.Route("GET", "/certificates", s.CertificateList)
+	r.Route("POST", "/apps/{app}/drains", s.DrainCreate)
+	r.Route("DELETE", "/apps/{app}/drains/{name}", s.DrainDelete)
+	r.Route("GET", "/apps/{app}/drains", s.DrainList)
+	r.Route("POST", "/apps/{app}/drains/{name}/test", s.DrainTest)
+	r.Route("GET", "/events", s.EventList)
 	r.Route("POST", "/events", s.EventSend)
 	r.Route("DELETE", "/apps/{app}/processes/{pid}/files", s.FilesDelete)
 	r.Route("GET", "/apps/{app}/processes/{pid}/files", s.FilesDownload)
 	r.Route("POST", "/apps/{app}/processes/{pid}/files", s.FilesUpload)
 	r.Route("", "", s.Initialize)
+	r.Route("POST", "/instances/{id}/drain", s.InstanceDrain)
 	r.Route("POST", "/instances/keyroll", s.InstanceKeyroll)
 	r.Route("GET", "/instances", s.InstanceList)
 	r.Route("SOCKET", "/instances/{id}/shell", s.InstanceShell)
@@ -81,6 +90,10 @@ func (s *Server) setupRoutes(r stdapi.Router) {
 	r.Route("PUT", "/resources/{name}", s.SystemResourceUpdate)
 	r.Route("", "", s.SystemUninstall)
 	r.Route("PUT", "/system", s.SystemUpdate)
+	r.Route("GET", "/system/workers", s.SystemWorkers)
 	r.Route("GET", "/system/sync/whitelist/instances/ip", s.SystemSyncInstancesIp)
+	r.Route("GET", "/resources/{name}/deliveries", s.WebhookDeliveryList)
+	r.Route("POST", "/resources/{name}/preview", s.WebhookPreview)
+	r.Route("POST", "/resources/{name}/deliveries/{id}/redeliver", s.WebhookRedeliver)
 	r.Route("", "", s.Workers)
 }
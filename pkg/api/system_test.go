@@ -206,3 +206,24 @@ func TestSystemUpdateError(t *testing.T) {
 		require.EqualError(t, err, "err1")
 	})
 }
+
+func TestSystemWorkers(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		w1 := structs.Workers{{Name: "monitor", Interval: 5 * time.Minute, Runs: 2}}
+		w2 := structs.Workers{}
+		p.On("SystemWorkers").Return(w1, nil)
+		err := c.Get("/system/workers", stdsdk.RequestOptions{}, &w2)
+		require.NoError(t, err)
+		require.Equal(t, w1, w2)
+	})
+}
+
+func TestSystemWorkersError(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		var w1 structs.Workers
+		p.On("SystemWorkers").Return(nil, fmt.Errorf("err1"))
+		err := c.Get("/system/workers", stdsdk.RequestOptions{}, &w1)
+		require.EqualError(t, err, "err1")
+		require.Nil(t, w1)
+	})
+}
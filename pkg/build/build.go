@@ -30,6 +30,8 @@ type Options struct {
 	Auth        string
 	BuildArgs   []string
 	Cache       bool
+	CacheRepo   string
+	Concurrency int
 	Development bool
 	EnvWrapper  bool
 	Generation  string
@@ -38,6 +40,9 @@ type Options struct {
 	Output      io.Writer
 	Push        string
 	Rack        string
+	Sbom        bool
+	SbomDeny    string
+	SigningKey  string
 	Source      string
 	Runtime     string
 }
@@ -48,8 +53,10 @@ type Build struct {
 	Exec     exec.Interface
 	Provider structs.Provider
 
-	logs   bytes.Buffer
-	writer io.Writer
+	logs     bytes.Buffer
+	redactor *redactor
+	sboms    sbomSet
+	writer   io.Writer
 }
 
 // New prepares a Build instance but does NOT start any long‑running work.
@@ -73,11 +80,13 @@ func New(opts Options) (*Build, error) {
 	b.Exec = &exec.Exec{}
 
 	if opts.Output != nil {
-		b.writer = io.MultiWriter(opts.Output, &b.logs)
+		b.redactor = &redactor{writer: io.MultiWriter(opts.Output, &b.logs)}
 	} else {
-		b.writer = io.MultiWriter(os.Stdout, &b.logs)
+		b.redactor = &redactor{writer: io.MultiWriter(os.Stdout, &b.logs)}
 	}
 
+	b.writer = b.redactor
+
 	return b, nil
 }
 
@@ -250,6 +259,13 @@ func (bb *Build) buildGeneration1(dir string) error {
 }
 
 func (bb *Build) success() error {
+	bb.redactor.Flush()
+
+	sbom, err := bb.storeSboms()
+	if err != nil {
+		return err
+	}
+
 	obj, err := bb.Provider.ObjectStore(bb.App, fmt.Sprintf("build/%s/logs", bb.Id), bytes.NewReader(bb.logs.Bytes()), structs.ObjectStoreOptions{})
 	if err != nil {
 		return fmt.Errorf("store logs: %w", err)
@@ -258,6 +274,7 @@ func (bb *Build) success() error {
 	if _, err := bb.Provider.BuildUpdate(bb.App, bb.Id, structs.BuildUpdateOptions{
 		Ended: options.Time(time.Now().UTC()),
 		Logs:  options.String(obj.Url),
+		Sbom:  sbom,
 	}); err != nil {
 		return fmt.Errorf("final build update: %w", err)
 	}
@@ -280,6 +297,7 @@ func (bb *Build) success() error {
 
 func (bb *Build) fail(buildErr error) error {
 	bb.Printf("ERROR: %s\n", buildErr)
+	bb.redactor.Flush()
 
 	bb.Provider.EventSend("build:create", structs.EventSendOptions{
 		Data:  map[string]string{"app": bb.App, "id": bb.Id},
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -81,6 +82,477 @@ func TestBuildGeneration2(t *testing.T) {
 	})
 }
 
+func TestBuildGeneration2Concurrency(t *testing.T) {
+	opts := build.Options{
+		App:         "app1",
+		Auth:        "{}",
+		Cache:       true,
+		Concurrency: 2,
+		Generation:  "2",
+		Id:          "build1",
+		Rack:        "rack1",
+		Source:      "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		e.On("Run", mock.Anything, "docker", "build", "-t", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "-f", "Dockerfile", "--network", "host", ".").Return(nil).Run(func(args mock.Arguments) {
+			fmt.Fprintf(args.Get(0).(io.Writer), "build1\nbuild2\n")
+		})
+		e.On("Execute", "docker", "inspect", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "--format", "{{json .Config.Entrypoint}}").Return([]byte("[]"), nil)
+		e.On("Execute", "docker", "pull", "httpd").Return([]byte("pulling\n"), nil)
+		e.On("Execute", "docker", "tag", "httpd", "rack1/app1:web.build1").Return([]byte("tagging\n"), nil)
+		e.On("Execute", "docker", "tag", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "rack1/app1:web2.build1").Return([]byte("tagging\n"), nil)
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("ReleaseCreate", "app1", structs.ReleaseCreateOptions{Build: options.String("build1")}).Return(fxRelease2(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1", "release_id": "release2"}}).Return(nil)
+
+		err = b.Execute()
+		require.NoError(t, err)
+
+		require.ElementsMatch(t,
+			[]string{
+				"build:web2 | Building: .",
+				"build:web2 | build1",
+				"build:web2 | build2",
+				"pull:httpd | Running: docker pull httpd",
+				"tag:web    | Running: docker tag httpd rack1/app1:web.build1",
+				"tag:web2   | Running: docker tag 049f26f1b03bfca2e3af367d481a7bf1a94564ba rack1/app1:web2.build1",
+			},
+			strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"),
+		)
+	})
+}
+
+func TestBuildGeneration2ConcurrencyFailure(t *testing.T) {
+	opts := build.Options{
+		App:         "app1",
+		Auth:        "{}",
+		Cache:       true,
+		Concurrency: 2,
+		Generation:  "2",
+		Id:          "build1",
+		Rack:        "rack1",
+		Source:      "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		e.On("Run", mock.Anything, "docker", "build", "-t", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "-f", "Dockerfile", "--network", "host", ".").Return(fmt.Errorf("err1"))
+		e.On("Execute", "docker", "pull", "httpd").Return([]byte("pulling\n"), nil).Maybe()
+		e.On("Execute", "docker", "tag", "httpd", "rack1/app1:web.build1").Return([]byte("tagging\n"), nil).Maybe()
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil).Run(func(args mock.Arguments) {
+			opts := args.Get(2).(structs.BuildUpdateOptions)
+			if opts.Status != nil {
+				require.Equal(t, "failed", *opts.Status)
+			}
+		})
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1"}, Error: options.String("docker build .: err1")}).Return(nil)
+
+		err = b.Execute()
+		require.EqualError(t, err, "docker build .: err1")
+
+		// the image that failed to build is never tagged
+		e.AssertNotCalled(t, "Execute", "docker", "tag", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "rack1/app1:web2.build1")
+		require.Contains(t, out.String(), "ERROR: docker build .: err1\n")
+	})
+}
+
+func TestBuildGeneration2TargetPlatformArgs(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Manifest:   "convox4.yml",
+		Rack:       "rack1",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		// only a builder of another architecture needs emulation
+		e.On("Execute", "docker", "run", "--privileged", "--rm", "tonistiigi/binfmt", "--install", "arm64").Return([]byte("installing\n"), nil).Maybe()
+		e.On("Run", mock.Anything, "docker", "build", "-t", "e3cfd194ce094b48c808ab89ee7681b99e128aab", "-f", "Dockerfile3", "--network", "host", "--platform", "linux/arm64", "--target", "web", "--build-arg", "VERSION=1.2.3", "--build-arg", "FOO=bar", ".").Return(nil)
+		e.On("Execute", "docker", "inspect", "e3cfd194ce094b48c808ab89ee7681b99e128aab", "--format", "{{json .Config.Entrypoint}}").Return([]byte("[]"), nil)
+		e.On("Execute", "docker", "tag", "e3cfd194ce094b48c808ab89ee7681b99e128aab", "rack1/app1:web.build1").Return([]byte("tagging\n"), nil)
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("ReleaseCreate", "app1", structs.ReleaseCreateOptions{Build: options.String("build1")}).Return(fxRelease2(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1", "release_id": "release2"}}).Return(nil)
+
+		err = b.Execute()
+		require.NoError(t, err)
+	})
+}
+
+func TestBuildGeneration2Platforms(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Manifest:   "convox5.yml",
+		Push:       "push1",
+		Rack:       "rack1",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		// the builder emulates whichever architecture is not its own
+		e.On("Execute", "docker", "run", "--privileged", "--rm", "tonistiigi/binfmt", "--install", "amd64").Return([]byte("installing\n"), nil).Maybe()
+		e.On("Execute", "docker", "run", "--privileged", "--rm", "tonistiigi/binfmt", "--install", "arm64").Return([]byte("installing\n"), nil).Maybe()
+		for _, platform := range []string{"linux-amd64", "linux-arm64"} {
+			hash := "dfdff62b4bfed639ad37a961d2200273837a41eb-" + platform
+			e.On("Run", mock.Anything, "docker", "build", "-t", hash, "-f", "Dockerfile", "--network", "host", "--platform", strings.Replace(platform, "-", "/", 1), ".").Return(nil).Once()
+			e.On("Execute", "docker", "inspect", hash, "--format", "{{json .Config.Entrypoint}}").Return([]byte("[]"), nil).Once()
+			e.On("Execute", "docker", "tag", hash, "rack1/app1:web.build1-"+platform).Return([]byte("tagging\n"), nil).Once()
+			e.On("Execute", "docker", "tag", "rack1/app1:web.build1-"+platform, "push1:web.build1-"+platform).Return([]byte("tagging\n"), nil).Once()
+			e.On("Execute", "docker", "push", "push1:web.build1-"+platform).Return([]byte("pushing\n"), nil).Once()
+		}
+		e.On("Execute", "docker", "buildx", "imagetools", "create", "--tag", "push1:web.build1", "push1:web.build1-linux-amd64", "push1:web.build1-linux-arm64").Return([]byte("creating\n"), nil).Once()
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil).Run(func(args mock.Arguments) {
+			data, err := io.ReadAll(args.Get(2).(io.Reader))
+			require.NoError(t, err)
+			require.True(t, strings.HasSuffix(string(data), "Running: docker buildx imagetools create --tag push1:web.build1 push1:web.build1-linux-amd64 push1:web.build1-linux-arm64\n"))
+		})
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("ReleaseCreate", "app1", structs.ReleaseCreateOptions{Build: options.String("build1")}).Return(fxRelease2(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1", "release_id": "release2"}}).Return(nil)
+
+		err = b.Execute()
+		require.NoError(t, err)
+	})
+}
+
+func TestBuildGeneration2PlatformsWithoutPush(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Manifest:   "convox5.yml",
+		Rack:       "rack1",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1"}, Error: options.String("service web: builds for several platforms must be pushed to a registry")}).Return(nil)
+
+		err = b.Execute()
+		require.EqualError(t, err, "service web: builds for several platforms must be pushed to a registry")
+	})
+}
+
+func TestBuildGeneration2Buildpack(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Generation: "2",
+		Id:         "build1",
+		Manifest:   "convox6.yml",
+		Rack:       "rack1",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		e.On("Execute", "docker", "pull", "paketobuildpacks/builder-jammy-base").Return([]byte("pulling\n"), nil)
+		e.On("Execute", "docker", "create", "--user", "root", "--network", "host", "-v", "/var/run/docker.sock:/var/run/docker.sock", "--entrypoint", "/bin/sh", "paketobuildpacks/builder-jammy-base", "-c", `chown -R "$CNB_USER_ID:$CNB_GROUP_ID" /workspace && exec /cnb/lifecycle/creator -daemon -app=/workspace -skip-restore 8f680cf0cc12a92bf2be3fd2cdb610c7a8639eae`).Return([]byte("container1\n"), nil)
+		e.On("Run", mock.Anything, "docker", "cp", "app/.", "container1:/workspace").Return(nil)
+		e.On("Run", mock.Anything, "docker", "cp", mock.Anything, "container1:/platform").Return(nil).Run(func(args mock.Arguments) {
+			data, err := os.ReadFile(filepath.Join(strings.TrimSuffix(args.String(3), "/."), "env", "NODE_ENV"))
+			require.NoError(t, err)
+			require.Equal(t, "production", string(data))
+		})
+		e.On("Run", mock.Anything, "docker", "start", "-a", "container1").Return(nil)
+		e.On("Execute", "docker", "rm", "-f", "container1").Return([]byte("container1\n"), nil)
+		e.On("Execute", "docker", "inspect", "8f680cf0cc12a92bf2be3fd2cdb610c7a8639eae", "--format", "{{json .Config.Entrypoint}}").Return([]byte(`["/cnb/process/web"]`), nil)
+		e.On("Execute", "docker", "tag", "8f680cf0cc12a92bf2be3fd2cdb610c7a8639eae", "rack1/app1:web.build1").Return([]byte("tagging\n"), nil)
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", structs.BuildUpdateOptions{Entrypoint: options.String("/cnb/process/web")}).Return(fxBuildStarted(), nil).Once()
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("ReleaseCreate", "app1", structs.ReleaseCreateOptions{Build: options.String("build1")}).Return(fxRelease2(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1", "release_id": "release2"}}).Return(nil)
+
+		err = b.Execute()
+		require.NoError(t, err)
+
+		e.AssertCalled(t, "Execute", "docker", "rm", "-f", "container1")
+	})
+}
+
+func TestBuildGeneration2Sbom(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Rack:       "rack1",
+		Sbom:       true,
+		SbomDeny:   "sbom-denylist",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		e.On("Run", mock.Anything, "docker", "build", "-t", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "-f", "Dockerfile", "--network", "host", ".").Return(nil)
+		e.On("Execute", "docker", "inspect", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "--format", "{{json .Config.Entrypoint}}").Return([]byte("[]"), nil)
+		e.On("Execute", "docker", "pull", "httpd").Return([]byte("pulling\n"), nil)
+		e.On("Execute", "docker", "tag", "httpd", "rack1/app1:web.build1").Return([]byte("tagging\n"), nil)
+		e.On("Execute", "docker", "tag", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "rack1/app1:web2.build1").Return([]byte("tagging\n"), nil)
+		e.On("Run", mock.Anything, "syft", "scan", "docker:rack1/app1:web.build1", "-q", "-o", mock.Anything).Return(nil).Run(writeSbom(`{"bomFormat":"CycloneDX","components":[{"name":"apr","version":"1.7.0"}]}`))
+		e.On("Run", mock.Anything, "syft", "scan", "docker:rack1/app1:web2.build1", "-q", "-o", mock.Anything).Return(nil).Run(writeSbom(`{"bomFormat":"CycloneDX","components":[{"name":"openssl","version":"3.0.2"},{"name":"log4j","version":"2.17.1"}]}`))
+		p.On("ObjectStore", "app1", "build/build1/sbom", mock.Anything, structs.ObjectStoreOptions{}).Return(&structs.Object{Url: "object://app1/build/build1/sbom"}, nil).Run(func(args mock.Arguments) {
+			data, err := io.ReadAll(args.Get(2).(io.Reader))
+			require.NoError(t, err)
+			require.JSONEq(t, `{
+				"web": {"bomFormat":"CycloneDX","components":[{"name":"apr","version":"1.7.0"}]},
+				"web2": {"bomFormat":"CycloneDX","components":[{"name":"openssl","version":"3.0.2"},{"name":"log4j","version":"2.17.1"}]}
+			}`, string(data))
+		})
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.MatchedBy(func(opts structs.BuildUpdateOptions) bool {
+			return opts.Logs != nil
+		})).Return(fxBuildStarted(), nil).Run(func(args mock.Arguments) {
+			opts := args.Get(2).(structs.BuildUpdateOptions)
+			require.NotNil(t, opts.Sbom)
+			require.Equal(t, "object://app1/build/build1/sbom", *opts.Sbom)
+		}).Once()
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("ReleaseCreate", "app1", structs.ReleaseCreateOptions{Build: options.String("build1")}).Return(fxRelease2(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1", "release_id": "release2"}}).Return(nil)
+
+		err = b.Execute()
+		require.NoError(t, err)
+
+		require.Contains(t, out.String(), "Generating: sbom for web\nPackages: 1\n")
+		require.Contains(t, out.String(), "Generating: sbom for web2\nPackages: 2\n")
+	})
+}
+
+func TestBuildGeneration2SbomPlatforms(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Manifest:   "convox5.yml",
+		Push:       "push1",
+		Rack:       "rack1",
+		Sbom:       true,
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		e.On("Execute", "docker", "run", "--privileged", "--rm", "tonistiigi/binfmt", "--install", "amd64").Return([]byte("installing\n"), nil).Maybe()
+		e.On("Execute", "docker", "run", "--privileged", "--rm", "tonistiigi/binfmt", "--install", "arm64").Return([]byte("installing\n"), nil).Maybe()
+		for _, platform := range []string{"linux-amd64", "linux-arm64"} {
+			hash := "dfdff62b4bfed639ad37a961d2200273837a41eb-" + platform
+			e.On("Run", mock.Anything, "docker", "build", "-t", hash, "-f", "Dockerfile", "--network", "host", "--platform", strings.Replace(platform, "-", "/", 1), ".").Return(nil).Once()
+			e.On("Execute", "docker", "inspect", hash, "--format", "{{json .Config.Entrypoint}}").Return([]byte("[]"), nil).Once()
+			e.On("Execute", "docker", "tag", hash, "rack1/app1:web.build1-"+platform).Return([]byte("tagging\n"), nil).Once()
+			e.On("Run", mock.Anything, "syft", "scan", "docker:rack1/app1:web.build1-"+platform, "-q", "-o", mock.Anything).Return(nil).Run(writeSbom(`{"components":[{"name":"musl","version":"` + platform + `"}]}`)).Once()
+			e.On("Execute", "docker", "tag", "rack1/app1:web.build1-"+platform, "push1:web.build1-"+platform).Return([]byte("tagging\n"), nil).Once()
+			e.On("Execute", "docker", "push", "push1:web.build1-"+platform).Return([]byte("pushing\n"), nil).Once()
+		}
+		e.On("Execute", "docker", "buildx", "imagetools", "create", "--tag", "push1:web.build1", "push1:web.build1-linux-amd64", "push1:web.build1-linux-arm64").Return([]byte("creating\n"), nil).Once()
+		p.On("ObjectStore", "app1", "build/build1/sbom", mock.Anything, structs.ObjectStoreOptions{}).Return(&structs.Object{Url: "object://app1/build/build1/sbom"}, nil).Run(func(args mock.Arguments) {
+			data, err := io.ReadAll(args.Get(2).(io.Reader))
+			require.NoError(t, err)
+			require.JSONEq(t, `{
+				"web@linux/amd64": {"components":[{"name":"musl","version":"linux-amd64"}]},
+				"web@linux/arm64": {"components":[{"name":"musl","version":"linux-arm64"}]}
+			}`, string(data))
+		})
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("ReleaseCreate", "app1", structs.ReleaseCreateOptions{Build: options.String("build1")}).Return(fxRelease2(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1", "release_id": "release2"}}).Return(nil)
+
+		err = b.Execute()
+		require.NoError(t, err)
+	})
+}
+
+func TestBuildGeneration2SbomDenied(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Push:       "push1",
+		Rack:       "rack1",
+		Sbom:       true,
+		SbomDeny:   "sbom-denylist",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		e.On("Run", mock.Anything, "docker", "build", "-t", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "-f", "Dockerfile", "--network", "host", ".").Return(nil)
+		e.On("Execute", "docker", "inspect", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "--format", "{{json .Config.Entrypoint}}").Return([]byte("[]"), nil)
+		e.On("Execute", "docker", "pull", "httpd").Return([]byte("pulling\n"), nil)
+		e.On("Execute", "docker", "tag", "httpd", "rack1/app1:web.build1").Return([]byte("tagging\n"), nil)
+		e.On("Execute", "docker", "tag", "049f26f1b03bfca2e3af367d481a7bf1a94564ba", "rack1/app1:web2.build1").Return([]byte("tagging\n"), nil)
+		e.On("Run", mock.Anything, "syft", "scan", "docker:rack1/app1:web.build1", "-q", "-o", mock.Anything).Return(nil).Run(writeSbom(`{"components":[{"name":"apr","version":"1.7.0"}]}`)).Maybe()
+		e.On("Run", mock.Anything, "syft", "scan", "docker:rack1/app1:web2.build1", "-q", "-o", mock.Anything).Return(nil).Run(writeSbom(`{"components":[{"name":"openssl","version":"1.0.2k"},{"name":"log4j-core","version":"2.14.1"},{"name":"zlib","version":"1.2.11"}]}`))
+		// the web image may be pushed before the web2 scan fails but the
+		// denied web2 image never is
+		e.On("Execute", "docker", "tag", "rack1/app1:web.build1", "push1:web.build1").Return([]byte("tagging\n"), nil).Maybe()
+		e.On("Execute", "docker", "push", "push1:web.build1").Return([]byte("pushing\n"), nil).Maybe()
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1"}, Error: options.String("service web2: image has denied packages: log4j-core@2.14.1, openssl@1.0.2k")}).Return(nil)
+
+		err = b.Execute()
+		require.EqualError(t, err, "service web2: image has denied packages: log4j-core@2.14.1, openssl@1.0.2k")
+	})
+}
+
+func TestBuildGeneration2Secrets(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Manifest:   "convox3.yml",
+		Rack:       "rack1",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{*fxRelease()}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+		secret := mock.MatchedBy(func(s string) bool {
+			parts := strings.SplitN(s, ",src=", 2)
+			if len(parts) != 2 || parts[0] != "id=FOO" {
+				return false
+			}
+			data, err := os.ReadFile(parts[1])
+			return err == nil && string(data) == "bar"
+		})
+		e.On("Run", mock.Anything, "env", "DOCKER_BUILDKIT=1", "docker", "build", "-t", "283495be993623dfe4b3c9a996a3a3d78bacc411", "-f", "Dockerfile", "--network", "host", "--secret", secret, ".").Return(nil).Run(func(args mock.Arguments) {
+			fmt.Fprintf(args.Get(0).(io.Writer), "token is ")
+			fmt.Fprintf(args.Get(0).(io.Writer), "ba")
+			fmt.Fprintf(args.Get(0).(io.Writer), "r\n")
+		})
+		e.On("Execute", "docker", "inspect", "283495be993623dfe4b3c9a996a3a3d78bacc411", "--format", "{{json .Config.Entrypoint}}").Return([]byte("[]"), nil)
+		e.On("Execute", "docker", "tag", "283495be993623dfe4b3c9a996a3a3d78bacc411", "rack1/app1:web.build1").Return([]byte("tagging\n"), nil)
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil).Run(func(args mock.Arguments) {
+			data, err := io.ReadAll(args.Get(2).(io.Reader))
+			require.NoError(t, err)
+			require.NotContains(t, string(data), "bar")
+		})
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("ReleaseCreate", "app1", structs.ReleaseCreateOptions{Build: options.String("build1")}).Return(fxRelease2(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1", "release_id": "release2"}}).Return(nil)
+
+		err = b.Execute()
+		require.NoError(t, err)
+
+		require.Equal(t,
+			[]string{
+				"Building: .",
+				"token is [REDACTED]",
+				"Running: docker tag 283495be993623dfe4b3c9a996a3a3d78bacc411 rack1/app1:web.build1",
+			},
+			strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"),
+		)
+	})
+}
+
+func TestBuildGeneration2SecretsMissing(t *testing.T) {
+	opts := build.Options{
+		App:        "app1",
+		Auth:       "{}",
+		Cache:      true,
+		Generation: "2",
+		Id:         "build1",
+		Manifest:   "convox3.yml",
+		Rack:       "rack1",
+		Source:     "object://app1/object.tgz",
+	}
+
+	testBuild(t, opts, func(b *build.Build, p *structs.MockProvider, e *exec.MockInterface, out *bytes.Buffer) {
+		p.On("BuildGet", "app1", "build1").Return(fxBuildStarted(), nil).Once()
+		bdata, err := os.ReadFile("testdata/httpd.tgz")
+		require.NoError(t, err)
+		p.On("ObjectFetch", "app1", "/object.tgz").Return(io.NopCloser(bytes.NewReader(bdata)), nil)
+		p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{}, nil)
+		p.On("ObjectStore", "app1", "build/build1/logs", mock.Anything, structs.ObjectStoreOptions{}).Return(fxObject(), nil)
+		p.On("BuildUpdate", "app1", "build1", mock.Anything).Return(fxBuildStarted(), nil)
+		p.On("EventSend", "build:create", structs.EventSendOptions{Data: map[string]string{"app": "app1", "id": "build1"}, Error: options.String("build secret FOO: env var FOO is not set")}).Return(nil)
+
+		err = b.Execute()
+		require.EqualError(t, err, "build secret FOO: env var FOO is not set")
+	})
+}
+
 func TestBuildGeneration2Development(t *testing.T) {
 	opts := build.Options{
 		App:         "app1",
@@ -339,6 +811,17 @@ func fxBuildStarted() *structs.Build {
 	}
 }
 
+// writeSbom writes doc to the file a mocked syft scan is asked to write
+func writeSbom(doc string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		output := args.String(len(args) - 1)
+		file := strings.SplitN(output, "=", 2)[1]
+		if err := os.WriteFile(file, []byte(doc), 0600); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func fxObject() *structs.Object {
 	return &structs.Object{
 		Url: "object://app1/build/build1/logs",
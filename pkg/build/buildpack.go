@@ -0,0 +1,95 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/convox/rack/pkg/manifest"
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+// buildBuildpack builds the source at path into tag with the Cloud Native
+// Buildpacks lifecycle of builder. The lifecycle runs in a container of the
+// builder image and exports the image to the docker daemon. The source and
+// build args are copied into the container as the daemon may not share a
+// filesystem with this build.
+func (bb *Build) buildBuildpack(ctx context.Context, w io.Writer, path string, b manifest.ServiceBuild, builder, tag string, env map[string]string) error {
+	if err := bb.pull(ctx, w, builder); err != nil {
+		return err
+	}
+
+	creator := []string{"/cnb/lifecycle/creator", "-daemon", "-app=" + manifest.BuildpackWorkspace}
+	if !bb.Cache {
+		creator = append(creator, "-skip-restore")
+	}
+	creator = append(creator, tag)
+
+	// the lifecycle drops to the user of the builder, which needs to own the
+	// source that docker cp copies in as root
+	script := fmt.Sprintf("chown -R \"$CNB_USER_ID:$CNB_GROUP_ID\" %s && exec %s", manifest.BuildpackWorkspace, shellquote.Join(creator...))
+
+	data, err := bb.output(ctx, "docker", "create", "--user", "root", "--network", "host", "-v", "/var/run/docker.sock:/var/run/docker.sock", "--entrypoint", "/bin/sh", builder, "-c", script)
+	if err != nil {
+		return errors.New(strings.TrimSpace(string(data)))
+	}
+
+	cid := strings.TrimSpace(string(data))
+
+	defer bb.output(context.Background(), "docker", "rm", "-f", cid)
+
+	if err := bb.run(ctx, w, "docker", "cp", path+"/.", fmt.Sprintf("%s:%s", cid, manifest.BuildpackWorkspace)); err != nil {
+		return fmt.Errorf("copy source %s: %w", path, err)
+	}
+
+	if vars := buildpackEnv(b, env); len(vars) > 0 {
+		tmp, err := os.MkdirTemp("", "convox-platform-")
+		if err != nil {
+			return fmt.Errorf("tempdir: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+
+		if err := os.Mkdir(filepath.Join(tmp, "env"), 0755); err != nil {
+			return err
+		}
+
+		for k, v := range vars {
+			if err := os.WriteFile(filepath.Join(tmp, "env", k), []byte(v), 0644); err != nil {
+				return err
+			}
+		}
+
+		if err := bb.run(ctx, w, "docker", "cp", tmp+"/.", fmt.Sprintf("%s:/platform", cid)); err != nil {
+			return fmt.Errorf("copy build args: %w", err)
+		}
+	}
+
+	if err := bb.run(ctx, w, "docker", "start", "-a", cid); err != nil {
+		return fmt.Errorf("buildpack build %s: %w", path, err)
+	}
+
+	return bb.saveEntrypoint(ctx, tag)
+}
+
+// buildpackEnv returns the build args of a buildpack build, which the
+// lifecycle gives to each buildpack as platform env vars. Without ARG lines
+// to match against only the args the service names are taken from the app
+// environment.
+func buildpackEnv(b manifest.ServiceBuild, env map[string]string) map[string]string {
+	vars := b.BuildArgs()
+
+	for _, a := range b.Args {
+		if strings.Contains(a, "=") {
+			continue
+		}
+		if v, ok := env[a]; ok {
+			vars[a] = v
+		}
+	}
+
+	return vars
+}
@@ -0,0 +1,106 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+)
+
+const (
+	// kanikoCacheTTL is how long kaniko trusts a cached layer, cache images
+	// older than this are pruned by the rack
+	kanikoCacheTTL = "336h"
+
+	kanikoCacheHit  = "Using caching version of cmd:"
+	kanikoCacheMiss = "No cached layer found for cmd"
+)
+
+// cacheRepository returns the registry repository that holds the layer cache
+// for a service, or an empty string if the build should not use a cache
+func (bb *Build) cacheRepository(service string) string {
+	if !bb.Cache || bb.CacheRepo == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%s", bb.CacheRepo, service)
+}
+
+var (
+	cacheRepositoriesLock sync.Mutex
+	cacheRepositories     = map[string]bool{}
+)
+
+// ensureCacheRepository creates the ECR repository for a cache image if it
+// does not exist yet
+func ensureCacheRepository(image string) error {
+	cacheRepositoriesLock.Lock()
+	defer cacheRepositoriesLock.Unlock()
+
+	if cacheRepositories[image] {
+		return nil
+	}
+
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid cache repository: %s", image)
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("aws session: %w", err)
+	}
+
+	_, err = ecr.New(sess).CreateRepository(&ecr.CreateRepositoryInput{
+		RepositoryName: aws.String(parts[1]),
+	})
+	if ae, ok := err.(awserr.Error); ok && ae.Code() == ecr.ErrCodeRepositoryAlreadyExistsException {
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("create cache repository: %w", err)
+	}
+
+	cacheRepositories[image] = true
+
+	return nil
+}
+
+// cacheCounter passes kaniko output through while counting the layers that
+// were and were not found in the cache
+type cacheCounter struct {
+	Hits   int
+	Misses int
+
+	line   bytes.Buffer
+	writer io.Writer
+}
+
+func (c *cacheCounter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b != '\n' {
+			c.line.WriteByte(b)
+			continue
+		}
+
+		c.count(c.line.String())
+		c.line.Reset()
+	}
+
+	return c.writer.Write(p)
+}
+
+func (c *cacheCounter) count(line string) {
+	switch {
+	case strings.Contains(line, kanikoCacheHit):
+		c.Hits++
+	case strings.Contains(line, kanikoCacheMiss):
+		c.Misses++
+	}
+}
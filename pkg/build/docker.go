@@ -2,9 +2,11 @@ package build
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -46,22 +48,50 @@ func (bb *Build) buildGeneration2(dir string) error {
 		return fmt.Errorf("parse manifest: %w", err)
 	}
 
+	deny, err := bb.denylist(dir)
+	if err != nil {
+		return err
+	}
+
 	prefix := fmt.Sprintf("%s/%s", bb.Rack, bb.App)
 
 	builds := map[string]manifest.ServiceBuild{}
+	platforms := map[string][]string{}
 	pulls := map[string]struct{}{}
 	pushes := map[string]string{}
+	services := map[string]string{}
+	sources := map[string]string{}
 	tags := map[string][]string{}
 
 	for _, s := range m.Services {
 		hash := s.BuildHash(bb.Id)
 		target := fmt.Sprintf("%s:%s.%s", prefix, s.Name, bb.Id)
 
+		services[target] = s.Name
+
 		if s.Image != "" {
+			platforms[target] = []string{""}
 			pulls[s.Image] = struct{}{}
 			tags[s.Image] = append(tags[s.Image], target)
+			sources[s.Image] = fmt.Sprintf("pull:%s", s.Image)
 		} else {
+			// the image for each platform is pushed on its own before the
+			// index that points at them
+			if len(s.Build.Platforms) > 0 && bb.Push == "" {
+				return fmt.Errorf("service %s: builds for several platforms must be pushed to a registry", s.Name)
+			}
+
+			if s.Build.Builder(dir) != "" {
+				if err := s.Build.ValidateBuildpack(); err != nil {
+					return fmt.Errorf("service %s: %w", s.Name, err)
+				}
+			}
+
+			if _, ok := builds[hash]; !ok {
+				sources[hash] = fmt.Sprintf("build:%s", s.Name)
+			}
 			builds[hash] = s.Build
+			platforms[target] = buildPlatforms(s.Build)
 			tags[hash] = append(tags[hash], target)
 		}
 
@@ -70,18 +100,76 @@ func (bb *Build) buildGeneration2(dir string) error {
 		}
 	}
 
-	for hash, b := range builds {
-		bb.Printf("Building: %s\n", b.Path)
+	jobs := []job{}
+	emulated := []string{}
 
-		if err := bb.build(filepath.Join(dir, b.Path), b.Manifest, hash, env); err != nil {
-			return err
+	hashes := keys(builds)
+	sort.Strings(hashes)
+
+	for _, hash := range hashes {
+		hash, b := hash, builds[hash]
+
+		// a service without a Dockerfile is built with buildpacks
+		if builder := b.Builder(dir); builder != "" {
+			jobs = append(jobs, job{
+				name: sources[hash],
+				fn: func(ctx context.Context, w io.Writer) error {
+					fmt.Fprintf(w, "Building: %s with %s\n", b.Path, builder)
+					return bb.buildBuildpack(ctx, w, filepath.Join(dir, b.Path), b, builder, hash, env)
+				},
+			})
+
+			continue
 		}
-	}
 
-	for image := range pulls {
-		if err := bb.pull(image); err != nil {
+		secrets, err := bb.secrets(b, env)
+		if err != nil {
 			return err
 		}
+
+		for _, platform := range buildPlatforms(b) {
+			pb := platformBuild(b, platform)
+			tag := platformTag(hash, platform)
+
+			deps := []string{}
+
+			if archs := foreignArchs([]string{pb.Platform}); len(archs) > 0 {
+				emulated = append(emulated, pb.Platform)
+				deps = append(deps, "emulation")
+			}
+
+			jobs = append(jobs, job{
+				name: platformJob(sources[hash], platform),
+				deps: deps,
+				fn: func(ctx context.Context, w io.Writer) error {
+					fmt.Fprintf(w, "Building: %s\n", pb.Path)
+					return bb.build(ctx, w, filepath.Join(dir, pb.Path), pb, tag, env, secrets)
+				},
+			})
+		}
+	}
+
+	if archs := foreignArchs(emulated); len(archs) > 0 {
+		jobs = append([]job{{
+			name: "emulation",
+			fn: func(ctx context.Context, w io.Writer) error {
+				return bb.emulate(ctx, w, archs)
+			},
+		}}, jobs...)
+	}
+
+	images := keys(pulls)
+	sort.Strings(images)
+
+	for _, image := range images {
+		image := image
+
+		jobs = append(jobs, job{
+			name: sources[image],
+			fn: func(ctx context.Context, w io.Writer) error {
+				return bb.pull(ctx, w, image)
+			},
+		})
 	}
 
 	tagSrcs := keys(tags)
@@ -89,38 +177,122 @@ func (bb *Build) buildGeneration2(dir string) error {
 
 	for _, src := range tagSrcs {
 		for _, dst := range tags[src] {
-			if err := bb.tag(src, dst); err != nil {
-				return err
-			}
-			if bb.EnvWrapper {
-				if err := bb.injectConvoxEnv(dst); err != nil {
-					return err
+			for _, p := range platforms[dst] {
+				from, to, platform := platformTag(src, p), platformTag(dst, p), p
+
+				// a single image built for another platform still needs the
+				// convox-env for that platform
+				if platform == "" {
+					platform = builds[src].Platform
 				}
+
+				jobs = append(jobs, job{
+					name: platformJob(fmt.Sprintf("tag:%s", services[dst]), p),
+					deps: []string{platformJob(sources[src], p)},
+					fn: func(ctx context.Context, w io.Writer) error {
+						if err := bb.tag(ctx, w, from, to); err != nil {
+							return err
+						}
+						if bb.EnvWrapper {
+							if err := bb.injectConvoxEnv(ctx, w, to, platform); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				})
+			}
+		}
+	}
+
+	// images are scanned before they are pushed so one with a denied
+	// package never leaves the builder, the image for each platform has its
+	// own bill of materials
+	scans := map[string][]string{}
+
+	if bb.sbomEnabled() {
+		targets := keys(services)
+		sort.Strings(targets)
+
+		for _, target := range targets {
+			for _, p := range platforms[target] {
+				service := platformJob(services[target], p)
+				source := fmt.Sprintf("docker:%s", platformTag(target, p))
+				name := fmt.Sprintf("sbom:%s", service)
+
+				scans[target] = append(scans[target], name)
+
+				jobs = append(jobs, job{
+					name: name,
+					deps: []string{platformJob(fmt.Sprintf("tag:%s", services[target]), p)},
+					fn: func(ctx context.Context, w io.Writer) error {
+						return bb.sbom(ctx, w, service, source, deny)
+					},
+				})
 			}
 		}
 	}
 
 	pushSrcs := keys(pushes)
 	sort.Strings(pushSrcs)
+
 	for _, src := range pushSrcs {
-		dst := pushes[src]
-		if err := bb.tag(src, dst); err != nil {
-			return err
+		src, dst, ps := src, pushes[src], platforms[src]
+		pushed := []string{}
+
+		for _, platform := range ps {
+			from, to := platformTag(src, platform), platformTag(dst, platform)
+			name := platformJob(fmt.Sprintf("push:%s", services[src]), platform)
+
+			deps := []string{platformJob(fmt.Sprintf("tag:%s", services[src]), platform)}
+
+			deps = append(deps, scans[src]...)
+
+			jobs = append(jobs, job{
+				name: name,
+				deps: deps,
+				fn: func(ctx context.Context, w io.Writer) error {
+					if err := bb.tag(ctx, w, from, to); err != nil {
+						return err
+					}
+					return bb.push(ctx, w, to)
+				},
+			})
+
+			pushed = append(pushed, name)
 		}
-		if err := bb.push(dst); err != nil {
-			return err
+
+		// platforms are either all set or a single empty one
+		if ps[0] != "" {
+			jobs = append(jobs, job{
+				name: fmt.Sprintf("push:%s", services[src]),
+				deps: pushed,
+				fn: func(ctx context.Context, w io.Writer) error {
+					return bb.pushIndex(ctx, w, dst, ps)
+				},
+			})
+		}
+
+		if bb.SigningKey != "" {
+			jobs = append(jobs, job{
+				name: fmt.Sprintf("sign:%s", services[src]),
+				deps: []string{fmt.Sprintf("push:%s", services[src])},
+				fn: func(ctx context.Context, w io.Writer) error {
+					return bb.sign(ctx, w, dst)
+				},
+			})
 		}
 	}
 
-	return nil
+	return bb.schedule(jobs)
 }
 
-func (bb *Build) build(path, dockerfile, tag string, env map[string]string) error {
+func (bb *Build) build(ctx context.Context, w io.Writer, path string, b manifest.ServiceBuild, tag string, env map[string]string, secrets []buildSecret) error {
 	if path == "" {
 		return fmt.Errorf("build path cannot be empty")
 	}
 
-	df := filepath.Join(path, dockerfile)
+	df := filepath.Join(path, b.Manifest)
 
 	args := []string{"build"}
 	if !bb.Cache {
@@ -129,18 +301,52 @@ func (bb *Build) build(path, dockerfile, tag string, env map[string]string) erro
 
 	args = append(args, "-t", tag, "-f", df, "--network", "host")
 
-	ba, err := bb.buildArgs(df, env)
+	if b.Platform != "" {
+		args = append(args, "--platform", b.Platform)
+	}
+
+	ba, err := bb.buildArgs(df, b, env)
 	if err != nil {
 		return err
 	}
 	args = append(args, ba...)
+
+	command := "docker"
+
+	// secrets are mounted by BuildKit for the RUN steps that ask for them and
+	// are never written to a layer
+	if len(secrets) > 0 {
+		tmp, err := os.MkdirTemp("", "convox-secrets-")
+		if err != nil {
+			return fmt.Errorf("tempdir: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+
+		if err := writeSecrets(tmp, secrets); err != nil {
+			return err
+		}
+
+		for _, s := range secrets {
+			args = append(args, "--secret", fmt.Sprintf("id=%s,src=%s", s.Id, filepath.Join(tmp, s.Id)))
+		}
+
+		command = "env"
+		args = append([]string{"DOCKER_BUILDKIT=1", "docker"}, args...)
+	}
+
 	args = append(args, path)
 
-	if err := bb.Exec.Run(bb.writer, "docker", args...); err != nil {
+	if err := bb.run(ctx, w, command, args...); err != nil {
 		return fmt.Errorf("docker build %s: %w", path, err)
 	}
 
-	data, err := bb.Exec.Execute("docker", "inspect", tag, "--format", "{{json .Config.Entrypoint}}")
+	return bb.saveEntrypoint(ctx, tag)
+}
+
+// saveEntrypoint records the entrypoint of the image built to tag on the build
+// so the rack can run commands through it
+func (bb *Build) saveEntrypoint(ctx context.Context, tag string) error {
+	data, err := bb.output(ctx, "docker", "inspect", tag, "--format", "{{json .Config.Entrypoint}}")
 	if err != nil {
 		return fmt.Errorf("docker inspect entrypoint: %w", err)
 	}
@@ -167,28 +373,42 @@ var (
 )
 
 // buildArgs returns CLI "--build-arg" flags derived from ARG statements that
-// have matches in the supplied env map.  It also adds "--target development"
-// automatically when the Build is in development mode and the Dockerfile has a
-// stage named "development".
-func (bb *Build) buildArgs(dockerfile string, env map[string]string) ([]string, error) {
+// have matches in the supplied env map or in the literal args of the service
+// build, which take precedence.  It adds "--target" for the stage set on the
+// service build, or "--target development" when the Build is in development
+// mode and the Dockerfile has a stage named "development".
+func (bb *Build) buildArgs(dockerfile string, b manifest.ServiceBuild, env map[string]string) ([]string, error) {
 	fd, err := os.Open(dockerfile)
 	if err != nil {
 		return nil, fmt.Errorf("open Dockerfile: %w", err)
 	}
 	defer fd.Close()
 
+	values := map[string]string{}
+
+	for k, v := range env {
+		values[k] = v
+	}
+
+	for k, v := range b.BuildArgs() {
+		values[k] = v
+	}
+
 	scanner := bufio.NewScanner(fd)
 	var args []string
 
+	development := false
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		if bb.Development && reFrom.MatchString(line) {
 			args = append(args, "--target", "development")
+			development = true
 		}
 
 		if m := reArg.FindStringSubmatch(line); m != nil {
 			key := strings.TrimSpace(m[1])
-			if val, ok := env[key]; ok {
+			if val, ok := values[key]; ok {
 				args = append(args, "--build-arg", fmt.Sprintf("%s=%s", key, val))
 			}
 		}
@@ -198,18 +418,22 @@ func (bb *Build) buildArgs(dockerfile string, env map[string]string) ([]string,
 		return nil, fmt.Errorf("scan Dockerfile: %w", err)
 	}
 
+	if b.Target != "" && !development {
+		args = append([]string{"--target", b.Target}, args...)
+	}
+
 	return args, nil
 }
 
-func (bb *Build) injectConvoxEnv(tag string) error {
-	bb.Printf("Injecting: convox-env\n")
+func (bb *Build) injectConvoxEnv(ctx context.Context, w io.Writer, tag, platform string) error {
+	fmt.Fprintf(w, "Injecting: convox-env\n")
 
 	var (
 		cmd        []string
 		entrypoint []string
 	)
 
-	data, err := bb.Exec.Execute("docker", "inspect", tag, "--format", "{{json .Config.Cmd}}")
+	data, err := bb.output(ctx, "docker", "inspect", tag, "--format", "{{json .Config.Cmd}}")
 	if err != nil {
 		return fmt.Errorf("inspect cmd: %w", err)
 	}
@@ -217,7 +441,7 @@ func (bb *Build) injectConvoxEnv(tag string) error {
 		return fmt.Errorf("parse cmd json: %w", err)
 	}
 
-	data, err = bb.Exec.Execute("docker", "inspect", tag, "--format", "{{json .Config.Entrypoint}}")
+	data, err = bb.output(ctx, "docker", "inspect", tag, "--format", "{{json .Config.Entrypoint}}")
 	if err != nil {
 		return fmt.Errorf("inspect entrypoint: %w", err)
 	}
@@ -242,7 +466,7 @@ func (bb *Build) injectConvoxEnv(tag string) error {
 	}
 	defer os.RemoveAll(tmp)
 
-	if _, err := bb.Exec.Execute("cp", "/go/bin/convox-env", filepath.Join(tmp, "convox-env")); err != nil {
+	if _, err := bb.output(ctx, "cp", convoxEnvPath("/go/bin", platform), filepath.Join(tmp, "convox-env")); err != nil {
 		return fmt.Errorf("copy convox-env: %w", err)
 	}
 
@@ -250,32 +474,38 @@ func (bb *Build) injectConvoxEnv(tag string) error {
 		return fmt.Errorf("write Dockerfile: %w", err)
 	}
 
-	if _, err := bb.Exec.Execute("docker", "build", "-t", tag, tmp); err != nil {
+	args := []string{"build", "-t", tag}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	args = append(args, tmp)
+
+	if _, err := bb.output(ctx, "docker", args...); err != nil {
 		return fmt.Errorf("rebuild with env wrapper: %w", err)
 	}
 
 	return nil
 }
 
-func (bb *Build) pull(tag string) error {
-	bb.Printf("Running: docker pull %s\n", tag)
-	if data, err := bb.Exec.Execute("docker", "pull", tag); err != nil {
+func (bb *Build) pull(ctx context.Context, w io.Writer, tag string) error {
+	fmt.Fprintf(w, "Running: docker pull %s\n", tag)
+	if data, err := bb.output(ctx, "docker", "pull", tag); err != nil {
 		return errors.New(strings.TrimSpace(string(data)))
 	}
 	return nil
 }
 
-func (bb *Build) push(tag string) error {
-	bb.Printf("Running: docker push %s\n", tag)
-	if data, err := bb.Exec.Execute("docker", "push", tag); err != nil {
+func (bb *Build) push(ctx context.Context, w io.Writer, tag string) error {
+	fmt.Fprintf(w, "Running: docker push %s\n", tag)
+	if data, err := bb.output(ctx, "docker", "push", tag); err != nil {
 		return errors.New(strings.TrimSpace(string(data)))
 	}
 	return nil
 }
 
-func (bb *Build) tag(from, to string) error {
-	bb.Printf("Running: docker tag %s %s\n", from, to)
-	if data, err := bb.Exec.Execute("docker", "tag", from, to); err != nil {
+func (bb *Build) tag(ctx context.Context, w io.Writer, from, to string) error {
+	fmt.Fprintf(w, "Running: docker tag %s %s\n", from, to)
+	if data, err := bb.output(ctx, "docker", "tag", from, to); err != nil {
 		return errors.New(strings.TrimSpace(string(data)))
 	}
 	return nil
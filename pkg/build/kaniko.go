@@ -3,6 +3,7 @@ package build
 import (
 	"archive/tar"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -21,16 +22,20 @@ import (
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/kballard/go-shellquote"
 )
 
 // kanikoWorkspaceDir is the directory baked into the builder image that Kaniko writes to.
 const kanikoWorkspaceDir = "/workspace"
 
-// convoxEnvBinary is the path to the convox-env binary in the builder image.
-const convoxEnvBinary = "/busybox/convox-env"
+// convoxEnvDir is the directory holding the convox-env binaries in the builder image.
+const convoxEnvDir = "/busybox"
 
 // tarPathFor generates a safe on‑disk tar filename for an image tag by replacing
 // characters that are illegal in file names.
@@ -73,21 +78,53 @@ func (bb *Build) buildGeneration2Daemonless(dir string) error {
 		return fmt.Errorf("loading manifest: %w", err)
 	}
 
+	deny, err := bb.denylist(dir)
+	if err != nil {
+		return err
+	}
+
 	prefix := fmt.Sprintf("%s/%s", bb.Rack, bb.App)
 
 	builds := map[string]manifest.ServiceBuild{}
+	owners := map[string]string{}
 	pulls := map[string]bool{}
 	pushes := map[string]string{}
+	services := map[string]string{}
+	sources := map[string]string{}
 	tags := map[string][]string{}
 
 	for _, s := range m.Services {
 		hash := s.BuildHash(bb.Id)
 		to := fmt.Sprintf("%s:%s.%s", prefix, s.Name, bb.Id)
 
+		services[to] = s.Name
+
 		if s.Image != "" {
 			pulls[s.Image] = true
 			tags[s.Image] = append(tags[s.Image], to)
+			sources[s.Image] = fmt.Sprintf("pull:%s", s.Image)
 		} else {
+			// the images for each platform are pushed with the index that
+			// points at them
+			if len(s.Build.Platforms) > 0 && bb.Push == "" {
+				return fmt.Errorf("service %s: builds for several platforms must be pushed to a registry", s.Name)
+			}
+
+			// the buildpack lifecycle exports its image to a docker daemon
+			if s.Build.Builder(dir) != "" {
+				return fmt.Errorf("service %s: buildpack builds require the docker build runtime", s.Name)
+			}
+
+			// kaniko can not register emulation itself, building for another
+			// architecture needs it registered on the host already
+			if archs := unemulated(foreignArchs(append([]string{s.Build.Platform}, s.Build.Platforms...))); len(archs) > 0 {
+				return fmt.Errorf("service %s: building for %s requires emulation that is not registered on the build instance, use the docker build runtime", s.Name, strings.Join(archs, ", "))
+			}
+
+			if _, ok := builds[hash]; !ok {
+				owners[hash] = s.Name
+				sources[hash] = fmt.Sprintf("build:%s", s.Name)
+			}
 			builds[hash] = s.Build
 			tags[hash] = append(tags[hash], to)
 		}
@@ -97,18 +134,59 @@ func (bb *Build) buildGeneration2Daemonless(dir string) error {
 		}
 	}
 
-	for hash, b := range builds {
-		bb.Printf("Building: %s\n", b.Path)
+	jobs := []job{}
 
-		if err := bb.buildDaemonless(filepath.Join(dir, b.Path), b.Manifest, hash, env); err != nil {
+	hashes := make([]string, 0, len(builds))
+	for hash := range builds {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	// kaniko unpacks each image it builds over the root filesystem of the
+	// builder so only one build can run at a time, each waits for the last
+	previous := []string{}
+
+	for _, hash := range hashes {
+		hash, b, service := hash, builds[hash], owners[hash]
+
+		secrets, err := bb.secrets(b, env)
+		if err != nil {
 			return err
 		}
+
+		for _, platform := range buildPlatforms(b) {
+			pb := platformBuild(b, platform)
+			tag := platformTag(hash, platform)
+			name := platformJob(sources[hash], platform)
+
+			jobs = append(jobs, job{
+				name: name,
+				deps: previous,
+				fn: func(ctx context.Context, w io.Writer) error {
+					fmt.Fprintf(w, "Building: %s\n", pb.Path)
+					return bb.buildDaemonless(ctx, w, service, filepath.Join(dir, pb.Path), pb, tag, env, secrets)
+				},
+			})
+
+			previous = []string{name}
+		}
 	}
 
+	images := make([]string, 0, len(pulls))
 	for image := range pulls {
-		if err := bb.pullDaemonless(image); err != nil {
-			return err
-		}
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	for _, image := range images {
+		image := image
+
+		jobs = append(jobs, job{
+			name: sources[image],
+			fn: func(ctx context.Context, w io.Writer) error {
+				return bb.pullDaemonless(ctx, w, image)
+			},
+		})
 	}
 
 	tagFroms := make([]string, 0, len(tags))
@@ -118,52 +196,158 @@ func (bb *Build) buildGeneration2Daemonless(dir string) error {
 	sort.Strings(tagFroms)
 
 	for _, from := range tagFroms {
-		tos := tags[from]
+		from, tos := from, tags[from]
+		ps := []string{""}
+
+		if b, ok := builds[from]; ok {
+			ps = buildPlatforms(b)
+		}
+
+		ready := []string{}
+
+		for _, p := range ps {
+			name := platformJob(sources[from], p)
 
-		if bb.EnvWrapper {
-			if err := bb.injectConvoxEnvDaemonless(from); err != nil {
-				return err
+			if bb.EnvWrapper {
+				tag, platform := platformTag(from, p), p
+
+				// a single image built for another platform still needs the
+				// convox-env for that platform
+				if platform == "" {
+					platform = builds[from].Platform
+				}
+
+				jobs = append(jobs, job{
+					name: fmt.Sprintf("inject:%s", name),
+					deps: []string{name},
+					fn: func(ctx context.Context, w io.Writer) error {
+						return bb.injectConvoxEnvDaemonless(w, tag, platform)
+					},
+				})
+
+				name = fmt.Sprintf("inject:%s", name)
 			}
+
+			ready = append(ready, name)
 		}
 
 		for _, to := range tos {
-			destination := pushes[to]
-			if err := bb.tagAndPushDaemonless(from, destination); err != nil {
-				return err
+			destination, deps := pushes[to], ready
+
+			// images are scanned before they are pushed so one with a
+			// denied package never leaves the builder, the image for each
+			// platform has its own bill of materials
+			if bb.sbomEnabled() {
+				scans := []string{}
+
+				for i, p := range ps {
+					service := platformJob(services[to], p)
+					source := fmt.Sprintf("docker-archive:%s", tarPathFor(platformTag(from, p)))
+					scan := fmt.Sprintf("sbom:%s", service)
+
+					if pulls[from] {
+						source = fmt.Sprintf("registry:%s", from)
+					}
+
+					jobs = append(jobs, job{
+						name: scan,
+						deps: []string{ready[i]},
+						fn: func(ctx context.Context, w io.Writer) error {
+							return bb.sbom(ctx, w, service, source, deny)
+						},
+					})
+
+					scans = append(scans, scan)
+				}
+
+				deps = append(scans, ready...)
+			}
+
+			jobs = append(jobs, job{
+				name: fmt.Sprintf("push:%s", services[to]),
+				deps: deps,
+				fn: func(ctx context.Context, w io.Writer) error {
+					// platforms are either all set or a single empty one
+					if ps[0] != "" {
+						return bb.pushIndexDaemonless(ctx, w, from, ps, destination)
+					}
+					return bb.tagAndPushDaemonless(ctx, w, from, destination)
+				},
+			})
+
+			if bb.SigningKey != "" {
+				jobs = append(jobs, job{
+					name: fmt.Sprintf("sign:%s", services[to]),
+					deps: []string{fmt.Sprintf("push:%s", services[to])},
+					fn: func(ctx context.Context, w io.Writer) error {
+						return bb.sign(ctx, w, destination)
+					},
+				})
 			}
 		}
 	}
 
-	return nil
+	return bb.schedule(jobs)
 }
 
 // buildDaemonless builds a single Dockerfile context with Kaniko and stores the
-// resulting OCI‑image tarball in workspaceDir.
-func (bb *Build) buildDaemonless(path, dockerfile, tag string, env map[string]string) error {
+// resulting OCI‑image tarball in workspaceDir. Layers are cached in a registry
+// repository for the service the image is built for.
+func (bb *Build) buildDaemonless(ctx context.Context, w io.Writer, service, path string, b manifest.ServiceBuild, tag string, env map[string]string, secrets []buildSecret) error {
 	contextDir := "dir://" + path
 	tarPath := tarPathFor(tag)
 
 	args := []string{
-		"--dockerfile", dockerfile,
+		"--dockerfile", b.Manifest,
 		"--context", contextDir,
 		"--tarPath", tarPath,
 		"--destination", tag,
 		"--ignore-path=" + path,
 		"--no-push",
-		"--cache=false",
 	}
 
-	df := filepath.Join(path, dockerfile)
-	ba, err := bb.buildArgs(df, env)
+	cache := bb.cacheRepository(service)
+
+	if cache != "" {
+		if err := ensureCacheRepository(cache); err != nil {
+			return err
+		}
+
+		args = append(args, "--cache=true", "--cache-repo", cache, "--cache-ttl", kanikoCacheTTL)
+	} else {
+		args = append(args, "--cache=false")
+	}
+
+	if b.Platform != "" {
+		args = append(args, "--custom-platform", b.Platform)
+	}
+
+	df := filepath.Join(path, b.Manifest)
+	ba, err := bb.buildArgs(df, b, env)
 	if err != nil {
 		return err
 	}
 	args = append(args, ba...)
 
-	if err := bb.Exec.Run(bb.writer, "/kaniko/executor", args...); err != nil {
+	if len(secrets) > 0 {
+		if err := writeSecrets(kanikoSecretsDir, secrets); err != nil {
+			return err
+		}
+		defer os.RemoveAll(kanikoSecretsDir)
+
+		args = append(args, "--ignore-path="+kanikoSecretsDir)
+	}
+
+	cc := &cacheCounter{writer: w}
+
+	if err := bb.run(ctx, cc, "/kaniko/executor", args...); err != nil {
 		return err
 	}
 
+	if cache != "" {
+		fmt.Fprintf(w, "Cache: %d hits, %d misses\n", cc.Hits, cc.Misses)
+	}
+
 	img, err := tarball.ImageFromPath(tarPath, nil)
 	if err != nil {
 		return fmt.Errorf("loading kaniko image tar: %w", err)
@@ -188,8 +372,8 @@ func (bb *Build) buildDaemonless(path, dockerfile, tag string, env map[string]st
 
 // injectConvoxEnvDaemonless mutates the already‑built tarball by prepending the
 // /convox-env wrapper and rewriting the entrypoint.
-func (bb *Build) injectConvoxEnvDaemonless(tag string) error {
-	bb.Printf("Injecting: convox-env\n")
+func (bb *Build) injectConvoxEnvDaemonless(w io.Writer, tag, platform string) error {
+	fmt.Fprintf(w, "Injecting: convox-env\n")
 
 	originalTar := tarPathFor(tag)
 	injectedTar := injectedTarPathFor(tag)
@@ -199,9 +383,11 @@ func (bb *Build) injectConvoxEnvDaemonless(tag string) error {
 		return fmt.Errorf("load image: %w", err)
 	}
 
-	bin, err := os.ReadFile(convoxEnvBinary)
+	binary := convoxEnvPath(convoxEnvDir, platform)
+
+	bin, err := os.ReadFile(binary)
 	if err != nil {
-		return fmt.Errorf("read %s: %w", convoxEnvBinary, err)
+		return fmt.Errorf("read %s: %w", binary, err)
 	}
 
 	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
@@ -242,44 +428,102 @@ func (bb *Build) injectConvoxEnvDaemonless(tag string) error {
 
 // pullDaemonless pulls a remote image to the local cache so that later we can
 // re‑tag and push it. Uses registry auth when needed.
-func (bb *Build) pullDaemonless(tag string) error {
-	bb.Printf("Running: docker pull %s\n", tag)
+func (bb *Build) pullDaemonless(ctx context.Context, w io.Writer, tag string) error {
+	fmt.Fprintf(w, "Running: docker pull %s\n", tag)
 
 	auth, err := ecrAuthenticator()
 	if err != nil {
 		return err
 	}
-	if _, err := crane.Pull(tag, crane.WithAuth(auth)); err != nil {
+	if _, err := crane.Pull(tag, crane.WithAuth(auth), crane.WithContext(ctx)); err != nil {
 		return fmt.Errorf("pull: %w", err)
 	}
 	return nil
 }
 
-func (bb *Build) tagAndPushDaemonless(from, to string) error {
-	bb.Printf("Running: tag and push %s\n", to)
+func (bb *Build) tagAndPushDaemonless(ctx context.Context, w io.Writer, from, to string) error {
+	fmt.Fprintf(w, "Running: tag and push %s\n", to)
 
-	imgTar := injectedTarPathFor(from)
-	img, err := tarball.ImageFromPath(imgTar, nil)
-	if err != nil && os.IsNotExist(err) {
-		imgTar = tarPathFor(from)
-		img, err = tarball.ImageFromPath(imgTar, nil)
-	}
+	img, err := loadDaemonless(from)
 	if err != nil {
-		return fmt.Errorf("load tar: %w", err)
+		return err
 	}
 
 	auth, err := ecrAuthenticator()
 	if err != nil {
 		return err
 	}
-	if err := crane.Push(img, to, crane.WithAuth(auth)); err != nil {
+	if err := crane.Push(img, to, crane.WithAuth(auth), crane.WithContext(ctx)); err != nil {
 		return fmt.Errorf("push: %w", err)
 	}
 
-	bb.Printf("Pushed %s\n", to)
+	fmt.Fprintf(w, "Pushed %s\n", to)
+	return nil
+}
+
+// pushIndexDaemonless pushes an OCI index to to that points at the image built
+// from for each platform
+func (bb *Build) pushIndexDaemonless(ctx context.Context, w io.Writer, from string, platforms []string, to string) error {
+	fmt.Fprintf(w, "Running: push index %s\n", to)
+
+	adds := []mutate.IndexAddendum{}
+
+	for _, p := range platforms {
+		img, err := loadDaemonless(platformTag(from, p))
+		if err != nil {
+			return err
+		}
+
+		cfg, err := img.ConfigFile()
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		adds = append(adds, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &v1.Platform{
+					Architecture: cfg.Architecture,
+					OS:           cfg.OS,
+					Variant:      cfg.Variant,
+				},
+			},
+		})
+	}
+
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), adds...)
+
+	ref, err := name.ParseReference(to)
+	if err != nil {
+		return fmt.Errorf("parse tag: %w", err)
+	}
+
+	auth, err := ecrAuthenticator()
+	if err != nil {
+		return err
+	}
+	if err := remote.WriteIndex(ref, idx, remote.WithAuth(auth), remote.WithContext(ctx)); err != nil {
+		return fmt.Errorf("push index: %w", err)
+	}
+
+	fmt.Fprintf(w, "Pushed %s\n", to)
 	return nil
 }
 
+// loadDaemonless loads the image built for tag, with convox-env if it was
+// injected
+func loadDaemonless(tag string) (v1.Image, error) {
+	img, err := tarball.ImageFromPath(injectedTarPathFor(tag), nil)
+	if err != nil && os.IsNotExist(err) {
+		img, err = tarball.ImageFromPath(tarPathFor(tag), nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load tar: %w", err)
+	}
+
+	return img, nil
+}
+
 var (
 	ecrOnce sync.Once
 	ecrAuth authn.Authenticator
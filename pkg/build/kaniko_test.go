@@ -0,0 +1,36 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGeneration2DaemonlessUnemulated(t *testing.T) {
+	defer func(d string) { binfmtDir = d }(binfmtDir)
+	binfmtDir = t.TempDir()
+
+	dir := t.TempDir()
+
+	manifest := "services:\n  web:\n    build:\n      path: .\n      platforms:\n        - linux/amd64\n        - linux/arm64\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "convox.yml"), []byte(manifest), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+
+	p := &structs.MockProvider{}
+	p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{{Id: "release1", App: "app1"}}, nil)
+	p.On("ReleaseGet", "app1", "release1").Return(&structs.Release{Id: "release1", App: "app1"}, nil)
+
+	bb, err := New(Options{App: "app1", Generation: "2", Id: "build1", Manifest: "convox.yml", Push: "push1", Rack: "rack1", Runtime: RuntimeDaemonless})
+	require.NoError(t, err)
+
+	bb.Provider = p
+
+	// whichever of the two architectures is not the host's has no emulation
+	err = bb.buildGeneration2Daemonless(dir)
+	require.Error(t, err)
+	require.Regexp(t, `^service web: building for (amd64|arm64) requires emulation that is not registered on the build instance, use the docker build runtime$`, err.Error())
+}
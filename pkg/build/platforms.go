@@ -0,0 +1,152 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/convox/rack/pkg/manifest"
+)
+
+// binfmtImage registers the QEMU handlers the docker daemon uses to run the
+// RUN steps of an image built for another architecture
+const binfmtImage = "tonistiigi/binfmt"
+
+// binfmtDir lists the emulation handlers registered with the kernel
+var binfmtDir = "/proc/sys/fs/binfmt_misc"
+
+// qemuArchs maps architectures to the names of their QEMU handlers
+var qemuArchs = map[string]string{
+	"386":     "i386",
+	"amd64":   "x86_64",
+	"arm":     "arm",
+	"arm64":   "aarch64",
+	"ppc64le": "ppc64le",
+	"riscv64": "riscv64",
+	"s390x":   "s390x",
+}
+
+// buildPlatforms returns the platforms each image of a service build is built
+// for. A build without platforms is a single image, shown as an empty platform.
+func buildPlatforms(b manifest.ServiceBuild) []string {
+	if len(b.Platforms) == 0 {
+		return []string{""}
+	}
+
+	return b.Platforms
+}
+
+// platformBuild returns the build of the image for one platform
+func platformBuild(b manifest.ServiceBuild, platform string) manifest.ServiceBuild {
+	if platform != "" {
+		b.Platform = platform
+		b.Platforms = nil
+	}
+
+	return b
+}
+
+// platformTag returns the tag of the image built for one platform of a build
+// that makes several
+func platformTag(tag, platform string) string {
+	if platform == "" {
+		return tag
+	}
+
+	return fmt.Sprintf("%s-%s", tag, strings.ReplaceAll(platform, "/", "-"))
+}
+
+// platformJob returns the name of the job for one platform of a build
+func platformJob(name, platform string) string {
+	if platform == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s@%s", name, platform)
+}
+
+func platformArch(platform string) string {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// foreignArchs returns the architectures the builder can only run under
+// emulation
+func foreignArchs(platforms []string) []string {
+	archs := map[string]bool{}
+
+	for _, p := range platforms {
+		if a := platformArch(p); a != "" && a != runtime.GOARCH {
+			archs[a] = true
+		}
+	}
+
+	as := keys(archs)
+	sort.Strings(as)
+
+	return as
+}
+
+// unemulated returns the architectures the host has no emulation handler
+// registered for
+func unemulated(archs []string) []string {
+	missing := []string{}
+
+	for _, a := range archs {
+		name, ok := qemuArchs[a]
+		if !ok {
+			missing = append(missing, a)
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(binfmtDir, "qemu-"+name)); err != nil {
+			missing = append(missing, a)
+		}
+	}
+
+	return missing
+}
+
+// convoxEnvPath returns the convox-env binary in dir that runs on platform,
+// the builder ships one cross compiled for each architecture it supports
+func convoxEnvPath(dir, platform string) string {
+	if a := platformArch(platform); a != "" && a != runtime.GOARCH {
+		return filepath.Join(dir, fmt.Sprintf("convox-env-%s", a))
+	}
+
+	return filepath.Join(dir, "convox-env")
+}
+
+func (bb *Build) emulate(ctx context.Context, w io.Writer, archs []string) error {
+	fmt.Fprintf(w, "Running: docker run --privileged --rm %s --install %s\n", binfmtImage, strings.Join(archs, ","))
+	if data, err := bb.output(ctx, "docker", "run", "--privileged", "--rm", binfmtImage, "--install", strings.Join(archs, ",")); err != nil {
+		return errors.New(strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// pushIndex pushes an index to tag that points at the image already pushed
+// for each platform
+func (bb *Build) pushIndex(ctx context.Context, w io.Writer, tag string, platforms []string) error {
+	args := []string{"buildx", "imagetools", "create", "--tag", tag}
+
+	for _, p := range platforms {
+		args = append(args, platformTag(tag, p))
+	}
+
+	fmt.Fprintf(w, "Running: docker %s\n", strings.Join(args, " "))
+	if data, err := bb.output(ctx, "docker", args...); err != nil {
+		return errors.New(strings.TrimSpace(string(data)))
+	}
+	return nil
+}
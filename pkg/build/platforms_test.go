@@ -0,0 +1,21 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnemulated(t *testing.T) {
+	dir := t.TempDir()
+
+	defer func(d string) { binfmtDir = d }(binfmtDir)
+	binfmtDir = dir
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "qemu-aarch64"), []byte("enabled\n"), 0644))
+
+	require.Equal(t, []string{}, unemulated([]string{"arm64"}))
+	require.Equal(t, []string{"amd64", "mips"}, unemulated([]string{"amd64", "arm64", "mips"}))
+}
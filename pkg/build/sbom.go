@@ -0,0 +1,192 @@
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+)
+
+// sbomFormat is the format syft writes each software bill of materials in
+const sbomFormat = "cyclonedx-json"
+
+// sbomSet collects the bill of materials for each service as the jobs that
+// generate them finish, the images of a service built for several platforms
+// each have their own as service@platform
+type sbomSet struct {
+	lock sync.Mutex
+	docs map[string]json.RawMessage
+}
+
+func (s *sbomSet) add(service string, doc json.RawMessage) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.docs == nil {
+		s.docs = map[string]json.RawMessage{}
+	}
+
+	s.docs[service] = doc
+}
+
+// sbomEnabled returns true if the build generates a bill of materials for
+// each service image, a denylist needs one to check against
+func (bb *Build) sbomEnabled() bool {
+	return bb.Sbom || bb.SbomDeny != ""
+}
+
+// denyRule matches packages by name and, if set, version. Both may use * and
+// ? as wildcards.
+type denyRule struct {
+	name    *regexp.Regexp
+	version *regexp.Regexp
+}
+
+func (r denyRule) match(name, version string) bool {
+	if !r.name.MatchString(name) {
+		return false
+	}
+
+	return r.version == nil || r.version.MatchString(version)
+}
+
+// denylist reads the package denylist from the build source. Each line is a
+// package name optionally followed by @version, blank lines and lines
+// starting with # are ignored.
+func (bb *Build) denylist(dir string) ([]denyRule, error) {
+	if bb.SbomDeny == "" {
+		return nil, nil
+	}
+
+	fd, err := os.Open(filepath.Join(dir, bb.SbomDeny))
+	if err != nil {
+		return nil, fmt.Errorf("open sbom denylist: %w", err)
+	}
+	defer fd.Close()
+
+	rules := []denyRule{}
+
+	scanner := bufio.NewScanner(fd)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "@", 2)
+
+		r := denyRule{name: globRegexp(parts[0])}
+
+		if len(parts) == 2 {
+			r.version = globRegexp(parts[1])
+		}
+
+		rules = append(rules, r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read sbom denylist: %w", err)
+	}
+
+	return rules, nil
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	q := regexp.QuoteMeta(pattern)
+	q = strings.ReplaceAll(q, `\*`, ".*")
+	q = strings.ReplaceAll(q, `\?`, ".")
+
+	return regexp.MustCompile("^" + q + "$")
+}
+
+// sbom generates the bill of materials for the image of a service from source,
+// any source syft can scan, and fails if the image has a denied package
+func (bb *Build) sbom(ctx context.Context, w io.Writer, service, source string, deny []denyRule) error {
+	fmt.Fprintf(w, "Generating: sbom for %s\n", service)
+
+	tmp, err := os.MkdirTemp("", "convox-sbom-")
+	if err != nil {
+		return fmt.Errorf("tempdir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	file := filepath.Join(tmp, "sbom.json")
+
+	if err := bb.run(ctx, w, "syft", "scan", source, "-q", "-o", fmt.Sprintf("%s=%s", sbomFormat, file)); err != nil {
+		return fmt.Errorf("generate sbom for %s: %w", service, err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read sbom for %s: %w", service, err)
+	}
+
+	var doc struct {
+		Components []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"components"`
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse sbom for %s: %w", service, err)
+	}
+
+	denied := []string{}
+
+	for _, c := range doc.Components {
+		for _, r := range deny {
+			if r.match(c.Name, c.Version) {
+				denied = append(denied, fmt.Sprintf("%s@%s", c.Name, c.Version))
+				break
+			}
+		}
+	}
+
+	if len(denied) > 0 {
+		sort.Strings(denied)
+		return fmt.Errorf("service %s: image has denied packages: %s", service, strings.Join(denied, ", "))
+	}
+
+	fmt.Fprintf(w, "Packages: %d\n", len(doc.Components))
+
+	bb.sboms.add(service, data)
+
+	return nil
+}
+
+// storeSboms stores the bill of materials of every service next to the build
+// logs and returns its location, or nil if the build generated none
+func (bb *Build) storeSboms() (*string, error) {
+	bb.sboms.lock.Lock()
+	defer bb.sboms.lock.Unlock()
+
+	if len(bb.sboms.docs) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(bb.sboms.docs)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sbom: %w", err)
+	}
+
+	obj, err := bb.Provider.ObjectStore(bb.App, fmt.Sprintf("build/%s/sbom", bb.Id), bytes.NewReader(data), structs.ObjectStoreOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("store sbom: %w", err)
+	}
+
+	return options.String(obj.Url), nil
+}
@@ -0,0 +1,180 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	osexec "os/exec"
+
+	"github.com/convox/exec"
+	"github.com/convox/rack/pkg/prefix"
+)
+
+// job is a single step of a build such as building, pulling or pushing one
+// image. A job starts once every job named in deps has finished.
+type job struct {
+	name string
+	deps []string
+	fn   func(ctx context.Context, w io.Writer) error
+}
+
+type jobResult struct {
+	name string
+	err  error
+}
+
+// schedule runs jobs as their dependencies finish, at most bb.Concurrency at a
+// time. Jobs that are ready together start in the order they were given, so a
+// concurrency of 1 runs them one after another exactly as listed. When jobs
+// can run side by side each line of output is prefixed with the job name to
+// keep it readable. The first failure cancels the jobs still running and skips
+// the rest.
+func (bb *Build) schedule(jobs []job) error {
+	concurrency := bb.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	names := map[string]bool{}
+
+	for _, j := range jobs {
+		if names[j.name] {
+			return fmt.Errorf("duplicate build job: %s", j.name)
+		}
+		names[j.name] = true
+	}
+
+	for _, j := range jobs {
+		for _, d := range j.deps {
+			if !names[d] {
+				return fmt.Errorf("build job %s depends on unknown job %s", j.name, d)
+			}
+		}
+	}
+
+	var pw *prefix.Writer
+
+	if concurrency > 1 {
+		prefixes := map[string]string{}
+		for name := range names {
+			prefixes[name] = ""
+		}
+		w := prefix.NewWriter(bb.writer, prefixes)
+		pw = &w
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := map[string]bool{}
+	started := map[string]bool{}
+	results := make(chan jobResult)
+	running := 0
+
+	var failure error
+
+	for len(done) < len(jobs) {
+		if failure == nil {
+			for _, j := range jobs {
+				if running >= concurrency {
+					break
+				}
+
+				if started[j.name] || !ready(j, done) {
+					continue
+				}
+
+				started[j.name] = true
+				running++
+
+				go func(j job) {
+					w, closer := jobWriter(bb.writer, pw, j.name)
+					err := j.fn(ctx, w)
+					closer()
+					results <- jobResult{name: j.name, err: err}
+				}(j)
+			}
+		}
+
+		if running == 0 {
+			if failure != nil {
+				return failure
+			}
+
+			return fmt.Errorf("build jobs have circular dependencies")
+		}
+
+		r := <-results
+		running--
+		done[r.name] = true
+
+		if r.err != nil && failure == nil {
+			failure = r.err
+			cancel()
+		}
+	}
+
+	return failure
+}
+
+func ready(j job, done map[string]bool) bool {
+	for _, d := range j.deps {
+		if !done[d] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// jobWriter returns the writer for a job's output and a function that flushes
+// it once the job is finished
+func jobWriter(w io.Writer, pw *prefix.Writer, name string) (io.Writer, func()) {
+	if pw == nil {
+		return w, func() {}
+	}
+
+	r, ww := io.Pipe()
+	flushed := make(chan struct{})
+
+	go func() {
+		pw.Write(name, r)
+		close(flushed)
+	}()
+
+	return ww, func() {
+		ww.Close()
+		<-flushed
+	}
+}
+
+// run streams the output of a command to w, killing the command if ctx is
+// canceled
+func (bb *Build) run(ctx context.Context, w io.Writer, command string, args ...string) error {
+	if _, ok := bb.Exec.(*exec.Exec); ok {
+		cmd := osexec.CommandContext(ctx, command, args...)
+		cmd.Stdout = w
+		cmd.Stderr = w
+		return cmd.Run()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return bb.Exec.Run(w, command, args...)
+}
+
+// output returns the combined output of a command, killing the command if ctx
+// is canceled
+func (bb *Build) output(ctx context.Context, command string, args ...string) ([]byte, error) {
+	if _, ok := bb.Exec.(*exec.Exec); ok {
+		return osexec.CommandContext(ctx, command, args...).CombinedOutput()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return bb.Exec.Execute(command, args...)
+}
@@ -0,0 +1,137 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/convox/rack/pkg/manifest"
+)
+
+// kanikoSecretsDir is where build secrets are mounted for Kaniko, the same
+// path BuildKit uses for RUN --mount=type=secret. It is left out of every
+// snapshot so the files never end up in a layer.
+const kanikoSecretsDir = "/run/secrets"
+
+const redacted = "[REDACTED]"
+
+type buildSecret struct {
+	Id    string
+	Value string
+}
+
+// secrets resolves the build secrets for a service from the app env and hides
+// their values in the build output
+func (bb *Build) secrets(b manifest.ServiceBuild, env map[string]string) ([]buildSecret, error) {
+	ss := []buildSecret{}
+
+	for _, s := range b.Secrets {
+		v, ok := env[s.Env]
+		if !ok {
+			return nil, fmt.Errorf("build secret %s: env var %s is not set", s.Id, s.Env)
+		}
+
+		bb.redactor.Add(v)
+
+		ss = append(ss, buildSecret{Id: s.Id, Value: v})
+	}
+
+	return ss, nil
+}
+
+// writeSecrets writes each secret to a file named for its id in dir
+func writeSecrets(dir string, secrets []buildSecret) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create secrets dir: %w", err)
+	}
+
+	for _, s := range secrets {
+		if err := os.WriteFile(filepath.Join(dir, s.Id), []byte(s.Value), 0600); err != nil {
+			return fmt.Errorf("write build secret %s: %w", s.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// redactor replaces secret values in the output written through it. Once a
+// value has been added output is held back until the end of each line so a
+// value split across writes is still caught.
+type redactor struct {
+	lock   sync.Mutex
+	line   bytes.Buffer
+	values []string
+	writer io.Writer
+}
+
+// Add hides value in any output written from now on
+func (r *redactor) Add(value string) {
+	if value == "" {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, v := range r.values {
+		if v == value {
+			return
+		}
+	}
+
+	r.values = append(r.values, value)
+
+	// replace longer values first so one containing another is fully hidden
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+}
+
+func (r *redactor) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.values) == 0 {
+		return r.writer.Write(p)
+	}
+
+	r.line.Write(p)
+
+	if i := bytes.LastIndexByte(r.line.Bytes(), '\n'); i >= 0 {
+		complete := string(r.line.Next(i + 1))
+
+		if _, err := io.WriteString(r.writer, r.redact(complete)); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush writes any partial line still held back
+func (r *redactor) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.line.Len() == 0 {
+		return nil
+	}
+
+	rest := r.line.String()
+	r.line.Reset()
+
+	_, err := io.WriteString(r.writer, r.redact(rest))
+
+	return err
+}
+
+func (r *redactor) redact(s string) string {
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+
+	return s
+}
@@ -0,0 +1,69 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/convox/rack/pkg/signing"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// sign signs the image pushed to tag with the rack signing key and pushes the
+// signature next to it, where the rack checks it before releasing the build
+func (bb *Build) sign(ctx context.Context, w io.Writer, tag string) error {
+	fmt.Fprintf(w, "Signing: %s\n", tag)
+
+	ref, err := name.ParseReference(tag)
+	if err != nil {
+		return fmt.Errorf("parse tag: %w", err)
+	}
+
+	auth, err := bb.registryAuth()
+	if err != nil {
+		return err
+	}
+
+	desc, err := remote.Head(ref, auth, remote.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", tag, err)
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("aws session: %w", err)
+	}
+
+	repo, digest := ref.Context().Name(), desc.Digest.String()
+
+	sig, err := signing.Sign(signing.NewKMSSigner(kms.New(sess), bb.SigningKey), repo, digest)
+	if err != nil {
+		return fmt.Errorf("sign %s: %w", tag, err)
+	}
+
+	if err := signing.Push(ctx, repo, digest, []signing.Signature{*sig}, auth); err != nil {
+		return fmt.Errorf("push signature: %w", err)
+	}
+
+	fmt.Fprintf(w, "Signed: %s@%s\n", repo, digest)
+	return nil
+}
+
+// registryAuth returns the credentials for the registry images are pushed to,
+// the docker runtime has already logged in to it
+func (bb *Build) registryAuth() (remote.Option, error) {
+	if bb.Runtime != RuntimeDaemonless {
+		return remote.WithAuthFromKeychain(authn.DefaultKeychain), nil
+	}
+
+	auth, err := ecrAuthenticator()
+	if err != nil {
+		return nil, err
+	}
+
+	return remote.WithAuth(auth), nil
+}
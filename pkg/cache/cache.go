@@ -1,111 +1,371 @@
 package cache
 
 import (
+	"container/list"
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type Cache map[string]map[string]*CacheItem
+const (
+	DefaultSize    = 5000
+	ExpiryInterval = time.Minute
+)
+
+var errLoadPanic = errors.New("cache load panicked")
+
+// Cache is a size bounded, least recently used cache of items grouped in
+// collections. Loads of the same missing key are de-duplicated.
+type Cache struct {
+	collections map[string]map[string]*list.Element
+	flights     map[string]*flight
+	lock        sync.Mutex
+	lru         *list.List
+	size        int
+	stats       stats
+	stop        chan struct{}
+}
 
 type CacheItem struct {
 	Item    interface{}
 	Expires time.Time
+
+	collection string
+	hash       string
+	str        *string
 }
 
-var (
-	cache = Cache{}
-	lock  = sync.Mutex{}
-)
+// Stats are counters since the cache was created
+type Stats struct {
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	Hits        uint64 `json:"hits"`
+	Items       int    `json:"items"`
+	Loads       uint64 `json:"loads"`
+	Misses      uint64 `json:"misses"`
+	Shared      uint64 `json:"shared"`
+}
+
+type stats struct {
+	evictions   uint64
+	expirations uint64
+	hits        uint64
+	loads       uint64
+	misses      uint64
+	shared      uint64
+}
+
+type flight struct {
+	done  chan struct{}
+	err   error
+	value interface{}
+}
+
+var cache = New(DefaultSize, ExpiryInterval)
+
+// New returns a cache holding at most size items, expired items are removed
+// every interval until Close is called
+func New(size int, interval time.Duration) *Cache {
+	c := &Cache{
+		collections: map[string]map[string]*list.Element{},
+		flights:     map[string]*flight{},
+		lru:         list.New(),
+		size:        size,
+		stop:        make(chan struct{}),
+	}
+
+	if interval > 0 {
+		go c.expireLoop(interval)
+	}
+
+	return c
+}
 
 func Get(collection string, key interface{}) interface{} {
-	lock.Lock()
-	defer lock.Unlock()
+	return cache.Get(collection, key)
+}
 
+func Set(collection string, key, value interface{}, ttl time.Duration) error {
+	return cache.Set(collection, key, value, ttl)
+}
+
+func Clear(collection string, key interface{}) error {
+	return cache.Clear(collection, key)
+}
+
+func ClearPrefix(collection string, prefix string) error {
+	return cache.ClearPrefix(collection, prefix)
+}
+
+func ClearFunc(collection string, fn func(key string) bool) error {
+	return cache.ClearFunc(collection, fn)
+}
+
+func Load(collection string, key interface{}, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	return cache.Load(collection, key, ttl, fn)
+}
+
+func CurrentStats() Stats {
+	return cache.Stats()
+}
+
+func (c *Cache) Get(collection string, key interface{}) interface{} {
 	if os.Getenv("PROVIDER") == "test" {
 		return nil
 	}
 
 	hash, err := hashKey(key)
-
 	if err != nil {
 		return nil
 	}
 
-	if cache[collection] == nil {
-		return nil
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.get(collection, hash)
+}
+
+func (c *Cache) Set(collection string, key, value interface{}, ttl time.Duration) error {
+	hash, err := hashKey(key)
+	if err != nil {
+		return err
 	}
 
-	item := cache[collection][hash]
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	if item == nil {
-		return nil
+	c.set(collection, hash, keyString(key), value, ttl)
+
+	return nil
+}
+
+func (c *Cache) Clear(collection string, key interface{}) error {
+	hash, err := hashKey(key)
+	if err != nil {
+		return err
 	}
 
-	if item.Expires.Before(time.Now()) {
-		return nil
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.collections[collection][hash]; ok {
+		c.remove(e)
 	}
 
-	return item.Item
+	return nil
 }
 
-func Set(collection string, key, value interface{}, ttl time.Duration) error {
-	lock.Lock()
-	defer lock.Unlock()
+// ClearPrefix removes the items of a collection with a string key starting with prefix
+func (c *Cache) ClearPrefix(collection string, prefix string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	if cache[collection] == nil {
-		cache[collection] = map[string]*CacheItem{}
+	for _, e := range c.collections[collection] {
+		if item := e.Value.(*CacheItem); item.str != nil && strings.HasPrefix(*item.str, prefix) {
+			c.remove(e)
+		}
 	}
 
-	hash, err := hashKey(key)
+	return nil
+}
 
-	if err != nil {
-		return err
-	}
+// ClearFunc removes the items of a collection for which fn returns true, fn
+// receives the json encoded key
+func (c *Cache) ClearFunc(collection string, fn func(key string) bool) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	cache[collection][hash] = &CacheItem{
-		Item:    value,
-		Expires: time.Now().Add(ttl),
+	for hash, e := range c.collections[collection] {
+		if fn(hash) {
+			c.remove(e)
+		}
 	}
 
 	return nil
 }
 
-func Clear(collection string, key interface{}) error {
-	lock.Lock()
-	defer lock.Unlock()
+// Load returns a cached item or calls fn to load it, concurrent loads of the
+// same key wait for the first one and share its result
+func (c *Cache) Load(collection string, key interface{}, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	if os.Getenv("PROVIDER") == "test" {
+		return fn()
+	}
 
 	hash, err := hashKey(key)
-
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if cache[collection] != nil && cache[collection][hash] != nil {
-		delete(cache[collection], hash)
+	c.lock.Lock()
+
+	if v := c.get(collection, hash); v != nil {
+		c.lock.Unlock()
+		return v, nil
 	}
 
-	return nil
+	fk := collection + "\x00" + hash
+
+	if f, ok := c.flights[fk]; ok {
+		c.lock.Unlock()
+		atomic.AddUint64(&c.stats.shared, 1)
+		<-f.done
+		return f.value, f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	c.flights[fk] = f
+
+	c.lock.Unlock()
+
+	atomic.AddUint64(&c.stats.loads, 1)
+
+	// the flight ends even when fn panics so that later loads of the key do
+	// not wait on it forever, the loads waiting on it get errLoadPanic
+	f.err = errLoadPanic
+
+	defer func() {
+		c.lock.Lock()
+
+		if f.err == nil {
+			c.set(collection, hash, keyString(key), f.value, ttl)
+		}
+
+		delete(c.flights, fk)
+
+		c.lock.Unlock()
+
+		close(f.done)
+	}()
+
+	f.value, f.err = fn()
+
+	return f.value, f.err
 }
 
-func ClearPrefix(collection string, prefix string) error {
-	lock.Lock()
-	defer lock.Unlock()
+// Expire removes expired items and returns how many were removed
+func (c *Cache) Expire() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	removed := 0
+
+	for e := c.lru.Back(); e != nil; {
+		prev := e.Prev()
 
-	for k := range cache[collection] {
-		match, err := hashPrefix(k, prefix)
-		if err != nil {
-			return err
+		if e.Value.(*CacheItem).Expires.Before(now) {
+			c.remove(e)
+			removed++
 		}
 
-		if match {
-			delete(cache[collection], k)
+		e = prev
+	}
+
+	atomic.AddUint64(&c.stats.expirations, uint64(removed))
+
+	return removed
+}
+
+func (c *Cache) Stats() Stats {
+	c.lock.Lock()
+	items := c.lru.Len()
+	c.lock.Unlock()
+
+	return Stats{
+		Evictions:   atomic.LoadUint64(&c.stats.evictions),
+		Expirations: atomic.LoadUint64(&c.stats.expirations),
+		Hits:        atomic.LoadUint64(&c.stats.hits),
+		Items:       items,
+		Loads:       atomic.LoadUint64(&c.stats.loads),
+		Misses:      atomic.LoadUint64(&c.stats.misses),
+		Shared:      atomic.LoadUint64(&c.stats.shared),
+	}
+}
+
+// Close stops background expiry
+func (c *Cache) Close() {
+	close(c.stop)
+}
+
+func (c *Cache) expireLoop(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-t.C:
+			c.Expire()
 		}
 	}
+}
 
-	return nil
+// get must be called with the lock held
+func (c *Cache) get(collection, hash string) interface{} {
+	e, ok := c.collections[collection][hash]
+	if !ok {
+		atomic.AddUint64(&c.stats.misses, 1)
+		return nil
+	}
+
+	item := e.Value.(*CacheItem)
+
+	if item.Expires.Before(time.Now()) {
+		c.remove(e)
+		atomic.AddUint64(&c.stats.expirations, 1)
+		atomic.AddUint64(&c.stats.misses, 1)
+		return nil
+	}
+
+	c.lru.MoveToFront(e)
+
+	atomic.AddUint64(&c.stats.hits, 1)
+
+	return item.Item
+}
+
+// set must be called with the lock held
+func (c *Cache) set(collection, hash string, str *string, value interface{}, ttl time.Duration) {
+	if e, ok := c.collections[collection][hash]; ok {
+		c.remove(e)
+	}
+
+	if c.collections[collection] == nil {
+		c.collections[collection] = map[string]*list.Element{}
+	}
+
+	item := &CacheItem{
+		Item:       value,
+		Expires:    time.Now().Add(ttl),
+		collection: collection,
+		hash:       hash,
+		str:        str,
+	}
+
+	c.collections[collection][hash] = c.lru.PushFront(item)
+
+	for c.size > 0 && c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+		atomic.AddUint64(&c.stats.evictions, 1)
+	}
+}
+
+// remove must be called with the lock held
+func (c *Cache) remove(e *list.Element) {
+	item := e.Value.(*CacheItem)
+
+	c.lru.Remove(e)
+
+	delete(c.collections[item.collection], item.hash)
+
+	if len(c.collections[item.collection]) == 0 {
+		delete(c.collections, item.collection)
+	}
 }
 
 func hashKey(key interface{}) (string, error) {
@@ -117,18 +377,17 @@ func hashKey(key interface{}) (string, error) {
 	return string(data), nil
 }
 
-func hashPrefix(hash string, prefix string) (bool, error) {
-	var w interface{}
-
-	if err := json.Unmarshal([]byte(hash), &w); err != nil {
-		return false, err
-	}
-
-	if s, ok := w.(string); ok {
-		if strings.HasPrefix(s, prefix) {
-			return true, nil
+// keyString returns string keys for ClearPrefix so they are not decoded again
+func keyString(key interface{}) *string {
+	switch t := key.(type) {
+	case string:
+		return &t
+	case *string:
+		if t != nil {
+			s := *t
+			return &s
 		}
 	}
 
-	return false, nil
+	return nil
 }
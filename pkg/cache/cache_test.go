@@ -1,8 +1,11 @@
 package cache
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -121,3 +124,194 @@ func TestClearPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestSetOverwrite(t *testing.T) {
+	c := New(10, 0)
+	defer c.Close()
+
+	require.NoError(t, c.Set("TestSetOverwrite", "test1", "one", time.Minute))
+	require.NoError(t, c.Set("TestSetOverwrite", "test1", "two", time.Minute))
+
+	require.Equal(t, "two", c.Get("TestSetOverwrite", "test1"))
+	require.Equal(t, 1, c.Stats().Items)
+}
+
+func TestSizeBound(t *testing.T) {
+	c := New(3, 0)
+	defer c.Close()
+
+	for _, k := range []string{"test1", "test2", "test3"} {
+		require.NoError(t, c.Set("TestSizeBound", k, k, time.Minute))
+	}
+
+	// test1 becomes the most recently used
+	require.Equal(t, "test1", c.Get("TestSizeBound", "test1"))
+
+	require.NoError(t, c.Set("TestSizeBound", "test4", "test4", time.Minute))
+
+	require.Equal(t, "test1", c.Get("TestSizeBound", "test1"))
+	require.Nil(t, c.Get("TestSizeBound", "test2"))
+	require.Equal(t, "test3", c.Get("TestSizeBound", "test3"))
+	require.Equal(t, "test4", c.Get("TestSizeBound", "test4"))
+
+	s := c.Stats()
+	require.Equal(t, uint64(1), s.Evictions)
+	require.Equal(t, 3, s.Items)
+}
+
+func TestExpire(t *testing.T) {
+	c := New(10, 0)
+	defer c.Close()
+
+	require.NoError(t, c.Set("TestExpire", "test1", "one", -time.Second))
+	require.NoError(t, c.Set("TestExpire", "test2", "two", time.Minute))
+
+	require.Equal(t, 1, c.Expire())
+
+	s := c.Stats()
+	require.Equal(t, uint64(1), s.Expirations)
+	require.Equal(t, 1, s.Items)
+}
+
+func TestExpireBackground(t *testing.T) {
+	c := New(10, 10*time.Millisecond)
+	defer c.Close()
+
+	require.NoError(t, c.Set("TestExpireBackground", "test1", "one", time.Millisecond))
+
+	require.Eventually(t, func() bool { return c.Stats().Items == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestClearPrefixPointerKeys(t *testing.T) {
+	c := New(10, 0)
+	defer c.Close()
+
+	name := "app1-stack"
+
+	require.NoError(t, c.Set("TestClearPrefixPointerKeys", &name, "stack", time.Minute))
+	require.NoError(t, c.Set("TestClearPrefixPointerKeys", map[string]string{"app1": "x"}, "other", time.Minute))
+
+	require.NoError(t, c.ClearPrefix("TestClearPrefixPointerKeys", "app1"))
+
+	require.Equal(t, 1, c.Stats().Items)
+}
+
+func TestLoad(t *testing.T) {
+	if os.Getenv("PROVIDER") == "test" {
+		os.Setenv("PROVIDER", "")
+		defer os.Setenv("PROVIDER", "test")
+	}
+
+	c := New(10, 0)
+	defer c.Close()
+
+	var calls int32
+
+	release := make(chan struct{})
+
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v, err := c.Load("TestLoad", "test1", time.Minute, load)
+			require.NoError(t, err)
+			require.Equal(t, "value", v)
+		}()
+	}
+
+	require.Eventually(t, func() bool { return c.Stats().Shared == 4 }, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	v, err := c.Load("TestLoad", "test1", time.Minute, load)
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	s := c.Stats()
+	require.Equal(t, uint64(1), s.Loads)
+	require.Equal(t, uint64(1), s.Hits)
+}
+
+func TestLoadError(t *testing.T) {
+	if os.Getenv("PROVIDER") == "test" {
+		os.Setenv("PROVIDER", "")
+		defer os.Setenv("PROVIDER", "test")
+	}
+
+	c := New(10, 0)
+	defer c.Close()
+
+	_, err := c.Load("TestLoadError", "test1", time.Minute, func() (interface{}, error) {
+		return nil, fmt.Errorf("err1")
+	})
+	require.EqualError(t, err, "err1")
+
+	require.Nil(t, c.Get("TestLoadError", "test1"))
+}
+
+func TestLoadPanic(t *testing.T) {
+	if os.Getenv("PROVIDER") == "test" {
+		os.Setenv("PROVIDER", "")
+		defer os.Setenv("PROVIDER", "test")
+	}
+
+	c := New(10, 0)
+	defer c.Close()
+
+	func() {
+		defer func() {
+			require.Equal(t, "panic1", recover())
+		}()
+
+		c.Load("TestLoadPanic", "test1", time.Minute, func() (interface{}, error) {
+			panic("panic1")
+		})
+	}()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		v, err := c.Load("TestLoadPanic", "test1", time.Minute, func() (interface{}, error) {
+			return "value", nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "value", v)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("load after a panicking load did not return")
+	}
+}
+
+func TestClearFunc(t *testing.T) {
+	c := New(10, 0)
+	defer c.Close()
+
+	require.NoError(t, c.Set("TestClearFunc", []string{"task1", "task2"}, "one", time.Minute))
+	require.NoError(t, c.Set("TestClearFunc", []string{"task3"}, "two", time.Minute))
+
+	require.NoError(t, c.ClearFunc("TestClearFunc", func(key string) bool {
+		return strings.Contains(key, `"task2"`)
+	}))
+
+	require.Nil(t, c.Get("TestClearFunc", []string{"task1", "task2"}))
+	require.Equal(t, "two", c.Get("TestClearFunc", []string{"task3"}))
+}
@@ -0,0 +1,154 @@
+package capacity
+
+import (
+	"sort"
+)
+
+// Node is an instance and the resources still free on it
+type Node struct {
+	Id     string
+	Cpu    int64
+	Memory int64
+	Ports  map[int64]bool // host ports already reserved
+}
+
+// Task is a task waiting to be placed, Group identifies the service it
+// belongs to
+type Task struct {
+	Group  string
+	Cpu    int64
+	Memory int64
+	Ports  []int64 // host ports the task reserves
+}
+
+// Result is the outcome of placing tasks
+type Result struct {
+	// Added is the number of empty nodes needed on top of the existing ones
+	// to place every task that fits on a node at all
+	Added int
+
+	// Oversized counts tasks by group that do not fit on an empty node
+	Oversized map[string]int
+
+	// Pending counts tasks by group that could not be placed on the existing
+	// nodes, including oversized tasks
+	Pending map[string]int
+}
+
+// Fits returns true if every task was placed on the existing nodes
+func (r Result) Fits() bool {
+	return len(r.Pending) == 0
+}
+
+// Plan places tasks onto nodes the way the ECS binpack strategy does: largest
+// tasks first, each onto the node with the least memory left that can hold
+// it. Tasks left over are then placed onto copies of empty to find how many
+// more nodes would be needed. The nodes passed in are not modified.
+func Plan(nodes []Node, empty Node, tasks []Task) Result {
+	r := Result{
+		Oversized: map[string]int{},
+		Pending:   map[string]int{},
+	}
+
+	ts := make([]Task, len(tasks))
+	copy(ts, tasks)
+
+	sort.SliceStable(ts, func(i, j int) bool {
+		if ts[i].Memory != ts[j].Memory {
+			return ts[i].Memory > ts[j].Memory
+		}
+		return ts[i].Cpu > ts[j].Cpu
+	})
+
+	ns := make([]*Node, len(nodes))
+
+	for i := range nodes {
+		ns[i] = copyNode(nodes[i])
+	}
+
+	leftover := []Task{}
+
+	for _, t := range ts {
+		if !place(ns, t) {
+			r.Pending[t.Group]++
+			leftover = append(leftover, t)
+		}
+	}
+
+	added := []*Node{}
+
+	for _, t := range leftover {
+		if !fits(&empty, t) {
+			r.Oversized[t.Group]++
+			continue
+		}
+
+		if place(added, t) {
+			continue
+		}
+
+		n := copyNode(empty)
+		reserve(n, t)
+		added = append(added, n)
+	}
+
+	r.Added = len(added)
+
+	return r
+}
+
+func copyNode(n Node) *Node {
+	c := n
+	c.Ports = map[int64]bool{}
+
+	for p, ok := range n.Ports {
+		c.Ports[p] = ok
+	}
+
+	return &c
+}
+
+func fits(n *Node, t Task) bool {
+	if n.Cpu < t.Cpu || n.Memory < t.Memory {
+		return false
+	}
+
+	for _, p := range t.Ports {
+		if n.Ports[p] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func place(ns []*Node, t Task) bool {
+	var best *Node
+
+	for _, n := range ns {
+		if !fits(n, t) {
+			continue
+		}
+
+		if best == nil || n.Memory < best.Memory {
+			best = n
+		}
+	}
+
+	if best == nil {
+		return false
+	}
+
+	reserve(best, t)
+
+	return true
+}
+
+func reserve(n *Node, t Task) {
+	n.Cpu -= t.Cpu
+	n.Memory -= t.Memory
+
+	for _, p := range t.Ports {
+		n.Ports[p] = true
+	}
+}
@@ -0,0 +1,86 @@
+package capacity_test
+
+import (
+	"testing"
+
+	"github.com/convox/rack/pkg/capacity"
+	"github.com/stretchr/testify/require"
+)
+
+func tasks(group string, n int, cpu, memory int64, ports ...int64) []capacity.Task {
+	ts := []capacity.Task{}
+
+	for i := 0; i < n; i++ {
+		ts = append(ts, capacity.Task{Group: group, Cpu: cpu, Memory: memory, Ports: ports})
+	}
+
+	return ts
+}
+
+func TestPlanFits(t *testing.T) {
+	nodes := []capacity.Node{
+		{Id: "i-1", Cpu: 1024, Memory: 2048},
+		{Id: "i-2", Cpu: 1024, Memory: 1024},
+	}
+
+	r := capacity.Plan(nodes, capacity.Node{Cpu: 2048, Memory: 4096}, tasks("app/web", 3, 256, 1024))
+
+	require.True(t, r.Fits())
+	require.Equal(t, 0, r.Added)
+	require.Empty(t, r.Pending)
+	require.Empty(t, r.Oversized)
+
+	// nodes passed in are left alone
+	require.Equal(t, int64(2048), nodes[0].Memory)
+}
+
+func TestPlanAddsNodes(t *testing.T) {
+	nodes := []capacity.Node{
+		{Id: "i-1", Cpu: 2048, Memory: 1024},
+	}
+
+	ts := append(tasks("app/web", 5, 256, 1024), tasks("app/worker", 2, 512, 512)...)
+
+	r := capacity.Plan(nodes, capacity.Node{Cpu: 2048, Memory: 2048}, ts)
+
+	require.False(t, r.Fits())
+	require.Equal(t, 3, r.Added)
+	require.Equal(t, map[string]int{"app/web": 4, "app/worker": 2}, r.Pending)
+	require.Empty(t, r.Oversized)
+}
+
+func TestPlanBinpack(t *testing.T) {
+	nodes := []capacity.Node{
+		{Id: "i-1", Cpu: 4096, Memory: 4096},
+		{Id: "i-2", Cpu: 4096, Memory: 1536},
+	}
+
+	// the large task is placed first so the small one does not take its room
+	ts := append(tasks("app/small", 1, 0, 1024), tasks("app/large", 1, 0, 4096)...)
+
+	r := capacity.Plan(nodes, capacity.Node{Cpu: 4096, Memory: 4096}, ts)
+
+	require.True(t, r.Fits())
+}
+
+func TestPlanPorts(t *testing.T) {
+	nodes := []capacity.Node{
+		{Id: "i-1", Cpu: 4096, Memory: 8192},
+		{Id: "i-2", Cpu: 4096, Memory: 8192, Ports: map[int64]bool{80: true}},
+	}
+
+	r := capacity.Plan(nodes, capacity.Node{Cpu: 4096, Memory: 8192, Ports: map[int64]bool{22: true}}, tasks("app/web", 3, 256, 512, 80))
+
+	require.False(t, r.Fits())
+	require.Equal(t, 2, r.Added)
+	require.Equal(t, map[string]int{"app/web": 2}, r.Pending)
+}
+
+func TestPlanOversized(t *testing.T) {
+	r := capacity.Plan(nil, capacity.Node{Cpu: 1024, Memory: 2048}, tasks("app/big", 2, 256, 4096))
+
+	require.False(t, r.Fits())
+	require.Equal(t, 0, r.Added)
+	require.Equal(t, map[string]int{"app/big": 2}, r.Pending)
+	require.Equal(t, map[string]int{"app/big": 2}, r.Oversized)
+}
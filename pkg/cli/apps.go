@@ -452,7 +452,7 @@ func appExport(rack sdk.Interface, c *stdcli.Context, app string, w io.Writer) e
 			}
 			defer fd.Close()
 
-			if err := rack.BuildExport(app, r.Build, fd); err != nil {
+			if err := rack.BuildExport(app, r.Build, fd, structs.BuildExportOptions{}); err != nil {
 				return err
 			}
 
@@ -527,7 +527,7 @@ func appImport(rack sdk.Interface, c *stdcli.Context, app string, r io.Reader) e
 
 		c.Startf("Importing build")
 
-		b, err := rack.BuildImport(app, fd)
+		b, err := rack.BuildImport(app, fd, structs.BuildImportOptions{})
 		if err != nil {
 			return err
 		}
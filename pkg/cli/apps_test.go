@@ -234,7 +234,7 @@ func TestAppsExport(t *testing.T) {
 		i.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
 		bdata, err := os.ReadFile("testdata/build.tgz")
 		require.NoError(t, err)
-		i.On("BuildExport", "app1", "build1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		i.On("BuildExport", "app1", "build1", mock.Anything, structs.BuildExportOptions{}).Return(nil).Run(func(args mock.Arguments) {
 			args.Get(2).(io.Writer).Write(bdata)
 		})
 
@@ -284,7 +284,7 @@ func TestAppsImport(t *testing.T) {
 		i.On("AppGet", "app1").Return(fxApp(), nil).Twice()
 		bdata, err := os.ReadFile("testdata/build.tgz")
 		require.NoError(t, err)
-		i.On("BuildImport", "app1", mock.Anything).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
+		i.On("BuildImport", "app1", mock.Anything, structs.BuildImportOptions{}).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
 			rdata, err := io.ReadAll(args.Get(1).(io.Reader))
 			require.NoError(t, err)
 			require.Equal(t, bdata, rdata)
@@ -338,7 +338,7 @@ func TestAppsImportNoParams(t *testing.T) {
 		i.On("AppGet", "app1").Return(fxApp(), nil).Twice()
 		bdata, err := os.ReadFile("testdata/build.tgz")
 		require.NoError(t, err)
-		i.On("BuildImport", "app1", mock.Anything).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
+		i.On("BuildImport", "app1", mock.Anything, structs.BuildImportOptions{}).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
 			rdata, err := io.ReadAll(args.Get(1).(io.Reader))
 			require.NoError(t, err)
 			require.Equal(t, bdata, rdata)
@@ -367,7 +367,7 @@ func TestAppsImportSameParams(t *testing.T) {
 		i.On("AppGet", "app1").Return(fxApp(), nil).Twice()
 		bdata, err := os.ReadFile("testdata/build.tgz")
 		require.NoError(t, err)
-		i.On("BuildImport", "app1", mock.Anything).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
+		i.On("BuildImport", "app1", mock.Anything, structs.BuildImportOptions{}).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
 			rdata, err := io.ReadAll(args.Get(1).(io.Reader))
 			require.NoError(t, err)
 			require.Equal(t, bdata, rdata)
@@ -28,23 +28,30 @@ func init() {
 		Validate: stdcli.Args(0),
 	})
 
+	register("builds cancel", "cancel a build", BuildsCancel, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack, flagApp},
+		Usage:    "<build>",
+		Validate: stdcli.Args(1),
+	})
+
 	register("builds export", "export a build", BuildsExport, stdcli.CommandOptions{
-		Flags: []stdcli.Flag{
+		Flags: append(stdcli.OptionFlags(structs.BuildExportOptions{}),
 			flagRack,
 			flagApp,
 			stdcli.StringFlag("file", "f", "import from file"),
-		},
+		),
 		Usage:    "<build>",
 		Validate: stdcli.Args(1),
 	})
 
 	register("builds import", "import a build", BuildsImport, stdcli.CommandOptions{
-		Flags: []stdcli.Flag{
+		Flags: append(stdcli.OptionFlags(structs.BuildImportOptions{}),
 			flagRack,
 			flagApp,
 			flagId,
 			stdcli.StringFlag("file", "f", "export to file"),
-		},
+			stdcli.BoolFlag("resumable", "", "upload in chunks that running the import again resumes"),
+		),
 		Validate: stdcli.Args(0),
 	})
 
@@ -59,6 +66,12 @@ func init() {
 		Usage:    "<build>",
 		Validate: stdcli.Args(1),
 	})
+
+	register("builds sbom", "get the software bill of materials for a build", BuildsSbom, stdcli.CommandOptions{
+		Flags:    append(stdcli.OptionFlags(structs.BuildSbomOptions{}), flagRack, flagApp),
+		Usage:    "<build>",
+		Validate: stdcli.Args(1),
+	})
 }
 
 func Build(rack sdk.Interface, c *stdcli.Context) error {
@@ -233,6 +246,16 @@ func Builds(rack sdk.Interface, c *stdcli.Context) error {
 	return t.Print()
 }
 
+func BuildsCancel(rack sdk.Interface, c *stdcli.Context) error {
+	c.Startf("Cancelling build <build>%s</build>", c.Arg(0))
+
+	if err := rack.BuildCancel(app(c), c.Arg(0)); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
 func BuildsExport(rack sdk.Interface, c *stdcli.Context) error {
 	var w io.Writer
 
@@ -251,9 +274,15 @@ func BuildsExport(rack sdk.Interface, c *stdcli.Context) error {
 		c.Writer().Stdout = c.Writer().Stderr
 	}
 
+	var opts structs.BuildExportOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
 	c.Startf("Exporting build")
 
-	if err := rack.BuildExport(app(c), c.Arg(0), w); err != nil {
+	if err := rack.BuildExport(app(c), c.Arg(0), w, opts); err != nil {
 		return err
 	}
 
@@ -285,6 +314,12 @@ func BuildsImport(rack sdk.Interface, c *stdcli.Context) error {
 
 	defer r.Close()
 
+	var opts structs.BuildImportOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
 	s, err := rack.SystemGet()
 	if err != nil {
 		return err
@@ -298,8 +333,10 @@ func BuildsImport(rack sdk.Interface, c *stdcli.Context) error {
 		b, err = rack.BuildImportMultipart(app(c), r)
 	} else if s.Version <= "20180708231844" {
 		b, err = rack.BuildImportUrl(app(c), r)
+	} else if c.Bool("resumable") {
+		b, err = rack.BuildImportChunked(app(c), r, opts)
 	} else {
-		b, err = rack.BuildImport(app(c), r)
+		b, err = rack.BuildImport(app(c), r, opts)
 	}
 	if err != nil {
 		return err
@@ -329,9 +366,29 @@ func BuildsInfo(rack sdk.Interface, c *stdcli.Context) error {
 	i.Add("Started", helpers.Ago(b.Started))
 	i.Add("Elapsed", helpers.Duration(b.Started, b.Ended))
 
+	if len(b.Transitions) > 0 {
+		i.Add("History", buildHistory(b.Transitions))
+	}
+
 	return i.Print()
 }
 
+// buildHistory describes each status a build went through with the time it
+// spent there
+func buildHistory(ts []structs.BuildTransition) string {
+	steps := []string{}
+
+	for i, t := range ts {
+		if i+1 < len(ts) {
+			steps = append(steps, fmt.Sprintf("%s %s", t.Status, helpers.Duration(t.Time, ts[i+1].Time)))
+		} else {
+			steps = append(steps, t.Status)
+		}
+	}
+
+	return strings.Join(steps, ", ")
+}
+
 func BuildsLogs(rack sdk.Interface, c *stdcli.Context) error {
 	var opts structs.LogsOptions
 
@@ -348,3 +405,23 @@ func BuildsLogs(rack sdk.Interface, c *stdcli.Context) error {
 
 	return nil
 }
+
+func BuildsSbom(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.BuildSbomOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	r, err := rack.BuildSbom(app(c), c.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if _, err := io.Copy(c, r); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -5,10 +5,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/convox/rack/pkg/cli"
 	mocksdk "github.com/convox/rack/pkg/mock/sdk"
+	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -152,7 +155,7 @@ func TestBuildsExport(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		data, err := os.ReadFile("testdata/build.tgz")
 		require.NoError(t, err)
-		i.On("BuildExport", "app1", "build1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		i.On("BuildExport", "app1", "build1", mock.Anything, structs.BuildExportOptions{}).Return(nil).Run(func(args mock.Arguments) {
 			args.Get(2).(io.Writer).Write(data)
 		})
 		tmpd, err := os.MkdirTemp("", "")
@@ -174,7 +177,7 @@ func TestBuildsExportStdout(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		data, err := os.ReadFile("testdata/build.tgz")
 		require.NoError(t, err)
-		i.On("BuildExport", "app1", "build1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		i.On("BuildExport", "app1", "build1", mock.Anything, structs.BuildExportOptions{}).Return(nil).Run(func(args mock.Arguments) {
 			args.Get(2).(io.Writer).Write(data)
 		})
 
@@ -186,9 +189,22 @@ func TestBuildsExportStdout(t *testing.T) {
 	})
 }
 
+func TestBuildsExportCompression(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.BuildExportOptions{Compression: options.String("zstd")}
+		i.On("BuildExport", "app1", "build1", mock.Anything, opts).Return(nil)
+
+		res, err := testExecute(e, "builds export build1 -a app1 -f /dev/null --compression zstd", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Exporting build... OK"})
+	})
+}
+
 func TestBuildsExportError(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
-		i.On("BuildExport", "app1", "build1", mock.Anything).Return(fmt.Errorf("err1"))
+		i.On("BuildExport", "app1", "build1", mock.Anything, structs.BuildExportOptions{}).Return(fmt.Errorf("err1"))
 
 		res, err := testExecute(e, "builds export build1 -a app1 -f /dev/null", nil)
 		require.NoError(t, err)
@@ -203,7 +219,7 @@ func TestBuildsImport(t *testing.T) {
 		data, err := os.ReadFile("testdata/build.tgz")
 		require.NoError(t, err)
 		i.On("SystemGet").Return(fxSystem(), nil)
-		i.On("BuildImport", "app1", mock.Anything).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
+		i.On("BuildImport", "app1", mock.Anything, structs.BuildImportOptions{}).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
 			rdata, err := io.ReadAll(args.Get(1).(io.Reader))
 			require.NoError(t, err)
 			require.Equal(t, data, rdata)
@@ -217,10 +233,30 @@ func TestBuildsImport(t *testing.T) {
 	})
 }
 
+func TestBuildsImportResumable(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		data, err := os.ReadFile("testdata/build.tgz")
+		require.NoError(t, err)
+		opts := structs.BuildImportOptions{Verify: options.Bool(true)}
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("BuildImportChunked", "app1", mock.Anything, opts).Return(fxBuild(), nil).Run(func(args mock.Arguments) {
+			rdata, err := io.ReadAll(args.Get(1).(io.Reader))
+			require.NoError(t, err)
+			require.Equal(t, data, rdata)
+		})
+
+		res, err := testExecute(e, "builds import -a app1 -f testdata/build.tgz --resumable --verify", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Importing build... OK, release1"})
+	})
+}
+
 func TestBuildsImportError(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("SystemGet").Return(fxSystem(), nil)
-		i.On("BuildImport", "app1", mock.Anything).Return(nil, fmt.Errorf("err1"))
+		i.On("BuildImport", "app1", mock.Anything, structs.BuildImportOptions{}).Return(nil, fmt.Errorf("err1"))
 
 		res, err := testExecute(e, "builds import -a app1 -f testdata/build.tgz", nil)
 		require.NoError(t, err)
@@ -249,6 +285,30 @@ func TestBuildsImportClassic(t *testing.T) {
 	})
 }
 
+func TestBuildsCancel(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("BuildCancel", "app1", "build1").Return(nil)
+
+		res, err := testExecute(e, "builds cancel build1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Cancelling build build1... OK"})
+	})
+}
+
+func TestBuildsCancelError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("BuildCancel", "app1", "build1").Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "builds cancel build1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Cancelling build build1... "})
+	})
+}
+
 func TestBuildsInfo(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("BuildGet", "app1", "build1").Return(fxBuild(), nil)
@@ -268,6 +328,33 @@ func TestBuildsInfo(t *testing.T) {
 	})
 }
 
+func TestBuildsInfoHistory(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		b := fxBuild()
+		b.Transitions = []structs.BuildTransition{
+			{Status: "created", Time: fxStarted},
+			{Status: "queued", Time: fxStarted},
+			{Status: "running", Time: fxStarted.Add(15 * time.Second)},
+			{Status: "complete", Time: fxStarted.Add(2 * time.Minute)},
+		}
+		i.On("BuildGet", "app1", "build1").Return(b, nil)
+
+		res, err := testExecute(e, "builds info build1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Id           build1",
+			"Status       complete",
+			"Release      release1",
+			"Description  desc",
+			"Started      2 days ago",
+			"Elapsed      2m0s",
+			"History      created 0s, queued 15s, running 1m45s, complete",
+		})
+	})
+}
+
 func TestBuildsInfoError(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("BuildGet", "app1", "build1").Return(nil, fmt.Errorf("err1"))
@@ -308,3 +395,39 @@ func TestBuildsLogsError(t *testing.T) {
 		res.RequireStdout(t, []string{""})
 	})
 }
+
+func TestBuildsSbom(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("BuildSbom", "app1", "build1", structs.BuildSbomOptions{}).Return(io.NopCloser(strings.NewReader(`{"web":{"bomFormat":"CycloneDX"}}`)), nil)
+
+		res, err := testExecute(e, "builds sbom build1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{`{"web":{"bomFormat":"CycloneDX"}}`})
+	})
+}
+
+func TestBuildsSbomService(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("BuildSbom", "app1", "build1", structs.BuildSbomOptions{Service: options.String("web")}).Return(io.NopCloser(strings.NewReader(`{"bomFormat":"CycloneDX"}`)), nil)
+
+		res, err := testExecute(e, "builds sbom build1 -a app1 --service web", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{`{"bomFormat":"CycloneDX"}`})
+	})
+}
+
+func TestBuildsSbomError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("BuildSbom", "app1", "build1", structs.BuildSbomOptions{}).Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "builds sbom build1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	register("drains", "list log drains for an app", Drains, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack},
+		Validate: stdcli.Args(0),
+	})
+
+	register("drains add", "add a log drain", DrainsAdd, stdcli.CommandOptions{
+		Flags:    append(stdcli.OptionFlags(structs.DrainCreateOptions{}), flagApp, flagRack),
+		Usage:    "<name> <url>",
+		Validate: stdcli.Args(2),
+	})
+
+	register("drains remove", "remove a log drain", DrainsRemove, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack},
+		Usage:    "<name>",
+		Validate: stdcli.Args(1),
+	})
+
+	register("drains test", "send a test log to a drain", DrainsTest, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack},
+		Usage:    "<name>",
+		Validate: stdcli.Args(1),
+	})
+}
+
+func Drains(rack sdk.Interface, c *stdcli.Context) error {
+	ds, err := rack.DrainList(app(c))
+	if err != nil {
+		return err
+	}
+
+	t := c.Table("NAME", "TYPE", "URL", "SERVICES", "PROCESSES", "REGEX")
+
+	for _, d := range ds {
+		t.AddRow(d.Name, d.Type, d.Url, strings.Join(d.Services, ","), strings.Join(d.Processes, ","), d.Regex)
+	}
+
+	return t.Print()
+}
+
+func DrainsAdd(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.DrainCreateOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	c.Startf("Adding drain <id>%s</id>", c.Arg(0))
+
+	if _, err := rack.DrainCreate(app(c), c.Arg(0), c.Arg(1), opts); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
+func DrainsRemove(rack sdk.Interface, c *stdcli.Context) error {
+	c.Startf("Removing drain <id>%s</id>", c.Arg(0))
+
+	if err := rack.DrainDelete(app(c), c.Arg(0)); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
+func DrainsTest(rack sdk.Interface, c *stdcli.Context) error {
+	c.Startf("Sending test log to <id>%s</id>", c.Arg(0))
+
+	if err := rack.DrainTest(app(c), c.Arg(0)); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
@@ -0,0 +1,117 @@
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/convox/rack/pkg/cli"
+	mocksdk "github.com/convox/rack/pkg/mock/sdk"
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrains(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		d2 := structs.Drain{App: "app1", Name: "drain2", Regex: "error", Type: "syslog", Url: "tcp+tls://logs.example.org:1234"}
+		i.On("DrainList", "app1").Return(structs.Drains{*fxDrain(), d2}, nil)
+
+		res, err := testExecute(e, "drains -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"NAME    TYPE    URL                              SERVICES    PROCESSES  REGEX",
+			"drain1  https   https://logs.example.org/ingest  web,worker             ",
+			"drain2  syslog  tcp+tls://logs.example.org:1234                         error",
+		})
+	})
+}
+
+func TestDrainsError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("DrainList", "app1").Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "drains -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestDrainsAdd(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.DrainCreateOptions{
+			Regex:    options.String("error"),
+			Services: &[]string{"web", "worker"},
+		}
+		i.On("DrainCreate", "app1", "drain1", "https://logs.example.org/ingest", opts).Return(fxDrain(), nil)
+
+		res, err := testExecute(e, "drains add drain1 https://logs.example.org/ingest -a app1 --service web --service worker --regex error", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Adding drain drain1... OK"})
+	})
+}
+
+func TestDrainsAddError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("DrainCreate", "app1", "drain1", "ftp://example.org", structs.DrainCreateOptions{}).Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "drains add drain1 ftp://example.org -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Adding drain drain1... "})
+	})
+}
+
+func TestDrainsRemove(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("DrainDelete", "app1", "drain1").Return(nil)
+
+		res, err := testExecute(e, "drains remove drain1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Removing drain drain1... OK"})
+	})
+}
+
+func TestDrainsRemoveError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("DrainDelete", "app1", "drain1").Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "drains remove drain1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Removing drain drain1... "})
+	})
+}
+
+func TestDrainsTest(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("DrainTest", "app1", "drain1").Return(nil)
+
+		res, err := testExecute(e, "drains test drain1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Sending test log to drain1... OK"})
+	})
+}
+
+func TestDrainsTestError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("DrainTest", "app1", "drain1").Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "drains test drain1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Sending test log to drain1... "})
+	})
+}
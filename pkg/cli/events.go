@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	register("events", "list rack events", Events, stdcli.CommandOptions{
+		Flags: append(stdcli.OptionFlags(structs.EventListOptions{}),
+			flagRack,
+			stdcli.BoolFlag("follow", "f", "poll for new events"),
+		),
+		Validate: stdcli.Args(0),
+	})
+}
+
+func Events(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.EventListOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	es, err := rack.EventList(opts)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	last := time.Now().UTC()
+
+	for _, e := range es {
+		seen[e.Id] = true
+		fmt.Fprintln(c, eventLine(e))
+	}
+
+	if !c.Bool("follow") {
+		return nil
+	}
+
+	// only poll the time range since the last request, the overlap is deduplicated by id
+	opts.Limit = nil
+	opts.Until = nil
+
+	for {
+		time.Sleep(WaitDuration)
+
+		since := time.Since(last) + WaitDuration
+		opts.Since = &since
+
+		last = time.Now().UTC()
+
+		es, err := rack.EventList(opts)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range es {
+			if seen[e.Id] {
+				continue
+			}
+
+			seen[e.Id] = true
+			fmt.Fprintln(c, eventLine(e))
+		}
+	}
+}
+
+func eventLine(e structs.Event) string {
+	keys := []string{}
+
+	for k := range e.Data {
+		if k != "rack" {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	data := make([]string, len(keys))
+
+	for i, k := range keys {
+		data[i] = fmt.Sprintf("%s=%s", k, e.Data[k])
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s %s", e.Timestamp.Format(time.RFC3339), e.Action, e.Status, strings.Join(data, " ")))
+}
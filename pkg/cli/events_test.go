@@ -0,0 +1,86 @@
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/cli"
+	mocksdk "github.com/convox/rack/pkg/mock/sdk"
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		es := structs.Events{
+			{Id: "event1", Action: "build:create", Data: map[string]string{"app": "app1", "id": "build1", "rack": "rack1"}, Status: "success", Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)},
+			{Id: "event2", Action: "release:promote", Data: map[string]string{"app": "app1", "id": "release1"}, Status: "start", Timestamp: time.Date(2021, 1, 2, 3, 5, 0, 0, time.UTC)},
+			{Id: "event3", Action: "instance:terminate", Data: map[string]string{"id": "i-1234"}, Status: "success", Timestamp: time.Date(2021, 1, 2, 3, 6, 0, 0, time.UTC)},
+		}
+		i.On("EventList", structs.EventListOptions{}).Return(es, nil)
+
+		res, err := testExecute(e, "events", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"2021-01-02T03:04:05Z build:create success app=app1 id=build1",
+			"2021-01-02T03:05:00Z release:promote start app=app1 id=release1",
+			"2021-01-02T03:06:00Z instance:terminate success id=i-1234",
+		})
+	})
+}
+
+func TestEventsError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("EventList", mock.Anything).Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "events", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestEventsFilters(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.EventListOptions{
+			Action: options.String("release:*"),
+			App:    options.String("app1"),
+			Limit:  options.Int(10),
+			Since:  options.Duration(2 * time.Hour),
+			Status: options.String("error"),
+			Until:  options.Duration(time.Hour),
+		}
+		i.On("EventList", opts).Return(structs.Events{}, nil)
+
+		res, err := testExecute(e, "events --action release:* -a app1 -l 10 --since 2h --status error --until 1h", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestEventsFollow(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		e1 := structs.Event{Id: "event1", Action: "app:create", Data: map[string]string{"name": "app1"}, Status: "success", Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+		e2 := structs.Event{Id: "event2", Action: "app:delete", Data: map[string]string{"name": "app1"}, Status: "success", Timestamp: time.Date(2021, 1, 2, 3, 5, 0, 0, time.UTC)}
+		i.On("EventList", structs.EventListOptions{}).Return(structs.Events{e1}, nil).Once()
+		i.On("EventList", mock.Anything).Return(structs.Events{e1, e2}, nil).Once()
+		i.On("EventList", mock.Anything).Return(nil, fmt.Errorf("err1")).Once()
+
+		res, err := testExecute(e, "events --follow", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{
+			"2021-01-02T03:04:05Z app:create success name=app1",
+			"2021-01-02T03:05:00Z app:delete success name=app1",
+		})
+	})
+}
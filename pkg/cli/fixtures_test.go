@@ -385,3 +385,28 @@ func fxSystemNLBInternal() *structs.System {
 		Version:    "21000101000000",
 	}
 }
+
+func fxDrain() *structs.Drain {
+	return &structs.Drain{
+		App:      "app1",
+		Name:     "drain1",
+		Services: []string{"web", "worker"},
+		Type:     "https",
+		Url:      "https://logs.example.org/ingest",
+	}
+}
+
+func fxWebhookDelivery() *structs.WebhookDelivery {
+	return &structs.WebhookDelivery{
+		Id:     "delivery1",
+		Action: "release:promote",
+		Attempts: []structs.WebhookAttempt{
+			{Attempt: 1, Error: "response status 503", Status: 503, Time: fxStarted},
+			{Attempt: 2, Status: 200, Time: fxStarted.Add(30 * time.Second)},
+		},
+		Created: fxStarted,
+		Event:   `{"action":"release:promote","status":"success"}`,
+		Status:  "success",
+		Updated: fxStarted.Add(30 * time.Second),
+	}
+}
@@ -17,6 +17,12 @@ func init() {
 		Validate: stdcli.Args(0),
 	})
 
+	register("instances drain", "move processes off an instance", InstancesDrain, stdcli.CommandOptions{
+		Flags:    append(stdcli.OptionFlags(structs.InstanceDrainOptions{}), flagRack),
+		Usage:    "<id>",
+		Validate: stdcli.Args(1),
+	})
+
 	register("instances keyroll", "roll ssh key on instances", InstancesKeyroll, stdcli.CommandOptions{
 		Flags:    []stdcli.Flag{flagRack, flagWait},
 		Validate: stdcli.Args(0),
@@ -28,7 +34,9 @@ func init() {
 	})
 
 	register("instances terminate", "terminate an instance", InstancesTerminate, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack},
+		Flags: append(stdcli.OptionFlags(structs.InstanceDrainOptions{}), flagRack,
+			stdcli.BoolFlag("drain", "", "move processes off the instance before terminating it"),
+		),
 		Validate: stdcli.ArgsMin(1),
 	})
 }
@@ -48,6 +56,22 @@ func Instances(rack sdk.Interface, c *stdcli.Context) error {
 	return t.Print()
 }
 
+func InstancesDrain(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.InstanceDrainOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	c.Startf("Draining instance")
+
+	if err := rack.InstanceDrain(c.Arg(0), opts); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
 func InstancesKeyroll(rack sdk.Interface, c *stdcli.Context) error {
 	c.Startf("Rolling instance key")
 
@@ -108,6 +132,22 @@ func InstancesSsh(rack sdk.Interface, c *stdcli.Context) error {
 func InstancesTerminate(rack sdk.Interface, c *stdcli.Context) error {
 	c.Startf("Terminating instance")
 
+	if c.Bool("drain") {
+		var opts structs.InstanceDrainOptions
+
+		if err := c.Options(&opts); err != nil {
+			return err
+		}
+
+		opts.Terminate = options.Bool(true)
+
+		if err := rack.InstanceDrain(c.Arg(0), opts); err != nil {
+			return err
+		}
+
+		return c.OK()
+	}
+
 	if err := rack.InstanceTerminate(c.Arg(0)); err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/convox/rack/pkg/cli"
 	mocksdk "github.com/convox/rack/pkg/mock/sdk"
+	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -41,6 +42,30 @@ func TestInstancesError(t *testing.T) {
 	})
 }
 
+func TestInstancesDrain(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("InstanceDrain", "instance1", structs.InstanceDrainOptions{Timeout: options.Int(600)}).Return(nil)
+
+		res, err := testExecute(e, "instances drain instance1 --timeout 600", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Draining instance... OK"})
+	})
+}
+
+func TestInstancesDrainError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("InstanceDrain", "instance1", structs.InstanceDrainOptions{}).Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "instances drain instance1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Draining instance... "})
+	})
+}
+
 func TestInstancesKeyroll(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("InstanceKeyroll").Return(nil)
@@ -140,3 +165,15 @@ func TestInstancesTerminateError(t *testing.T) {
 		res.RequireStdout(t, []string{"Terminating instance... "})
 	})
 }
+
+func TestInstancesTerminateDrain(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("InstanceDrain", "instance1", structs.InstanceDrainOptions{Terminate: options.Bool(true)}).Return(nil)
+
+		res, err := testExecute(e, "instances terminate instance1 --drain", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Terminating instance... OK"})
+	})
+}
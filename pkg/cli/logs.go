@@ -1,17 +1,44 @@
 package cli
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
 	"io"
+	"strings"
+	"time"
 
+	"github.com/convox/rack/pkg/helpers"
 	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 	"github.com/convox/rack/sdk"
 	"github.com/convox/stdcli"
 )
 
+// logsWindow is how far logs go back by default, as the Since option does
+const logsWindow = 2 * time.Minute
+
+var flagsLogsSearch = []stdcli.Flag{
+	stdcli.StringFlag("cursor", "", "continue from the cursor returned by a previous search"),
+	stdcli.StringFlag("end", "", "end of the search as a RFC3339 time or a duration ago"),
+	stdcli.IntFlag("limit", "", "maximum number of results"),
+	stdcli.StringFlag("output", "o", "output format (json)"),
+	stdcli.StringFlag("start", "", "start of the search as a RFC3339 time or a duration ago"),
+}
+
 func init() {
 	register("logs", "get logs for an app", Logs, stdcli.CommandOptions{
-		Flags:    append(stdcli.OptionFlags(structs.LogsOptions{}), flagApp, flagNoFollow, flagRack),
+		Flags: append(stdcli.OptionFlags(structs.LogsOptions{}),
+			flagApp,
+			flagNoFollow,
+			flagRack,
+			stdcli.StringFlag("output", "o", "output format (json)"),
+		),
+		Validate: stdcli.Args(0),
+	})
+
+	register("logs search", "search app logs over a time range", LogsSearch, stdcli.CommandOptions{
+		Flags:    append(append(stdcli.OptionFlags(structs.LogsOptions{}), flagsLogsSearch...), flagApp, flagRack),
 		Validate: stdcli.Args(0),
 	})
 }
@@ -27,6 +54,16 @@ func Logs(rack sdk.Interface, c *stdcli.Context) error {
 		opts.Follow = options.Bool(false)
 	}
 
+	// without --since the logs end at --until and start the default window
+	// before it
+	if opts.Until != nil && opts.Since == nil {
+		opts.Since = options.Duration(*opts.Until + logsWindow)
+	}
+
+	if opts.Until != nil && *opts.Until >= *opts.Since {
+		return fmt.Errorf("--until must be more recent than --since")
+	}
+
 	opts.Prefix = options.Bool(true)
 
 	r, err := rack.AppLogs(app(c), opts)
@@ -34,7 +71,128 @@ func Logs(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
-	_, err = io.Copy(c, r)
+	switch c.String("output") {
+	case "":
+		_, err = io.Copy(c, r)
+		return err
+	case "json":
+		_, err := writeLogs(c, app(c), "json", r)
+		return err
+	default:
+		return fmt.Errorf("unknown output format: %s", c.String("output"))
+	}
+}
+
+func LogsSearch(rack sdk.Interface, c *stdcli.Context) error {
+	return logsSearch(c, app(c), func(opts structs.LogsOptions) (io.ReadCloser, error) {
+		return rack.AppLogs(app(c), opts)
+	})
+}
+
+func logsSearch(c *stdcli.Context, app string, fn func(structs.LogsOptions) (io.ReadCloser, error)) error {
+	var opts structs.LogsOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	switch c.String("output") {
+	case "", "json":
+	default:
+		return fmt.Errorf("unknown output format: %s", c.String("output"))
+	}
+
+	if v := c.String("cursor"); v != "" {
+		opts.Cursor = options.String(v)
+	}
+
+	if v := c.String("end"); v != "" {
+		t, err := parseLogTime(v)
+		if err != nil {
+			return err
+		}
+		opts.End = options.Time(t)
+	}
+
+	if v := c.String("start"); v != "" {
+		t, err := parseLogTime(v)
+		if err != nil {
+			return err
+		}
+		opts.Start = options.Time(t)
+	}
+
+	opts.Follow = options.Bool(false)
+	opts.Limit = options.Int(helpers.CoalesceInt(c.Int("limit"), 100))
+	opts.Prefix = options.Bool(true)
+
+	r, err := fn(opts)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	cursor, err := writeLogs(c, app, c.String("output"), r)
+	if err != nil {
+		return err
+	}
+
+	if cursor != "" {
+		fmt.Fprintf(c.Writer().Stderr, "more results available: --cursor %s\n", cursor)
+	}
+
+	return nil
+}
+
+// parseLogTime accepts either a RFC3339 time or a duration before now
+func parseLogTime(v string) (time.Time, error) {
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().UTC().Add(-1 * d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s", v)
+	}
+
+	return t.UTC(), nil
+}
+
+// writeLogs copies prefixed log lines to w in the given output format and
+// returns the search cursor if one was sent
+func writeLogs(w io.Writer, app, output string, r io.Reader) (string, error) {
+	cursor := ""
+
+	enc := json.NewEncoder(w)
+
+	s := bufio.NewScanner(r)
+
+	s.Buffer(make([]byte, 4096), 1024*1024)
+
+	for s.Scan() {
+		line := s.Text()
+
+		if strings.HasPrefix(line, structs.LogsCursorPrefix) {
+			cursor = strings.TrimPrefix(line, structs.LogsCursorPrefix)
+			continue
+		}
+
+		if output != "json" {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		e, err := helpers.ParseLogLine(app, line)
+		if err != nil {
+			e = &structs.LogEntry{App: app, Message: line}
+		}
+
+		if err := enc.Encode(e); err != nil {
+			return "", err
+		}
+	}
 
-	return err
+	return cursor, s.Err()
 }
@@ -3,6 +3,7 @@ package cli_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/convox/rack/pkg/cli"
 	mocksdk "github.com/convox/rack/pkg/mock/sdk"
@@ -37,3 +38,136 @@ func TestLogsError(t *testing.T) {
 		res.RequireStdout(t, []string{""})
 	})
 }
+
+func TestLogsFilters(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.LogsOptions{
+			Fields:  &[]string{"level=error", "code=500"},
+			Prefix:  options.Bool(true),
+			Process: options.String("pid1"),
+			Service: options.String("web"),
+			Since:   options.Duration(time.Hour + 2*time.Minute),
+			Until:   options.Duration(time.Hour),
+		}
+		i.On("AppLogs", "app1", opts).Return(testLogs(fxLogs()), nil)
+
+		res, err := testExecute(e, "logs -a app1 --service web --process pid1 --field level=error --field code=500 --until 1h", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			fxLogs()[0],
+			fxLogs()[1],
+		})
+	})
+}
+
+func TestLogsUntilSince(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.LogsOptions{
+			Prefix: options.Bool(true),
+			Since:  options.Duration(2 * time.Hour),
+			Until:  options.Duration(time.Hour),
+		}
+		i.On("AppLogs", "app1", opts).Return(testLogs(fxLogs()), nil)
+
+		res, err := testExecute(e, "logs -a app1 --since 2h --until 1h", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+
+		res, err = testExecute(e, "logs -a app1 --since 1h --until 1h", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: --until must be more recent than --since"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestLogsOutputJSON(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppLogs", "app1", structs.LogsOptions{Prefix: options.Bool(true)}).Return(testLogs([]string{
+			"2021-01-02T03:04:05Z service/web/pid1 log1",
+			"log2",
+		}), nil)
+
+		res, err := testExecute(e, "logs -a app1 --output json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			`{"timestamp":"2021-01-02T03:04:05Z","app":"app1","service":"web","process":"pid1","message":"log1"}`,
+			`{"timestamp":"0001-01-01T00:00:00Z","app":"app1","service":"","process":"","message":"log2"}`,
+		})
+	})
+}
+
+func TestLogsOutputUnknown(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppLogs", "app1", structs.LogsOptions{Prefix: options.Bool(true)}).Return(testLogs(fxLogs()), nil)
+
+		res, err := testExecute(e, "logs -a app1 --output yaml", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: unknown output format: yaml"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestLogsSearch(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.LogsOptions{
+			End:    options.Time(time.Date(2021, 1, 2, 4, 0, 0, 0, time.UTC)),
+			Follow: options.Bool(false),
+			Limit:  options.Int(2),
+			Prefix: options.Bool(true),
+			Regex:  options.String("err"),
+			Start:  options.Time(time.Date(2021, 1, 2, 3, 0, 0, 0, time.UTC)),
+		}
+		i.On("AppLogs", "app1", opts).Return(testLogs([]string{
+			"2021-01-02T03:04:05Z service/web/pid1 err1",
+			"2021-01-02T03:04:06Z service/web/pid1 err2",
+			structs.LogsCursorPrefix + "cursor1",
+		}), nil)
+
+		res, err := testExecute(e, "logs search -a app1 --start 2021-01-02T03:00:00Z --end 2021-01-02T04:00:00Z --regex err --limit 2", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{"more results available: --cursor cursor1"})
+		res.RequireStdout(t, []string{
+			"2021-01-02T03:04:05Z service/web/pid1 err1",
+			"2021-01-02T03:04:06Z service/web/pid1 err2",
+		})
+	})
+}
+
+func TestLogsSearchCursor(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.LogsOptions{
+			Cursor: options.String("cursor1"),
+			Follow: options.Bool(false),
+			Limit:  options.Int(100),
+			Prefix: options.Bool(true),
+		}
+		i.On("AppLogs", "app1", opts).Return(testLogs([]string{
+			"2021-01-02T03:04:07Z service/web/pid1 err3",
+		}), nil)
+
+		res, err := testExecute(e, "logs search -a app1 --cursor cursor1 -o json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			`{"timestamp":"2021-01-02T03:04:07Z","app":"app1","service":"web","process":"pid1","message":"err3"}`,
+		})
+	})
+}
+
+func TestLogsSearchInvalidTime(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		res, err := testExecute(e, "logs search -a app1 --start yesterday", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: invalid time: yesterday"})
+		res.RequireStdout(t, []string{""})
+	})
+}
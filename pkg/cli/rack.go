@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -79,6 +80,9 @@ var paramGroups = map[string]map[string]bool{
 		"HttpProxy":                             true, // dual-listed in network
 		"IMDSHttpPutResponseHopLimit":           true,
 		"IMDSHttpTokens":                        true,
+		"ImageSignatureRequired":                true,
+		"ImageSigning":                          true,
+		"ImageSigningTrustedKeys":               true,
 		"InstancePolicy":                        true, // dual-listed in instances
 		"InstanceSecurityGroup":                 true,
 		"InstancesIpToIncludInWhiteListing":     true,
@@ -173,6 +177,7 @@ var paramGroups = map[string]map[string]bool{
 		"MaintainTimerState":      true,
 		"Telemetry":               true,
 		"Version":                 true,
+		"WorkerIntervals":         true,
 	},
 }
 
@@ -182,14 +187,14 @@ var paramGroups = map[string]map[string]bool{
 var groupDescriptions = map[string]string{
 	"network":   "VPC, subnets, gateways, connectivity, proxy",
 	"nlb":       "Network Load Balancer: listeners, cross-zone, allow-CIDR, preserve-client-IP",
-	"security":  "Credentials, allowlists, SGs, SSL, IMDS, container hardening",
+	"security":  "Credentials, allowlists, SGs, SSL, IMDS, container hardening, image signing",
 	"scaling":   "Autoscaling, spot fleet, instance counts, schedules, HA",
 	"instances": "AMI, instance type, boot/run commands, IAM policy, volumes, tenancy",
 	"build":     "Build method, build instance, Fargate build, image pruning",
 	"api":       "Rack API web process config, router, ingress toggles",
 	"logging":   "Logs destination, retention, syslog format",
 	"storage":   "S3 versioning, DynamoDB protection, EFS encryption",
-	"meta":      "Version, development mode, telemetry, client ID, ECS tuning",
+	"meta":      "Version, development mode, telemetry, client ID, ECS and worker tuning",
 }
 
 // resolveGroup resolves a possibly-partial group name to an exact group key.
@@ -314,6 +319,17 @@ func init() {
 		Validate: stdcli.Args(0),
 	})
 
+	register("rack capacity plan", "check whether a scale change or release fits on the rack", RackCapacityPlan, stdcli.CommandOptions{
+		Flags: append(stdcli.OptionFlags(structs.CapacityPlanOptions{}), flagApp, flagRack),
+		Usage: "[service]",
+		Validate: func(c *stdcli.Context) error {
+			if c.String("release") != "" {
+				return stdcli.Args(0)(c)
+			}
+			return stdcli.Args(1)(c)
+		},
+	})
+
 	registerWithoutProvider("rack install", "install a rack", RackInstall, stdcli.CommandOptions{
 		Flags:    append(stdcli.OptionFlags(structs.SystemInstallOptions{})),
 		Usage:    "<type> [Parameter=Value]...",
@@ -325,6 +341,11 @@ func init() {
 		Validate: stdcli.Args(0),
 	})
 
+	register("rack logs search", "search rack logs over a time range", RackLogsSearch, stdcli.CommandOptions{
+		Flags:    append(append(stdcli.OptionFlags(structs.LogsOptions{}), flagsLogsSearch...), flagRack),
+		Validate: stdcli.Args(0),
+	})
+
 	register("rack params", "display rack parameters", RackParams, stdcli.CommandOptions{
 		Flags: []stdcli.Flag{
 			flagRack,
@@ -393,6 +414,11 @@ func init() {
 		Flags:    []stdcli.Flag{flagRack},
 		Validate: stdcli.Args(0),
 	})
+
+	register("rack workers", "list rack background workers", RackWorkers, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Validate: stdcli.Args(0),
+	})
 }
 
 func Rack(rack sdk.Interface, c *stdcli.Context) error {
@@ -478,6 +504,65 @@ func RackAccessKeyRotate(rack sdk.Interface, c *stdcli.Context) error {
 	return c.OK()
 }
 
+func RackCapacityPlan(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.CapacityPlanOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	opts.App = options.String(app(c))
+
+	if s := c.Arg(0); s != "" {
+		opts.Service = options.String(s)
+	}
+
+	plan, err := rack.CapacityPlan(opts)
+	if err != nil {
+		return err
+	}
+
+	return capacityPlanPrint(c, plan)
+}
+
+func capacityPlanPrint(c *stdcli.Context, plan *structs.CapacityPlan) error {
+	i := c.Info()
+
+	fits := "No"
+
+	if plan.Fits {
+		fits = "Yes"
+	}
+
+	i.Add("Fits", fits)
+	i.Add("Instances", fmt.Sprintf("%d", plan.Instances))
+	i.Add("Required", fmt.Sprintf("%d", plan.InstancesRequired))
+
+	if err := i.Print(); err != nil {
+		return err
+	}
+
+	if len(plan.Pending) == 0 {
+		return nil
+	}
+
+	c.Writef("\n")
+
+	t := c.Table("APP", "SERVICE", "PENDING")
+
+	for _, p := range plan.Pending {
+		pending := fmt.Sprintf("%d", p.Count)
+
+		if p.Oversized {
+			pending += " (larger than an instance)"
+		}
+
+		t.AddRow(p.App, p.Service, pending)
+	}
+
+	return t.Print()
+}
+
 func RackInstall(rack sdk.Interface, c *stdcli.Context) error {
 	var opts structs.SystemInstallOptions
 
@@ -582,6 +667,10 @@ func RackLogs(rack sdk.Interface, c *stdcli.Context) error {
 	return nil
 }
 
+func RackLogsSearch(rack sdk.Interface, c *stdcli.Context) error {
+	return logsSearch(c, "", rack.SystemLogs)
+}
+
 func RackParams(rack sdk.Interface, c *stdcli.Context) error {
 	var (
 		groupFilter   map[string]bool
@@ -975,6 +1064,50 @@ func RackWait(rack sdk.Interface, c *stdcli.Context) error {
 	return c.OK()
 }
 
+func RackWorkers(rack sdk.Interface, c *stdcli.Context) error {
+	ws, err := rack.SystemWorkers()
+	if err != nil {
+		return err
+	}
+
+	t := c.Table("NAME", "STATUS", "INTERVAL", "LAST RUN", "DURATION", "NEXT RUN", "FAILURES", "ERROR")
+
+	for _, w := range ws {
+		interval := w.Interval.String()
+		next := helpers.Ago(w.NextRun)
+
+		if w.Disabled {
+			interval = "disabled"
+			next = ""
+		}
+
+		duration := ""
+
+		if !w.LastRun.IsZero() {
+			duration = w.Duration.Round(time.Millisecond).String()
+		}
+
+		t.AddRow(w.Name, workerStatus(w), interval, helpers.Ago(w.LastRun), duration, next, fmt.Sprintf("%d/%d", w.Failures, w.Runs), w.Error)
+	}
+
+	return t.Print()
+}
+
+func workerStatus(w structs.Worker) string {
+	switch {
+	case w.Disabled:
+		return "disabled"
+	case w.Running:
+		return "running"
+	case w.LastRun.IsZero():
+		return "pending"
+	case w.Error != "":
+		return "error"
+	default:
+		return "ok"
+	}
+}
+
 func RackSyncWhiteListInstancesIp(rack sdk.Interface, c *stdcli.Context) error {
 	err := rack.SyncInstancesIpInSecurityGroup()
 	if err != nil {
@@ -221,5 +221,5 @@ func TestParamGroupsCoverRackJSON(t *testing.T) {
 	}
 	require.Empty(t, stale, "paramGroups members not in rack.json Parameters: %v", stale)
 
-	require.Equal(t, 110, len(rack.Parameters), "post-hardening rack.json should have 110 Parameters")
+	require.Equal(t, 114, len(rack.Parameters), "post-hardening rack.json should have 114 Parameters")
 }
@@ -112,6 +112,67 @@ func TestRackNLBInternal(t *testing.T) {
 	})
 }
 
+func TestRackCapacityPlan(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("CapacityPlan", structs.CapacityPlanOptions{App: options.String("app1"), Count: options.Int(2), Service: options.String("web")}).Return(&structs.CapacityPlan{
+			Fits:              true,
+			Instances:         3,
+			InstancesRequired: 3,
+			Pending:           structs.CapacityPendings{},
+		}, nil)
+
+		res, err := testExecute(e, "rack capacity plan web --count 2 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Fits       Yes",
+			"Instances  3",
+			"Required   3",
+		})
+	})
+}
+
+func TestRackCapacityPlanRelease(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("CapacityPlan", structs.CapacityPlanOptions{App: options.String("app1"), Release: options.String("R1234567")}).Return(&structs.CapacityPlan{
+			Fits:              false,
+			Instances:         3,
+			InstancesRequired: 4,
+			Pending: structs.CapacityPendings{
+				{App: "app1", Service: "big", Count: 1, Oversized: true},
+				{App: "app1", Service: "web", Count: 2},
+			},
+		}, nil)
+
+		res, err := testExecute(e, "rack capacity plan --release R1234567 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Fits       No",
+			"Instances  3",
+			"Required   4",
+			"",
+			"APP   SERVICE  PENDING",
+			"app1  big      1 (larger than an instance)",
+			"app1  web      2",
+		})
+	})
+}
+
+func TestRackCapacityPlanError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("CapacityPlan", structs.CapacityPlanOptions{App: options.String("app1"), Service: options.String("web")}).Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "rack capacity plan web -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
 func TestRackInstall(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -802,3 +863,59 @@ func TestRackWaitError(t *testing.T) {
 		})
 	})
 }
+
+func TestRackWorkers(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("SystemWorkers").Return(structs.Workers{
+			{Name: "cleanup", Interval: time.Hour},
+			{Name: "monitor", Duration: 1500 * time.Millisecond, Failures: 1, Interval: 5 * time.Minute, LastRun: fxStarted, Runs: 3},
+			{Name: "spot-replace", Disabled: true, Duration: 2 * time.Second, Error: "err1", Failures: 1, LastRun: fxStarted, Runs: 1},
+			{Name: "sync-instance-ips", Interval: 30 * time.Minute, LastRun: fxStarted, Running: true, Runs: 1},
+		}, nil)
+
+		res, err := testExecute(e, "rack workers", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"NAME               STATUS    INTERVAL  LAST RUN    DURATION  NEXT RUN  FAILURES  ERROR",
+			"cleanup            pending   1h0m0s                                    0/0       ",
+			"monitor            ok        5m0s      2 days ago  1.5s                1/3       ",
+			"spot-replace       disabled  disabled  2 days ago  2s                  1/1       err1",
+			"sync-instance-ips  running   30m0s     2 days ago  0s                  0/1       ",
+		})
+	})
+}
+
+func TestRackWorkersError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("SystemWorkers").Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "rack workers", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestRackLogsSearch(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.LogsOptions{
+			Follow:  options.Bool(false),
+			Limit:   options.Int(100),
+			Prefix:  options.Bool(true),
+			Service: options.String("api"),
+		}
+		i.On("SystemLogs", opts).Return(testLogs(fxLogsSystem()), nil)
+
+		res, err := testExecute(e, "rack logs search --service api", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			fxLogsSystem()[0],
+			fxLogsSystem()[1],
+		})
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 	"github.com/convox/rack/sdk"
 	"github.com/convox/stdcli"
@@ -12,7 +13,9 @@ import (
 
 func init() {
 	register("scale", "scale a service", Scale, stdcli.CommandOptions{
-		Flags: append(stdcli.OptionFlags(structs.ServiceUpdateOptions{}), flagApp, flagRack, flagWait),
+		Flags: append(stdcli.OptionFlags(structs.ServiceUpdateOptions{}), flagApp, flagRack, flagWait,
+			stdcli.BoolFlag("dry-run", "", "check whether the change fits on the rack without applying it"),
+		),
 		Usage: "<service>",
 		Validate: func(c *stdcli.Context) error {
 			if c.Value("count") != nil || c.Value("cpu") != nil || c.Value("memory") != nil {
@@ -43,6 +46,21 @@ func Scale(rack sdk.Interface, c *stdcli.Context) error {
 	if opts.Count != nil || opts.Cpu != nil || opts.Memory != nil {
 		service := c.Arg(0)
 
+		if c.Bool("dry-run") {
+			plan, err := rack.CapacityPlan(structs.CapacityPlanOptions{
+				App:     options.String(app(c)),
+				Count:   opts.Count,
+				Cpu:     opts.Cpu,
+				Memory:  opts.Memory,
+				Service: options.String(service),
+			})
+			if err != nil {
+				return err
+			}
+
+			return capacityPlanPrint(c, plan)
+		}
+
 		c.Startf("Scaling <service>%s</service>", service)
 
 		if s.Version <= "20180708231844" {
@@ -98,3 +98,30 @@ func TestScaleUpdateClassic(t *testing.T) {
 		res.RequireStdout(t, []string{"Scaling web... OK"})
 	})
 }
+
+func TestScaleUpdateDryRun(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("CapacityPlan", structs.CapacityPlanOptions{App: options.String("app1"), Count: options.Int(30), Memory: options.Int(1024), Service: options.String("web")}).Return(&structs.CapacityPlan{
+			Fits:              false,
+			Instances:         3,
+			InstancesRequired: 5,
+			Pending: structs.CapacityPendings{
+				{App: "app1", Service: "web", Count: 12},
+			},
+		}, nil)
+
+		res, err := testExecute(e, "scale web --count 30 --memory 1024 --dry-run -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Fits       No",
+			"Instances  3",
+			"Required   5",
+			"",
+			"APP   SERVICE  PENDING",
+			"app1  web      12",
+		})
+	})
+}
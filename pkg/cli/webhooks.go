@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	register("webhooks deliveries", "list recent deliveries for a webhook", WebhooksDeliveries, stdcli.CommandOptions{
+		Flags:    append(stdcli.OptionFlags(structs.WebhookDeliveryListOptions{}), flagRack),
+		Usage:    "<webhook>",
+		Validate: stdcli.Args(1),
+	})
+
+	register("webhooks preview", "render a sample event for a webhook without sending it", WebhooksPreview, stdcli.CommandOptions{
+		Flags:    append(stdcli.OptionFlags(structs.WebhookPreviewOptions{}), flagRack),
+		Usage:    "<webhook>",
+		Validate: stdcli.Args(1),
+	})
+
+	register("webhooks redeliver", "redeliver a webhook event", WebhooksRedeliver, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Usage:    "<webhook> <delivery>",
+		Validate: stdcli.Args(2),
+	})
+}
+
+func WebhooksDeliveries(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.WebhookDeliveryListOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	ds, err := rack.WebhookDeliveryList(c.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+
+	t := c.Table("ID", "ACTION", "STATUS", "ATTEMPTS", "LAST", "CREATED")
+
+	for _, d := range ds {
+		last := ""
+
+		if len(d.Attempts) > 0 {
+			a := d.Attempts[len(d.Attempts)-1]
+			last = coalesce(a.Error, fmt.Sprintf("%d", a.Status))
+		}
+
+		t.AddRow(d.Id, d.Action, d.Status, fmt.Sprintf("%d", len(d.Attempts)), last, helpers.Ago(d.Created))
+	}
+
+	return t.Print()
+}
+
+func WebhooksPreview(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.WebhookPreviewOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	wp, err := rack.WebhookPreview(c.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+
+	i := c.Info()
+
+	i.Add("Matched", fmt.Sprintf("%t", wp.Matched))
+	i.Add("Content Type", wp.ContentType)
+
+	if err := i.Print(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c, "\n%s\n", wp.Payload)
+
+	return nil
+}
+
+func WebhooksRedeliver(rack sdk.Interface, c *stdcli.Context) error {
+	c.Startf("Redelivering <id>%s</id>", c.Arg(1))
+
+	if err := rack.WebhookRedeliver(c.Arg(0), c.Arg(1)); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
@@ -0,0 +1,126 @@
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/convox/rack/pkg/cli"
+	mocksdk "github.com/convox/rack/pkg/mock/sdk"
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooksDeliveries(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		d2 := structs.WebhookDelivery{
+			Id:       "delivery2",
+			Action:   "app:create",
+			Attempts: []structs.WebhookAttempt{{Attempt: 1, Error: "dial tcp: i/o timeout", Time: fxStarted}},
+			Created:  fxStarted,
+			Status:   "retrying",
+		}
+		i.On("WebhookDeliveryList", "hook1", structs.WebhookDeliveryListOptions{}).Return(structs.WebhookDeliveries{*fxWebhookDelivery(), d2}, nil)
+
+		res, err := testExecute(e, "webhooks deliveries hook1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"ID         ACTION           STATUS    ATTEMPTS  LAST                   CREATED",
+			"delivery1  release:promote  success   2         200                    2 days ago",
+			"delivery2  app:create       retrying  1         dial tcp: i/o timeout  2 days ago",
+		})
+	})
+}
+
+func TestWebhooksDeliveriesError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("WebhookDeliveryList", "hook1", structs.WebhookDeliveryListOptions{}).Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "webhooks deliveries hook1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestWebhooksDeliveriesOptions(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.WebhookDeliveryListOptions{
+			Limit:  options.Int(5),
+			Status: options.String("dead-letter"),
+		}
+		i.On("WebhookDeliveryList", "hook1", opts).Return(structs.WebhookDeliveries{}, nil)
+
+		res, err := testExecute(e, "webhooks deliveries hook1 -l 5 --status dead-letter", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"ID  ACTION  STATUS  ATTEMPTS  LAST  CREATED"})
+	})
+}
+
+func TestWebhooksPreview(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.WebhookPreviewOptions{
+			Action:   options.String("release:promote"),
+			Status:   options.String("error"),
+			Template: options.String("slack"),
+		}
+		wp := &structs.WebhookPreview{
+			ContentType: "application/json",
+			Matched:     true,
+			Payload:     `{"text":"[rack1] release:promote error app=example id=RABCDEFGHIJ"}`,
+		}
+		i.On("WebhookPreview", "hook1", opts).Return(wp, nil)
+
+		res, err := testExecute(e, "webhooks preview hook1 --action release:promote --status error --template slack", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Matched       true",
+			"Content Type  application/json",
+			"",
+			`{"text":"[rack1] release:promote error app=example id=RABCDEFGHIJ"}`,
+		})
+	})
+}
+
+func TestWebhooksPreviewError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("WebhookPreview", "hook1", structs.WebhookPreviewOptions{Filter: options.String("service=web")}).Return(nil, fmt.Errorf("invalid filter key: service"))
+
+		res, err := testExecute(e, "webhooks preview hook1 --filter service=web", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: invalid filter key: service"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestWebhooksRedeliver(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("WebhookRedeliver", "hook1", "delivery1").Return(nil)
+
+		res, err := testExecute(e, "webhooks redeliver hook1 delivery1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Redelivering delivery1... OK"})
+	})
+}
+
+func TestWebhooksRedeliverError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("WebhookRedeliver", "hook1", "delivery1").Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "webhooks redeliver hook1 delivery1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Redelivering delivery1... "})
+	})
+}
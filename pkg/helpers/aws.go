@@ -341,9 +341,21 @@ func CloudformationUpdate(cf cloudformationiface.CloudFormationAPI, stack string
 }
 
 func CloudWatchLogsSubscribe(ctx context.Context, cw cloudwatchlogsiface.CloudWatchLogsAPI, group, stream string, opts structs.LogsOptions) (io.ReadCloser, error) {
+	if opts.Start == nil && opts.Since != nil && opts.Until != nil && *opts.Until >= *opts.Since {
+		return nil, fmt.Errorf("until %s must be more recent than since %s", *opts.Until, *opts.Since)
+	}
+
+	if _, err := NewLogFilter(opts); err != nil {
+		return nil, err
+	}
+
 	r, w := io.Pipe()
 
-	go CloudWatchLogsStream(ctx, cw, w, group, stream, opts)
+	if opts.Search() {
+		go CloudWatchLogsSearch(ctx, cw, w, group, stream, opts)
+	} else {
+		go CloudWatchLogsStream(ctx, cw, w, group, stream, opts)
+	}
 
 	return r, nil
 }
@@ -353,30 +365,16 @@ var TimeNow = time.Now // defaults to the real clock
 func CloudWatchLogsStream(ctx context.Context, cw cloudwatchlogsiface.CloudWatchLogsAPI, w io.WriteCloser, group, stream string, opts structs.LogsOptions) error {
 	defer w.Close()
 
-	req := &cloudwatchlogs.FilterLogEventsInput{
-		LogGroupName: aws.String(group),
-	}
-
-	if opts.Filter != nil {
-		req.FilterPattern = aws.String(*opts.Filter)
+	filter, err := NewLogFilter(opts)
+	if err != nil {
+		return err
 	}
 
-	follow := DefaultBool(opts.Follow, true)
+	req := cloudWatchLogsRequest(group, stream, opts)
 
-	var start int64
+	follow := DefaultBool(opts.Follow, true) && opts.Until == nil
 
-	if opts.Since != nil {
-		start = TimeNow().UTC().Add((*opts.Since) * -1).UnixMilli()
-		req.StartTime = aws.Int64(start)
-	} else {
-		req.StartTime = aws.Int64(TimeNow().UTC().Add(-1 * time.Hour).UnixMilli())
-	}
-
-	if stream != "" {
-		req.LogStreamNames = []*string{aws.String(stream)}
-	} else {
-		req.Interleaved = aws.Bool(true)
-	}
+	start := *req.StartTime
 
 	seen := map[string]bool{}
 
@@ -424,7 +422,7 @@ func CloudWatchLogsStream(ctx context.Context, cw cloudwatchlogsiface.CloudWatch
 
 			sort.Slice(es, func(i, j int) bool { return *es[i].Timestamp < *es[j].Timestamp })
 
-			if _, err := writeLogEvents(w, es, opts); err != nil {
+			if _, err := writeLogEvents(w, es, opts, filter); err != nil {
 				return err
 			}
 
@@ -441,6 +439,138 @@ func CloudWatchLogsStream(ctx context.Context, cw cloudwatchlogsiface.CloudWatch
 	}
 }
 
+// CloudWatchLogsSearch writes at most opts.Limit matching events between the
+// start and end of the search, followed by a cursor if more events remain
+func CloudWatchLogsSearch(ctx context.Context, cw cloudwatchlogsiface.CloudWatchLogsAPI, w io.WriteCloser, group, stream string, opts structs.LogsOptions) error {
+	defer w.Close()
+
+	filter, err := NewLogFilter(opts)
+	if err != nil {
+		return err
+	}
+
+	req := cloudWatchLogsRequest(group, stream, opts)
+
+	var cur logsCursor
+
+	if opts.Cursor != nil {
+		c, err := decodeLogsCursor(*opts.Cursor)
+		if err != nil {
+			return err
+		}
+
+		cur = *c
+		req.StartTime = aws.Int64(cur.Timestamp)
+	}
+
+	skip := map[string]bool{}
+
+	for _, id := range cur.Events {
+		skip[id] = true
+	}
+
+	limit := DefaultInt(opts.Limit, 100)
+	count := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		res, err := cw.FilterLogEvents(req)
+		if err != nil {
+			switch AwsErrorCode(err) {
+			case "ThrottlingException":
+				time.Sleep(3 * time.Second)
+				continue
+			default:
+				return err
+			}
+		}
+
+		es := res.Events
+
+		sort.SliceStable(es, func(i, j int) bool { return *es[i].Timestamp < *es[j].Timestamp })
+
+		for _, e := range es {
+			if skip[*e.EventId] || !filter.Match(*e.LogStreamName, *e.Message) {
+				continue
+			}
+
+			if count >= limit {
+				c, err := cur.encode()
+				if err != nil {
+					return err
+				}
+
+				_, err = fmt.Fprintf(w, "%s%s\n", structs.LogsCursorPrefix, c)
+
+				return err
+			}
+
+			if _, err := w.Write([]byte(formatLogEvent(e, opts))); err != nil {
+				return err
+			}
+
+			count++
+
+			if *e.Timestamp != cur.Timestamp {
+				cur = logsCursor{Timestamp: *e.Timestamp}
+			}
+
+			cur.Events = append(cur.Events, *e.EventId)
+		}
+
+		if res.NextToken == nil {
+			return nil
+		}
+
+		req.NextToken = res.NextToken
+	}
+}
+
+func cloudWatchLogsRequest(group, stream string, opts structs.LogsOptions) *cloudwatchlogs.FilterLogEventsInput {
+	req := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(group),
+	}
+
+	if opts.Filter != nil {
+		req.FilterPattern = aws.String(*opts.Filter)
+	}
+
+	switch {
+	case opts.Start != nil:
+		req.StartTime = aws.Int64(opts.Start.UTC().UnixMilli())
+	case opts.Since != nil:
+		req.StartTime = aws.Int64(TimeNow().UTC().Add((*opts.Since) * -1).UnixMilli())
+	default:
+		req.StartTime = aws.Int64(TimeNow().UTC().Add(-1 * time.Hour).UnixMilli())
+	}
+
+	switch {
+	case opts.End != nil:
+		req.EndTime = aws.Int64(opts.End.UTC().UnixMilli())
+	case opts.Until != nil:
+		req.EndTime = aws.Int64(TimeNow().UTC().Add((*opts.Until) * -1).UnixMilli())
+	}
+
+	switch {
+	case stream != "":
+		req.LogStreamNames = []*string{aws.String(stream)}
+	case opts.Service != nil && opts.Process != nil:
+		req.LogStreamNames = []*string{aws.String(fmt.Sprintf("service/%s/%s", *opts.Service, *opts.Process))}
+	case opts.Service != nil:
+		req.LogStreamNamePrefix = aws.String(fmt.Sprintf("service/%s/", *opts.Service))
+		req.Interleaved = aws.Bool(true)
+	default:
+		req.Interleaved = aws.Bool(true)
+	}
+
+	return req
+}
+
 func awscli(args ...string) ([]byte, error) {
 	return exec.Command("aws", args...).CombinedOutput()
 }
@@ -514,7 +644,7 @@ func setupCredentialsRole() (map[string]string, error) {
 	return env, nil
 }
 
-func writeLogEvents(w io.Writer, events []*cloudwatchlogs.FilteredLogEvent, opts structs.LogsOptions) (int64, error) {
+func writeLogEvents(w io.Writer, events []*cloudwatchlogs.FilteredLogEvent, opts structs.LogsOptions, filter *LogFilter) (int64, error) {
 	if len(events) == 0 {
 		return 0, nil
 	}
@@ -528,19 +658,11 @@ func writeLogEvents(w io.Writer, events []*cloudwatchlogs.FilteredLogEvent, opts
 			latest = *e.Timestamp
 		}
 
-		prefix := ""
-
-		if DefaultBool(opts.Prefix, false) {
-			sec := *e.Timestamp / 1000
-			nsec := (*e.Timestamp % 1000) * 1000
-			t := time.Unix(sec, nsec).UTC()
-
-			prefix = fmt.Sprintf("%s %s ", t.Format(time.RFC3339), *e.LogStreamName)
+		if !filter.Match(*e.LogStreamName, *e.Message) {
+			continue
 		}
 
-		line := fmt.Sprintf("%s%s\n", prefix, *e.Message)
-
-		if _, err := w.Write([]byte(line)); err != nil {
+		if _, err := w.Write([]byte(formatLogEvent(e, opts))); err != nil {
 			return 0, err
 		}
 	}
@@ -548,6 +670,20 @@ func writeLogEvents(w io.Writer, events []*cloudwatchlogs.FilteredLogEvent, opts
 	return latest, nil
 }
 
+func formatLogEvent(e *cloudwatchlogs.FilteredLogEvent, opts structs.LogsOptions) string {
+	prefix := ""
+
+	if DefaultBool(opts.Prefix, false) {
+		sec := *e.Timestamp / 1000
+		nsec := (*e.Timestamp % 1000) * 1000
+		t := time.Unix(sec, nsec).UTC()
+
+		prefix = fmt.Sprintf("%s %s ", t.Format(time.RFC3339), *e.LogStreamName)
+	}
+
+	return fmt.Sprintf("%s%s\n", prefix, *e.Message)
+}
+
 func NewSession() (*session.Session, error) {
 	config := &aws.Config{
 		MaxRetries: aws.Int(MAX_RETRY),
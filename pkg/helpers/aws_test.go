@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/convox/rack/pkg/helpers"
 	mockaws "github.com/convox/rack/pkg/mock/aws"
+	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -180,3 +182,110 @@ func (mwc *myWriteCloser) Close() error {
 	// Noop
 	return nil
 }
+
+func TestCloudWatchLogsSearch(t *testing.T) {
+	event := func(id string, ts int64, stream, message string) *cloudwatchlogs.FilteredLogEvent {
+		return &cloudwatchlogs.FilteredLogEvent{
+			EventId:       aws.String(id),
+			LogStreamName: aws.String(stream),
+			Message:       aws.String(message),
+			Timestamp:     aws.Int64(ts),
+		}
+	}
+
+	start := time.Date(2021, 1, 2, 3, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	cw := &mockaws.CloudWatchLogsAPI{}
+	cw.On("FilterLogEvents", &cloudwatchlogs.FilterLogEventsInput{
+		EndTime:      aws.Int64(end.UnixMilli()),
+		Interleaved:  aws.Bool(true),
+		LogGroupName: aws.String("grp"),
+		StartTime:    aws.Int64(start.UnixMilli()),
+	}).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			event("id1", 1000, "service/web/pid1", `{"level":"info","msg":"one"}`),
+			event("id2", 2000, "service/web/pid1", `{"level":"error","msg":"two"}`),
+		},
+		NextToken: aws.String("token1"),
+	}, nil)
+	cw.On("FilterLogEvents", &cloudwatchlogs.FilterLogEventsInput{
+		EndTime:      aws.Int64(end.UnixMilli()),
+		Interleaved:  aws.Bool(true),
+		LogGroupName: aws.String("grp"),
+		NextToken:    aws.String("token1"),
+		StartTime:    aws.Int64(start.UnixMilli()),
+	}).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			event("id3", 2000, "service/worker/pid2", `{"level":"error","msg":"three"}`),
+			event("id4", 3000, "service/web/pid1", `{"level":"error","msg":"four"}`),
+		},
+	}, nil)
+
+	opts := structs.LogsOptions{
+		End:    &end,
+		Fields: &[]string{"level=error"},
+		Limit:  options.Int(2),
+		Start:  &start,
+	}
+
+	buf := &bufferCloser{}
+
+	err := helpers.CloudWatchLogsSearch(context.Background(), cw, buf, "grp", "", opts)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, `{"level":"error","msg":"two"}`, lines[0])
+	require.Equal(t, `{"level":"error","msg":"three"}`, lines[1])
+	require.True(t, strings.HasPrefix(lines[2], structs.LogsCursorPrefix))
+
+	cursor := strings.TrimPrefix(lines[2], structs.LogsCursorPrefix)
+
+	cw = &mockaws.CloudWatchLogsAPI{}
+	cw.On("FilterLogEvents", &cloudwatchlogs.FilterLogEventsInput{
+		EndTime:      aws.Int64(end.UnixMilli()),
+		Interleaved:  aws.Bool(true),
+		LogGroupName: aws.String("grp"),
+		StartTime:    aws.Int64(2000),
+	}).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			event("id2", 2000, "service/web/pid1", `{"level":"error","msg":"two"}`),
+			event("id3", 2000, "service/worker/pid2", `{"level":"error","msg":"three"}`),
+			event("id4", 3000, "service/web/pid1", `{"level":"error","msg":"four"}`),
+		},
+	}, nil)
+
+	opts.Cursor = &cursor
+
+	buf.Reset()
+
+	err = helpers.CloudWatchLogsSearch(context.Background(), cw, buf, "grp", "", opts)
+	require.NoError(t, err)
+	require.Equal(t, "{\"level\":\"error\",\"msg\":\"four\"}\n", buf.String())
+}
+
+func TestCloudWatchLogsSearchInvalid(t *testing.T) {
+	cw := &mockaws.CloudWatchLogsAPI{}
+
+	_, err := helpers.CloudWatchLogsSubscribe(context.Background(), cw, "grp", "", structs.LogsOptions{Regex: options.String("[")})
+	require.EqualError(t, err, "invalid regex: error parsing regexp: missing closing ]: `[`")
+
+	err = helpers.CloudWatchLogsSearch(context.Background(), cw, &bufferCloser{}, "grp", "", structs.LogsOptions{Cursor: options.String("!!")})
+	require.EqualError(t, err, "invalid cursor")
+}
+
+func TestCloudWatchLogsSubscribeUntil(t *testing.T) {
+	cw := &mockaws.CloudWatchLogsAPI{}
+
+	_, err := helpers.CloudWatchLogsSubscribe(context.Background(), cw, "grp", "", structs.LogsOptions{Since: options.Duration(2 * time.Minute), Until: options.Duration(time.Hour)})
+	require.EqualError(t, err, "until 1h0m0s must be more recent than since 2m0s")
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (*bufferCloser) Close() error {
+	return nil
+}
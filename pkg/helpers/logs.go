@@ -0,0 +1,194 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/convox/rack/pkg/structs"
+)
+
+var reLogLine = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z) ([^ ]+) (.*)$`)
+
+// LogFilter applies the service, process, field and regex filters in LogsOptions to log lines
+type LogFilter struct {
+	fields []string
+	opts   structs.LogsOptions
+	regex  *regexp.Regexp
+}
+
+func NewLogFilter(opts structs.LogsOptions) (*LogFilter, error) {
+	f := &LogFilter{opts: opts}
+
+	if opts.Fields != nil {
+		f.fields = *opts.Fields
+	}
+
+	if opts.Regex != nil {
+		r, err := regexp.Compile(*opts.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %s", err)
+		}
+
+		f.regex = r
+	}
+
+	return f, nil
+}
+
+func (f *LogFilter) Match(stream, message string) bool {
+	if !LogStreamMatch(stream, f.opts) {
+		return false
+	}
+
+	if f.regex != nil && !f.regex.MatchString(message) {
+		return false
+	}
+
+	return LogFieldsMatch(message, f.fields)
+}
+
+// LogFieldsMatch returns true if message is a JSON object that satisfies every
+// key=value filter in fields. Nested keys can be addressed as parent.child
+func LogFieldsMatch(message string, fields []string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(strings.TrimSpace(message))))
+	dec.UseNumber()
+
+	var data map[string]interface{}
+
+	if err := dec.Decode(&data); err != nil {
+		return false
+	}
+
+	for _, f := range fields {
+		parts := strings.SplitN(f, "=", 2)
+
+		if len(parts) != 2 {
+			return false
+		}
+
+		v, ok := logField(data, parts[0])
+		if !ok || v != parts[1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LogStreamMatch returns true if a log stream named kind/service/process
+// belongs to the service and process selected in opts
+func LogStreamMatch(stream string, opts structs.LogsOptions) bool {
+	parts := strings.SplitN(stream, "/", 3)
+
+	if len(parts) != 3 {
+		return opts.Service == nil && opts.Process == nil
+	}
+
+	if opts.Service != nil && *opts.Service != parts[1] {
+		return false
+	}
+
+	if opts.Process != nil && *opts.Process != parts[2] {
+		return false
+	}
+
+	return true
+}
+
+// ParseLogLine parses a prefixed log line into its parts
+func ParseLogLine(app, line string) (*structs.LogEntry, error) {
+	match := reLogLine.FindStringSubmatch(line)
+
+	if len(match) != 4 {
+		return nil, fmt.Errorf("invalid log line")
+	}
+
+	ts, err := time.Parse(time.RFC3339, match[1])
+	if err != nil {
+		return nil, err
+	}
+
+	e := &structs.LogEntry{
+		App:       app,
+		Message:   match[3],
+		Timestamp: ts,
+	}
+
+	parts := strings.SplitN(match[2], "/", 3)
+
+	switch len(parts) {
+	case 3:
+		e.Service = parts[1]
+		e.Process = parts[2]
+	default:
+		e.Process = match[2]
+	}
+
+	return e, nil
+}
+
+func logField(data map[string]interface{}, key string) (string, bool) {
+	var v interface{} = data
+
+	for _, k := range strings.Split(key, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		if v, ok = m[k]; !ok {
+			return "", false
+		}
+	}
+
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case json.Number, bool:
+		return fmt.Sprintf("%v", t), true
+	case nil:
+		return "null", true
+	default:
+		return "", false
+	}
+}
+
+// logsCursor marks the position of the last event returned by a search, events
+// sharing its timestamp that were already returned are skipped on the next page
+type logsCursor struct {
+	Timestamp int64    `json:"t"`
+	Events    []string `json:"e"`
+}
+
+func decodeLogsCursor(cursor string) (*logsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var c logsCursor
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &c, nil
+}
+
+func (c logsCursor) encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,77 @@
+package helpers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFieldsMatch(t *testing.T) {
+	msg := `{"level":"error","code":500,"ok":false,"req":{"method":"GET"}}`
+
+	tests := []struct {
+		message string
+		fields  []string
+		match   bool
+	}{
+		{msg, nil, true},
+		{msg, []string{"level=error"}, true},
+		{msg, []string{"level=error", "code=500"}, true},
+		{msg, []string{"ok=false"}, true},
+		{msg, []string{"req.method=GET"}, true},
+		{msg, []string{"level=info"}, false},
+		{msg, []string{"level=error", "code=404"}, false},
+		{msg, []string{"missing=x"}, false},
+		{msg, []string{"level"}, false},
+		{"plain text", []string{"level=error"}, false},
+		{"plain text", nil, true},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.match, helpers.LogFieldsMatch(tt.message, tt.fields), "%s %v", tt.message, tt.fields)
+	}
+}
+
+func TestLogStreamMatch(t *testing.T) {
+	tests := []struct {
+		stream string
+		opts   structs.LogsOptions
+		match  bool
+	}{
+		{"service/web/pid1", structs.LogsOptions{}, true},
+		{"service/web/pid1", structs.LogsOptions{Service: options.String("web")}, true},
+		{"service/web/pid1", structs.LogsOptions{Service: options.String("worker")}, false},
+		{"service/web/pid1", structs.LogsOptions{Process: options.String("pid1")}, true},
+		{"service/web/pid1", structs.LogsOptions{Process: options.String("pid2")}, false},
+		{"strm", structs.LogsOptions{}, true},
+		{"strm", structs.LogsOptions{Service: options.String("web")}, false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.match, helpers.LogStreamMatch(tt.stream, tt.opts), tt.stream)
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	e, err := helpers.ParseLogLine("app1", "2021-01-02T03:04:05Z service/web/pid1 hello world")
+	require.NoError(t, err)
+	require.Equal(t, &structs.LogEntry{
+		App:       "app1",
+		Message:   "hello world",
+		Process:   "pid1",
+		Service:   "web",
+		Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, e)
+
+	e, err = helpers.ParseLogLine("app1", "2021-01-02T03:04:05Z agent hello")
+	require.NoError(t, err)
+	require.Equal(t, "agent", e.Process)
+	require.Equal(t, "", e.Service)
+
+	_, err = helpers.ParseLogLine("app1", "hello")
+	require.EqualError(t, err, "invalid log line")
+}
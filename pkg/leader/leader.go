@@ -0,0 +1,136 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultLease = 30 * time.Second
+)
+
+// Lock is a lease backend, a lease is held by one holder until it expires
+// or is released
+type Lock interface {
+	// Acquire takes the lease if it is free or expired, or extends it if
+	// holder already has it, and returns false if another holder has it
+	Acquire(name, holder string, lease time.Duration) (bool, error)
+
+	// Release gives up the lease if holder has it
+	Release(name, holder string) error
+}
+
+// Elector runs a function only while it holds a named lease
+type Elector struct {
+	Holder string
+	Lease  time.Duration
+	Lock   Lock
+	Name   string
+
+	leading bool
+	lock    sync.Mutex
+}
+
+// New returns an Elector for the named lease with a holder unique to this
+// process
+func New(lock Lock, name string) *Elector {
+	return &Elector{
+		Holder: Holder(),
+		Lease:  DefaultLease,
+		Lock:   lock,
+		Name:   name,
+	}
+}
+
+// Holder returns an identifier for this process
+func Holder() string {
+	host, _ := os.Hostname()
+
+	return fmt.Sprintf("%s-%d-%06d", host, os.Getpid(), rand.Intn(1000000))
+}
+
+// Leading returns true while the lease is held
+func (e *Elector) Leading() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.leading
+}
+
+// Run tries to take the lease every third of the lease duration until ctx is
+// done. While it holds the lease fn runs with a context that is cancelled
+// when the lease can not be renewed. The lease is released when ctx is done.
+func (e *Elector) Run(ctx context.Context, fn func(ctx context.Context)) {
+	interval := e.Lease / 3
+
+	var current *term
+
+	stop := func() {
+		if current == nil {
+			return
+		}
+
+		e.setLeading(false)
+
+		current.cancel()
+		<-current.done
+
+		current = nil
+	}
+
+	defer func() {
+		stop()
+		e.Lock.Release(e.Name, e.Holder)
+	}()
+
+	for {
+		ok, err := e.Lock.Acquire(e.Name, e.Holder, e.Lease)
+
+		switch {
+		case err != nil || !ok:
+			// an error renewing could mean another holder takes over
+			// once the lease expires so stop leading either way
+			stop()
+		case current == nil:
+			e.setLeading(true)
+
+			current = start(ctx, fn)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// term is a period of leadership
+type term struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func start(ctx context.Context, fn func(ctx context.Context)) *term {
+	ctx, cancel := context.WithCancel(ctx)
+
+	t := &term{cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(t.done)
+		fn(ctx)
+	}()
+
+	return t
+}
+
+func (e *Elector) setLeading(leading bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.leading = leading
+}
@@ -0,0 +1,171 @@
+package leader_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/leader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLock(t *testing.T) {
+	l := leader.NewMemoryLock()
+
+	ok, err := l.Acquire("workers", "h1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = l.Acquire("workers", "h2", time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = l.Acquire("workers", "h1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, l.Release("workers", "h2"))
+	require.Equal(t, "h1", l.Holder("workers"))
+
+	require.NoError(t, l.Release("workers", "h1"))
+	require.Equal(t, "", l.Holder("workers"))
+
+	ok, err = l.Acquire("workers", "h2", time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	time.Sleep(5 * time.Millisecond)
+
+	ok, err = l.Acquire("workers", "h1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func testElector(l leader.Lock, holder string) *leader.Elector {
+	e := leader.New(l, "workers")
+	e.Holder = holder
+	e.Lease = 30 * time.Millisecond
+	return e
+}
+
+func TestElectorSingleLeader(t *testing.T) {
+	l := leader.NewMemoryLock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var lock sync.Mutex
+	running := 0
+	max := 0
+
+	fn := func(ctx context.Context) {
+		lock.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		lock.Unlock()
+
+		<-ctx.Done()
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+	}
+
+	var wg sync.WaitGroup
+
+	es := []*leader.Elector{}
+
+	for i := 0; i < 3; i++ {
+		e := testElector(l, fmt.Sprintf("h%d", i))
+		es = append(es, e)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			e.Run(ctx, fn)
+		}()
+	}
+
+	require.Eventually(t, func() bool { return l.Holder("workers") != "" }, time.Second, time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	leading := 0
+
+	for _, e := range es {
+		if e.Leading() {
+			leading++
+		}
+	}
+
+	require.Equal(t, 1, leading)
+
+	cancel()
+	wg.Wait()
+
+	require.Equal(t, 1, max)
+	require.Equal(t, 0, running)
+	require.Equal(t, "", l.Holder("workers"))
+}
+
+// failLock fails to acquire once failing is set
+type failLock struct {
+	*leader.MemoryLock
+
+	failing bool
+	lock    sync.Mutex
+}
+
+func (f *failLock) Acquire(name, holder string, d time.Duration) (bool, error) {
+	f.lock.Lock()
+	failing := f.failing
+	f.lock.Unlock()
+
+	if failing {
+		return false, fmt.Errorf("err1")
+	}
+
+	return f.MemoryLock.Acquire(name, holder, d)
+}
+
+func TestElectorFailover(t *testing.T) {
+	fl := &failLock{MemoryLock: leader.NewMemoryLock()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopped := make(chan struct{})
+
+	e1 := testElector(fl, "h1")
+
+	go e1.Run(ctx, func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	require.Eventually(t, e1.Leading, time.Second, time.Millisecond)
+
+	// h1 can no longer renew so it stops and h2 takes over once the lease expires
+	fl.lock.Lock()
+	fl.failing = true
+	fl.lock.Unlock()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("leader did not stop")
+	}
+
+	require.False(t, e1.Leading())
+
+	e2 := testElector(fl.MemoryLock, "h2")
+
+	go e2.Run(ctx, func(ctx context.Context) { <-ctx.Done() })
+
+	require.Eventually(t, e2.Leading, time.Second, time.Millisecond)
+	require.Equal(t, "h2", fl.Holder("workers"))
+}
@@ -0,0 +1,59 @@
+package leader
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryLock is a Lock for electors in a single process
+type MemoryLock struct {
+	leases map[string]lease
+	lock   sync.Mutex
+}
+
+type lease struct {
+	expires time.Time
+	holder  string
+}
+
+func NewMemoryLock() *MemoryLock {
+	return &MemoryLock{leases: map[string]lease{}}
+}
+
+func (m *MemoryLock) Acquire(name, holder string, d time.Duration) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+
+	if l, ok := m.leases[name]; ok && l.holder != holder && l.expires.After(now) {
+		return false, nil
+	}
+
+	m.leases[name] = lease{expires: now.Add(d), holder: holder}
+
+	return true, nil
+}
+
+func (m *MemoryLock) Release(name, holder string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if l, ok := m.leases[name]; ok && l.holder == holder {
+		delete(m.leases, name)
+	}
+
+	return nil
+}
+
+// Holder returns the current holder of a lease, empty if it is free
+func (m *MemoryLock) Holder(name string) string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if l, ok := m.leases[name]; ok && l.expires.After(time.Now()) {
+		return l.holder
+	}
+
+	return ""
+}
@@ -0,0 +1,290 @@
+package logstorage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// disk persists each stream as a directory of append-only segment files,
+// segments are named for the nanosecond timestamp of their first log
+type disk struct {
+	path         string
+	segmentBytes int64
+	segments     map[string][]*segment
+}
+
+type segment struct {
+	file *os.File
+	path string
+	last time.Time
+	size int64
+}
+
+func openDisk(path string, segmentBytes int64) (*disk, error) {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+
+	if segmentBytes <= 0 {
+		segmentBytes = DefaultOptions.SegmentBytes
+	}
+
+	d := &disk{
+		path:         path,
+		segmentBytes: segmentBytes,
+		segments:     map[string][]*segment{},
+	}
+
+	return d, nil
+}
+
+func (d *disk) append(stream string, l Log) error {
+	seg, err := d.active(stream, l.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	n, err := seg.file.Write(append(data, '\n'))
+	if err != nil {
+		return err
+	}
+
+	seg.size += int64(n)
+	seg.track(l.Timestamp)
+
+	return nil
+}
+
+func (d *disk) close() error {
+	var err error
+
+	for _, segs := range d.segments {
+		for _, seg := range segs {
+			if cerr := seg.close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	}
+
+	return err
+}
+
+// expire removes the segments of a stream that only hold logs before oldest
+func (d *disk) expire(stream string, oldest time.Time) error {
+	keep := []*segment{}
+
+	for _, seg := range d.segments[stream] {
+		if !seg.last.Before(oldest) {
+			keep = append(keep, seg)
+			continue
+		}
+
+		seg.close()
+
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	d.segments[stream] = keep
+
+	return nil
+}
+
+func (d *disk) load() (map[string][]Log, error) {
+	streams := map[string][]Log{}
+
+	dirs, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+
+		stream, err := url.PathUnescape(dir.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		files, err := filepath.Glob(filepath.Join(d.path, dir.Name(), "*.log"))
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(files)
+
+		ls := []Log{}
+
+		for _, file := range files {
+			seg, sls, err := readSegment(file)
+			if err != nil {
+				return nil, err
+			}
+
+			d.segments[stream] = append(d.segments[stream], seg)
+
+			ls = append(ls, sls...)
+		}
+
+		sort.SliceStable(ls, func(i, j int) bool { return ls[i].Timestamp.Before(ls[j].Timestamp) })
+
+		if len(ls) > 0 {
+			streams[stream] = ls
+		}
+	}
+
+	return streams, nil
+}
+
+func (d *disk) remove(stream string) error {
+	for _, seg := range d.segments[stream] {
+		seg.close()
+	}
+
+	delete(d.segments, stream)
+
+	return os.RemoveAll(d.streamPath(stream))
+}
+
+// active returns the segment that the next log for stream should be written
+// to, rotating to a new segment once the current one is full
+func (d *disk) active(stream string, ts time.Time) (*segment, error) {
+	segs := d.segments[stream]
+
+	if n := len(segs); n > 0 && segs[n-1].size < d.segmentBytes {
+		seg := segs[n-1]
+
+		if seg.file == nil {
+			fd, err := os.OpenFile(seg.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+			if err != nil {
+				return nil, err
+			}
+
+			seg.file = fd
+		}
+
+		return seg, nil
+	}
+
+	if n := len(segs); n > 0 {
+		if err := segs[n-1].close(); err != nil {
+			return nil, err
+		}
+	}
+
+	dir := d.streamPath(stream)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	// segment names must sort in creation order even if logs arrive out of order
+	name := ts.UnixNano()
+
+	if n := len(segs); n > 0 {
+		if last := segmentName(segs[n-1].path); name <= last {
+			name = last + 1
+		}
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%019d.log", name))
+
+	fd, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	seg := &segment{file: fd, path: path, last: ts}
+
+	d.segments[stream] = append(segs, seg)
+
+	return seg, nil
+}
+
+func (d *disk) streamPath(stream string) string {
+	return filepath.Join(d.path, url.PathEscape(stream))
+}
+
+func (s *segment) close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+func (s *segment) track(ts time.Time) {
+	if ts.After(s.last) {
+		s.last = ts
+	}
+}
+
+func readSegment(path string) (*segment, []Log, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer fd.Close()
+
+	seg := &segment{path: path}
+	ls := []Log{}
+
+	r := bufio.NewReader(fd)
+
+	for {
+		line, err := r.ReadBytes('\n')
+		seg.size += int64(len(line))
+
+		// a partial trailing line is left behind if the process died mid-write
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var l Log
+
+			if jerr := json.Unmarshal(line, &l); jerr == nil {
+				ls = append(ls, l)
+				seg.track(l.Timestamp)
+			}
+		}
+
+		if err == io.EOF {
+			// never append after a partial line, the next write starts a new segment
+			if len(line) > 0 {
+				seg.size = math.MaxInt64
+			}
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return seg, ls, nil
+}
+
+func segmentName(path string) int64 {
+	var n int64
+
+	fmt.Sscanf(strings.TrimSuffix(filepath.Base(path), ".log"), "%d", &n)
+
+	return n
+}
@@ -11,6 +11,10 @@ import (
 
 type Store struct {
 	lock          sync.Mutex
+	disk          *disk
+	done          chan struct{}
+	opts          Options
+	sizes         map[string]int
 	streams       map[string][]Log
 	subscriptions subscriptions
 }
@@ -21,26 +25,86 @@ type Log struct {
 	Timestamp time.Time
 }
 
+// Options control how much of each stream is retained
+type Options struct {
+	MaxAge       time.Duration // drop logs older than this, zero to disable
+	MaxBytes     int           // per-stream limit on prefix and message bytes, zero to disable
+	MaxLogs      int           // per-stream limit on number of logs, zero to disable
+	Path         string        // directory for on-disk segments, memory only if empty
+	SegmentBytes int64         // rotate on-disk segments at this size
+}
+
 type Receiver chan Log
 
+var DefaultOptions = Options{
+	MaxAge:       24 * time.Hour,
+	MaxBytes:     10 * 1024 * 1024,
+	MaxLogs:      10000,
+	SegmentBytes: 1024 * 1024,
+}
+
 func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
 }
 
-func New() Store {
-	s := Store{streams: map[string][]Log{}}
+// New returns an in-memory Store with the default retention
+func New() *Store {
+	s, _ := NewWithOptions(DefaultOptions)
+	return s
+}
+
+// NewWithOptions returns a Store with the given retention, replaying any
+// existing segments when opts.Path is set
+func NewWithOptions(opts Options) (*Store, error) {
+	s := &Store{
+		done:    make(chan struct{}),
+		opts:    opts,
+		sizes:   map[string]int{},
+		streams: map[string][]Log{},
+	}
+
+	if opts.Path != "" {
+		d, err := openDisk(opts.Path, opts.SegmentBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		streams, err := d.load()
+		if err != nil {
+			d.close()
+			return nil, err
+		}
+
+		s.disk = d
+
+		for name, ls := range streams {
+			s.streams[name] = ls
+
+			for _, l := range ls {
+				s.sizes[name] += l.size()
+			}
+
+			s.trim(name, s.cutoff())
+		}
+	}
 
 	go s.startCleaner()
 
-	return s
+	return s, nil
 }
 
-func (s *Store) Append(stream string, ts time.Time, prefix, message string) {
+func (s *Store) Append(stream string, ts time.Time, prefix, message string) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	log := Log{Message: message, Prefix: prefix, Timestamp: ts}
 
+	if s.disk != nil {
+		if err := s.disk.append(stream, log); err != nil {
+			return err
+		}
+	}
+
 	ls, ok := s.streams[stream]
 	if !ok {
 		ls = []Log{}
@@ -53,8 +117,44 @@ func (s *Store) Append(stream string, ts time.Time, prefix, message string) {
 	ls[n] = log
 
 	s.streams[stream] = ls
+	s.sizes[stream] += log.size()
+
+	s.trim(stream, time.Time{})
 
 	s.subscriptions.send(stream, log)
+
+	return nil
+}
+
+// Close stops the cleaner and releases any open segments
+func (s *Store) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	default:
+		close(s.done)
+	}
+
+	if s.disk != nil {
+		return s.disk.close()
+	}
+
+	return nil
+}
+
+// Prune applies the age limit to every stream, it is called periodically by the cleaner
+func (s *Store) Prune() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cutoff := s.cutoff()
+
+	for name := range s.streams {
+		s.trim(name, cutoff)
+	}
 }
 
 func (s *Store) Subscribe(ctx context.Context, ch Receiver, stream string, start time.Time, follow bool) {
@@ -63,11 +163,15 @@ func (s *Store) Subscribe(ctx context.Context, ch Receiver, stream string, start
 
 	if ls, ok := s.streams[stream]; ok {
 		n := sort.Search(len(ls), func(i int) bool { return !ls[i].Timestamp.Before(start) })
-		go sendMultiple(ch, ls[n:], func() {
+		history := make([]Log, len(ls)-n)
+		copy(history, ls[n:])
+		go sendMultiple(ch, history, func() {
 			if !follow {
 				close(ch)
 			}
 		})
+	} else if !follow {
+		close(ch)
 	}
 
 	if follow {
@@ -75,23 +179,87 @@ func (s *Store) Subscribe(ctx context.Context, ch Receiver, stream string, start
 	}
 }
 
-func (s *Store) cleanupLogs() {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-
-	for name := range s.streams {
-		ls := s.streams[name]
-		n := sort.Search(len(ls), func(i int) bool { return ls[i].Timestamp.After(time.Now().Add(30 * time.Second)) })
-		s.streams[name] = ls[n:]
+func (s *Store) cutoff() time.Time {
+	if s.opts.MaxAge == 0 {
+		return time.Time{}
 	}
+
+	return time.Now().Add(-1 * s.opts.MaxAge)
 }
 
 func (s *Store) startCleaner() {
-	for range time.Tick(30 * time.Second) {
-		s.cleanupLogs()
+	tick := time.NewTicker(30 * time.Second)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-tick.C:
+			s.Prune()
+		}
 	}
 }
 
+// trim drops the oldest logs in a stream until it is within the limits,
+// logs before cutoff are dropped unless cutoff is zero
+func (s *Store) trim(stream string, cutoff time.Time) {
+	ls := s.streams[stream]
+	size := s.sizes[stream]
+
+	n := 0
+
+	if !cutoff.IsZero() {
+		n = sort.Search(len(ls), func(i int) bool { return !ls[i].Timestamp.Before(cutoff) })
+	}
+
+	if s.opts.MaxLogs > 0 && len(ls)-n > s.opts.MaxLogs {
+		n = len(ls) - s.opts.MaxLogs
+	}
+
+	for i := 0; i < n; i++ {
+		size -= ls[i].size()
+	}
+
+	for s.opts.MaxBytes > 0 && size > s.opts.MaxBytes && n < len(ls) {
+		size -= ls[n].size()
+		n++
+	}
+
+	if n == 0 {
+		return
+	}
+
+	if n == len(ls) {
+		delete(s.streams, stream)
+		delete(s.sizes, stream)
+
+		if s.disk != nil {
+			s.disk.remove(stream)
+		}
+
+		return
+	}
+
+	rest := ls[n:]
+
+	// release the dropped logs once the backing array is mostly unused
+	if cap(rest) > 2*len(rest) {
+		rest = append([]Log{}, rest...)
+	}
+
+	s.streams[stream] = rest
+	s.sizes[stream] = size
+
+	if s.disk != nil {
+		s.disk.expire(stream, rest[0].Timestamp)
+	}
+}
+
+func (l Log) size() int {
+	return len(l.Prefix) + len(l.Message)
+}
+
 type subscriptions struct {
 	lock          sync.Mutex
 	subscriptions map[string]map[string]*subscription
@@ -127,6 +295,13 @@ func (s *subscriptions) add(ctx context.Context, ch Receiver, stream string, sta
 	go s.watch(ctx, stream, handle)
 }
 
+func (s *subscriptions) get(stream, handle string) *subscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.subscriptions[stream][handle]
+}
+
 func (s *subscriptions) remove(stream, handle string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -164,10 +339,8 @@ func (s *subscriptions) watch(ctx context.Context, stream, handle string) {
 		case <-ctx.Done():
 			return
 		case <-tick.C:
-			if ss, ok := s.subscriptions[stream]; ok {
-				if sub, ok := ss[handle]; ok && len(sub.queue) > 0 {
-					sub.flush()
-				}
+			if sub := s.get(stream, handle); sub != nil {
+				sub.flush()
 			}
 		}
 	}
@@ -2,6 +2,8 @@ package logstorage_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -86,3 +88,131 @@ func TestFollow(t *testing.T) {
 	require.Equal(t, "p1", log.Prefix)
 	require.Equal(t, "one", log.Message)
 }
+
+func TestMaxLogs(t *testing.T) {
+	s, err := logstorage.NewWithOptions(logstorage.Options{MaxLogs: 2})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Append("foo", time1, "p1", "one"))
+	require.NoError(t, s.Append("foo", time2, "p2", "two"))
+	require.NoError(t, s.Append("foo", time3, "p3", "three"))
+
+	require.Equal(t, []string{"two", "three"}, messages(t, s, "foo", time1))
+}
+
+func TestMaxBytes(t *testing.T) {
+	s, err := logstorage.NewWithOptions(logstorage.Options{MaxBytes: 10})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Append("foo", time1, "p1", "one"))
+	require.NoError(t, s.Append("foo", time2, "p2", "two"))
+	require.NoError(t, s.Append("foo", time3, "p3", "three"))
+
+	require.Equal(t, []string{"three"}, messages(t, s, "foo", time1))
+}
+
+func TestMaxAge(t *testing.T) {
+	s, err := logstorage.NewWithOptions(logstorage.Options{MaxAge: time.Hour})
+	require.NoError(t, err)
+	defer s.Close()
+
+	now := time.Now().UTC()
+
+	require.NoError(t, s.Append("foo", now.Add(-2*time.Hour), "p1", "old"))
+	require.NoError(t, s.Append("foo", now, "p2", "new"))
+	require.NoError(t, s.Append("bar", now.Add(-3*time.Hour), "p3", "expired"))
+
+	s.Prune()
+
+	require.Equal(t, []string{"new"}, messages(t, s, "foo", time1))
+	require.Equal(t, []string{}, messages(t, s, "bar", time1))
+}
+
+func TestDiskReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := logstorage.NewWithOptions(logstorage.Options{Path: dir})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Append("app/foo", time2, "p2", "two"))
+	require.NoError(t, s.Append("app/foo", time1, "p1", "one"))
+	require.NoError(t, s.Append("bar", time3, "p3", "three"))
+	require.NoError(t, s.Close())
+
+	s, err = logstorage.NewWithOptions(logstorage.Options{Path: dir})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.Equal(t, []string{"one", "two"}, messages(t, s, "app/foo", time1))
+	require.Equal(t, []string{"three"}, messages(t, s, "bar", time1))
+
+	require.NoError(t, s.Append("bar", time3.Add(time.Second), "p4", "four"))
+	require.Equal(t, []string{"three", "four"}, messages(t, s, "bar", time1))
+}
+
+func TestDiskRetention(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := logstorage.NewWithOptions(logstorage.Options{MaxLogs: 2, Path: dir, SegmentBytes: 1})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Append("foo", time1, "p1", "one"))
+	require.NoError(t, s.Append("foo", time2, "p2", "two"))
+	require.NoError(t, s.Append("foo", time3, "p3", "three"))
+	require.NoError(t, s.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "foo", "*.log"))
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+
+	s, err = logstorage.NewWithOptions(logstorage.Options{Path: dir})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.Equal(t, []string{"two", "three"}, messages(t, s, "foo", time1))
+}
+
+func TestDiskPartialSegment(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := logstorage.NewWithOptions(logstorage.Options{Path: dir})
+	require.NoError(t, err)
+	require.NoError(t, s.Append("foo", time1, "p1", "one"))
+	require.NoError(t, s.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "foo", "*.log"))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+
+	fd, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte(`{"Prefix":"p2","Mess`))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	s, err = logstorage.NewWithOptions(logstorage.Options{Path: dir})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Append("foo", time3, "p3", "three"))
+	require.Equal(t, []string{"one", "three"}, messages(t, s, "foo", time1))
+}
+
+func messages(t *testing.T, s *logstorage.Store, stream string, start time.Time) []string {
+	ch := make(chan logstorage.Log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.Subscribe(ctx, ch, stream, start, false)
+
+	ms := []string{}
+
+	for l := range ch {
+		ms = append(ms, l.Message)
+	}
+
+	return ms
+}
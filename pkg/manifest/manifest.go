@@ -14,11 +14,16 @@ import (
 )
 
 const (
-	ValidNameDescription = "must contain only lowercase alphanumeric and dashes"
+	ValidNameDescription   = "must contain only lowercase alphanumeric and dashes"
+	ValidSecretDescription = "must contain only alphanumeric, dashes, dots and underscores"
 )
 
 var (
-	nameValidator = regexp.MustCompile(`^[a-z]{1}[a-z0-9-]*$`)
+	nameValidator   = regexp.MustCompile(`^[a-z]{1}[a-z0-9-]*$`)
+	secretValidator = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+	buildArgValidator = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	platformValidator = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
 
 	// nlbCertARNPattern accepts ACM certificate ARNs and IAM server-certificate
 	// ARNs across all AWS partitions (aws, aws-cn, aws-us-gov).
@@ -222,6 +227,51 @@ func (m *Manifest) Validate() error {
 			return fmt.Errorf("service name %s invalid, %s", s.Name, ValidNameDescription)
 		}
 
+		for _, a := range s.Build.Args {
+			if name := strings.SplitN(a, "=", 2)[0]; !buildArgValidator.MatchString(name) {
+				return fmt.Errorf("service %s: build arg %q invalid", s.Name, a)
+			}
+		}
+
+		if p := s.Build.Platform; p != "" && !platformValidator.MatchString(p) {
+			return fmt.Errorf("service %s: build platform %q invalid, must be os/arch or os/arch/variant", s.Name, p)
+		}
+
+		if s.Build.Platform != "" && len(s.Build.Platforms) > 0 {
+			return fmt.Errorf("service %s: build platform and platforms can not both be set", s.Name)
+		}
+
+		platforms := map[string]bool{}
+		for _, p := range s.Build.Platforms {
+			if !platformValidator.MatchString(p) {
+				return fmt.Errorf("service %s: build platform %q invalid, must be os/arch or os/arch/variant", s.Name, p)
+			}
+			if platforms[p] {
+				return fmt.Errorf("service %s: duplicate build platform %s", s.Name, p)
+			}
+			platforms[p] = true
+		}
+
+		if s.Build.Buildpack != "" {
+			if err := s.Build.ValidateBuildpack(); err != nil {
+				return fmt.Errorf("service %s: %w", s.Name, err)
+			}
+		}
+
+		secrets := map[string]bool{}
+		for _, bs := range s.Build.Secrets {
+			if bs.Env == "" {
+				return fmt.Errorf("service %s: build secret %s requires an env var", s.Name, bs.Id)
+			}
+			if !secretValidator.MatchString(bs.Id) {
+				return fmt.Errorf("service %s: build secret id %q invalid, %s", s.Name, bs.Id, ValidSecretDescription)
+			}
+			if secrets[bs.Id] {
+				return fmt.Errorf("service %s: duplicate build secret %s", s.Name, bs.Id)
+			}
+			secrets[bs.Id] = true
+		}
+
 		if len(s.NLB) > 0 && s.Agent.Enabled {
 			return fmt.Errorf("service %s: agent mode is incompatible with nlb ports", s.Name)
 		}
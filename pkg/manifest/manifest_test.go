@@ -1,6 +1,8 @@
 package manifest_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/convox/rack/pkg/helpers"
@@ -481,6 +483,92 @@ func TestManifestServiceNameValidation(t *testing.T) {
 	require.EqualError(t, err, "service name web_with_underscore invalid, must contain only lowercase alphanumeric and dashes")
 }
 
+func TestManifestBuildSecrets(t *testing.T) {
+	m, err := testdataManifest("build-secrets", map[string]string{})
+	require.NoError(t, err)
+	require.Equal(t, []manifest.ServiceBuildSecret{
+		{Env: "NPM_TOKEN", Id: "NPM_TOKEN"},
+		{Env: "GITHUB_TOKEN", Id: "github"},
+	}, m.Services[0].Build.Secrets)
+
+	m, err = testdataManifest("invalid.5", map[string]string{})
+	require.Nil(t, m)
+	require.EqualError(t, err, "service web: duplicate build secret NPM_TOKEN")
+}
+
+func TestManifestBuildTargetPlatform(t *testing.T) {
+	m, err := testdataManifest("build-target", map[string]string{})
+	require.NoError(t, err)
+
+	web, err := m.Service("web")
+	require.NoError(t, err)
+	require.Equal(t, "web", web.Build.Target)
+	require.Equal(t, "linux/arm64", web.Build.Platform)
+	require.Equal(t, map[string]string{"VERSION": "1.2.3"}, web.Build.BuildArgs())
+
+	worker, err := m.Service("worker")
+	require.NoError(t, err)
+
+	// services sharing a Dockerfile build separately for each target
+	require.NotEqual(t, web.BuildHash("build1"), worker.BuildHash("build1"))
+
+	m, err = testdataManifest("invalid.6", map[string]string{})
+	require.Nil(t, m)
+	require.EqualError(t, err, `service web: build platform "arm64" invalid, must be os/arch or os/arch/variant`)
+}
+
+func TestManifestBuildPlatforms(t *testing.T) {
+	m, err := testdataManifest("build-platforms", map[string]string{})
+	require.NoError(t, err)
+
+	web, err := m.Service("web")
+	require.NoError(t, err)
+	require.Equal(t, []string{"linux/amd64", "linux/arm64"}, web.Build.Platforms)
+
+	worker, err := m.Service("worker")
+	require.NoError(t, err)
+	require.NotEqual(t, web.BuildHash("build1"), worker.BuildHash("build1"))
+
+	m, err = testdataManifest("invalid.7", map[string]string{})
+	require.Nil(t, m)
+	require.EqualError(t, err, "service web: build platform and platforms can not both be set")
+
+	m, err = testdataManifest("invalid.8", map[string]string{})
+	require.Nil(t, m)
+	require.EqualError(t, err, "service web: duplicate build platform linux/arm64")
+}
+
+func TestManifestBuildpack(t *testing.T) {
+	m, err := testdataManifest("build-buildpack", map[string]string{})
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "api"), 0755))
+
+	web, err := m.Service("web")
+	require.NoError(t, err)
+	require.Equal(t, "paketobuildpacks/builder-jammy-full", web.Build.Builder(root))
+
+	api, err := m.Service("api")
+	require.NoError(t, err)
+	require.Equal(t, manifest.DefaultBuilder, api.Build.Builder(root))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "api", "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	require.Equal(t, "", api.Build.Builder(root))
+
+	// a missing manifest set on the service is an error of the build
+	worker, err := m.Service("worker")
+	require.NoError(t, err)
+	require.Equal(t, "", worker.Build.Builder(root))
+
+	require.NotEqual(t, web.BuildHash("build1"), worker.BuildHash("build1"))
+
+	m, err = testdataManifest("invalid.9", map[string]string{})
+	require.Nil(t, m)
+	require.EqualError(t, err, "service web: buildpack builds do not support a build target")
+}
+
 func testdataManifest(name string, env map[string]string) (*manifest.Manifest, error) {
 	data, err := helpers.Testdata(name)
 	if err != nil {
@@ -3,6 +3,8 @@ package manifest
 import (
 	"crypto/sha1"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -61,9 +63,78 @@ type ServiceNLBPort struct {
 }
 
 type ServiceBuild struct {
-	Args     []string `yaml:"args,omitempty"`
-	Manifest string   `yaml:"manifest,omitempty"`
-	Path     string   `yaml:"path,omitempty"`
+	Args      []string             `yaml:"args,omitempty"`
+	Buildpack string               `yaml:"buildpack,omitempty"`
+	Manifest  string               `yaml:"manifest,omitempty"`
+	Path      string               `yaml:"path,omitempty"`
+	Platform  string               `yaml:"platform,omitempty"`
+	Platforms []string             `yaml:"platforms,omitempty"`
+	Secrets   []ServiceBuildSecret `yaml:"secrets,omitempty"`
+	Target    string               `yaml:"target,omitempty"`
+}
+
+// ServiceBuildSecret exposes the app env var Env to the build as the secret Id
+// without writing it into the image
+type ServiceBuildSecret struct {
+	Env string `yaml:"env,omitempty"`
+	Id  string `yaml:"id,omitempty"`
+}
+
+// DefaultBuilder is the buildpack builder a service is built with when its
+// build path has no Dockerfile
+const DefaultBuilder = "paketobuildpacks/builder-jammy-base"
+
+// BuildpackWorkspace is where a buildpack build puts the source of a service
+// in its image
+const BuildpackWorkspace = "/workspace"
+
+// Builder returns the buildpack builder image a service is built with from the
+// source under root, or an empty string for a Dockerfile build. A service
+// without a Dockerfile at its build path and no other manifest set falls back
+// to the default builder.
+func (b ServiceBuild) Builder(root string) string {
+	if b.Buildpack != "" {
+		return b.Buildpack
+	}
+
+	if b.Path == "" || b.Manifest != "Dockerfile" {
+		return ""
+	}
+
+	if _, err := os.Stat(filepath.Join(root, b.Path, b.Manifest)); os.IsNotExist(err) {
+		return DefaultBuilder
+	}
+
+	return ""
+}
+
+// ValidateBuildpack returns an error if a build that uses buildpacks sets an
+// option only a Dockerfile build supports
+func (b ServiceBuild) ValidateBuildpack() error {
+	switch {
+	case b.Target != "":
+		return fmt.Errorf("buildpack builds do not support a build target")
+	case len(b.Secrets) > 0:
+		return fmt.Errorf("buildpack builds do not support build secrets")
+	case b.Platform != "" || len(b.Platforms) > 0:
+		return fmt.Errorf("buildpack builds do not support build platforms")
+	}
+
+	return nil
+}
+
+// BuildArgs returns the build args set on a service with a literal value,
+// args given only as a name are taken from the app environment instead
+func (b ServiceBuild) BuildArgs() map[string]string {
+	args := map[string]string{}
+
+	for _, a := range b.Args {
+		if parts := strings.SplitN(a, "=", 2); len(parts) == 2 {
+			args[parts[0]] = parts[1]
+		}
+	}
+
+	return args
 }
 
 type ServiceCommand []string
@@ -127,7 +198,29 @@ type ServiceTermination struct {
 }
 
 func (s Service) BuildHash(key string) string {
-	return fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("key=%q build[path=%q, manifest=%q, args=%v] image=%q", key, s.Build.Path, s.Build.Manifest, s.Build.Args, s.Image))))
+	build := fmt.Sprintf("path=%q, manifest=%q, args=%v", s.Build.Path, s.Build.Manifest, s.Build.Args)
+
+	if len(s.Build.Secrets) > 0 {
+		build += fmt.Sprintf(", secrets=%v", s.Build.Secrets)
+	}
+
+	if s.Build.Target != "" {
+		build += fmt.Sprintf(", target=%q", s.Build.Target)
+	}
+
+	if s.Build.Platform != "" {
+		build += fmt.Sprintf(", platform=%q", s.Build.Platform)
+	}
+
+	if len(s.Build.Platforms) > 0 {
+		build += fmt.Sprintf(", platforms=%v", s.Build.Platforms)
+	}
+
+	if s.Build.Buildpack != "" {
+		build += fmt.Sprintf(", buildpack=%q", s.Build.Buildpack)
+	}
+
+	return fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("key=%q build[%s] image=%q", key, build, s.Image))))
 }
 
 func (s Service) Domain() string {
@@ -147,8 +147,13 @@ func (v *ServiceBuild) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			return err
 		}
 		v.Args = r.Args
+		v.Buildpack = r.Buildpack
 		v.Manifest = r.Manifest
 		v.Path = r.Path
+		v.Platform = r.Platform
+		v.Platforms = r.Platforms
+		v.Secrets = r.Secrets
+		v.Target = r.Target
 	case string:
 		v.Path = t
 	default:
@@ -159,13 +164,39 @@ func (v *ServiceBuild) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 func (v ServiceBuild) MarshalYAML() (interface{}, error) {
-	if len(v.Args) == 0 {
+	if len(v.Args) == 0 && v.Buildpack == "" && len(v.Secrets) == 0 && v.Platform == "" && len(v.Platforms) == 0 && v.Target == "" {
 		return v.Path, nil
 	}
 
 	return v, nil
 }
 
+func (v *ServiceBuildSecret) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w interface{}
+
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+
+	switch t := w.(type) {
+	case map[interface{}]interface{}:
+		type serviceBuildSecret ServiceBuildSecret
+		var r serviceBuildSecret
+		if err := remarshal(w, &r); err != nil {
+			return err
+		}
+		v.Env = r.Env
+		v.Id = r.Id
+	case string:
+		v.Env = t
+		v.Id = t
+	default:
+		return fmt.Errorf("unknown type for build secret: %T", t)
+	}
+
+	return nil
+}
+
 func (v *ServiceCommand) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var w interface{}
 
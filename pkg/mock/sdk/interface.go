@@ -212,6 +212,20 @@ func (_m *Interface) AppUpdate(name string, opts structs.AppUpdateOptions) error
 	return r0
 }
 
+// BuildCancel provides a mock function with given fields: app, id
+func (_m *Interface) BuildCancel(app string, id string) error {
+	ret := _m.Called(app, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(app, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BuildCreate provides a mock function with given fields: app, url, opts
 func (_m *Interface) BuildCreate(app string, url string, opts structs.BuildCreateOptions) (*structs.Build, error) {
 	ret := _m.Called(app, url, opts)
@@ -258,13 +272,13 @@ func (_m *Interface) BuildCreateUpload(_a0 string, _a1 io.Reader, _a2 structs.Bu
 	return r0, r1
 }
 
-// BuildExport provides a mock function with given fields: app, id, w
-func (_m *Interface) BuildExport(app string, id string, w io.Writer) error {
-	ret := _m.Called(app, id, w)
+// BuildExport provides a mock function with given fields: app, id, w, opts
+func (_m *Interface) BuildExport(app string, id string, w io.Writer, opts structs.BuildExportOptions) error {
+	ret := _m.Called(app, id, w, opts)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, io.Writer) error); ok {
-		r0 = rf(app, id, w)
+	if rf, ok := ret.Get(0).(func(string, string, io.Writer, structs.BuildExportOptions) error); ok {
+		r0 = rf(app, id, w, opts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -295,13 +309,13 @@ func (_m *Interface) BuildGet(app string, id string) (*structs.Build, error) {
 	return r0, r1
 }
 
-// BuildImport provides a mock function with given fields: app, r
-func (_m *Interface) BuildImport(app string, r io.Reader) (*structs.Build, error) {
-	ret := _m.Called(app, r)
+// BuildImport provides a mock function with given fields: app, r, opts
+func (_m *Interface) BuildImport(app string, r io.Reader, opts structs.BuildImportOptions) (*structs.Build, error) {
+	ret := _m.Called(app, r, opts)
 
 	var r0 *structs.Build
-	if rf, ok := ret.Get(0).(func(string, io.Reader) *structs.Build); ok {
-		r0 = rf(app, r)
+	if rf, ok := ret.Get(0).(func(string, io.Reader, structs.BuildImportOptions) *structs.Build); ok {
+		r0 = rf(app, r, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*structs.Build)
@@ -309,8 +323,31 @@ func (_m *Interface) BuildImport(app string, r io.Reader) (*structs.Build, error
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, io.Reader) error); ok {
-		r1 = rf(app, r)
+	if rf, ok := ret.Get(1).(func(string, io.Reader, structs.BuildImportOptions) error); ok {
+		r1 = rf(app, r, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BuildImportChunked provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Interface) BuildImportChunked(_a0 string, _a1 io.Reader, _a2 structs.BuildImportOptions) (*structs.Build, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *structs.Build
+	if rf, ok := ret.Get(0).(func(string, io.Reader, structs.BuildImportOptions) *structs.Build); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.Build)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, io.Reader, structs.BuildImportOptions) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -410,6 +447,29 @@ func (_m *Interface) BuildLogs(app string, id string, opts structs.LogsOptions)
 	return r0, r1
 }
 
+// BuildSbom provides a mock function with given fields: app, id, opts
+func (_m *Interface) BuildSbom(app string, id string, opts structs.BuildSbomOptions) (io.ReadCloser, error) {
+	ret := _m.Called(app, id, opts)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, string, structs.BuildSbomOptions) io.ReadCloser); ok {
+		r0 = rf(app, id, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, structs.BuildSbomOptions) error); ok {
+		r1 = rf(app, id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BuildUpdate provides a mock function with given fields: app, id, opts
 func (_m *Interface) BuildUpdate(app string, id string, opts structs.BuildUpdateOptions) (*structs.Build, error) {
 	ret := _m.Called(app, id, opts)
@@ -456,6 +516,29 @@ func (_m *Interface) CapacityGet() (*structs.Capacity, error) {
 	return r0, r1
 }
 
+// CapacityPlan provides a mock function with given fields: opts
+func (_m *Interface) CapacityPlan(opts structs.CapacityPlanOptions) (*structs.CapacityPlan, error) {
+	ret := _m.Called(opts)
+
+	var r0 *structs.CapacityPlan
+	if rf, ok := ret.Get(0).(func(structs.CapacityPlanOptions) *structs.CapacityPlan); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.CapacityPlan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(structs.CapacityPlanOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CertificateApply provides a mock function with given fields: app, service, port, id
 func (_m *Interface) CertificateApply(app string, service string, port int, id string) error {
 	ret := _m.Called(app, service, port, id)
@@ -590,6 +673,80 @@ func (_m *Interface) DeleteDB(resource string) error {
 	return r0
 }
 
+// DrainCreate provides a mock function with given fields: app, name, url, opts
+func (_m *Interface) DrainCreate(app string, name string, url string, opts structs.DrainCreateOptions) (*structs.Drain, error) {
+	ret := _m.Called(app, name, url, opts)
+
+	var r0 *structs.Drain
+	if rf, ok := ret.Get(0).(func(string, string, string, structs.DrainCreateOptions) *structs.Drain); ok {
+		r0 = rf(app, name, url, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.Drain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string, structs.DrainCreateOptions) error); ok {
+		r1 = rf(app, name, url, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DrainDelete provides a mock function with given fields: app, name
+func (_m *Interface) DrainDelete(app string, name string) error {
+	ret := _m.Called(app, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(app, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DrainList provides a mock function with given fields: app
+func (_m *Interface) DrainList(app string) (structs.Drains, error) {
+	ret := _m.Called(app)
+
+	var r0 structs.Drains
+	if rf, ok := ret.Get(0).(func(string) structs.Drains); ok {
+		r0 = rf(app)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.Drains)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(app)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DrainTest provides a mock function with given fields: app, name
+func (_m *Interface) DrainTest(app string, name string) error {
+	ret := _m.Called(app, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(app, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // EnvironmentSet provides a mock function with given fields: _a0, _a1
 func (_m *Interface) EnvironmentSet(_a0 string, _a1 []byte) (*structs.Release, error) {
 	ret := _m.Called(_a0, _a1)
@@ -636,6 +793,29 @@ func (_m *Interface) EnvironmentUnset(_a0 string, _a1 string) (*structs.Release,
 	return r0, r1
 }
 
+// EventList provides a mock function with given fields: opts
+func (_m *Interface) EventList(opts structs.EventListOptions) (structs.Events, error) {
+	ret := _m.Called(opts)
+
+	var r0 structs.Events
+	if rf, ok := ret.Get(0).(func(structs.EventListOptions) structs.Events); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.Events)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(structs.EventListOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // EventSend provides a mock function with given fields: action, opts
 func (_m *Interface) EventSend(action string, opts structs.EventSendOptions) error {
 	ret := _m.Called(action, opts)
@@ -766,6 +946,20 @@ func (_m *Interface) Initialize(opts structs.ProviderOptions) error {
 	return r0
 }
 
+// InstanceDrain provides a mock function with given fields: id, opts
+func (_m *Interface) InstanceDrain(id string, opts structs.InstanceDrainOptions) error {
+	ret := _m.Called(id, opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, structs.InstanceDrainOptions) error); ok {
+		r0 = rf(id, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InstanceKeyroll provides a mock function with given fields:
 func (_m *Interface) InstanceKeyroll() error {
 	ret := _m.Called()
@@ -2139,6 +2333,89 @@ func (_m *Interface) SystemUpdate(opts structs.SystemUpdateOptions) error {
 	return r0
 }
 
+// SystemWorkers provides a mock function with given fields: 
+func (_m *Interface) SystemWorkers() (structs.Workers, error) {
+	ret := _m.Called()
+
+	var r0 structs.Workers
+	if rf, ok := ret.Get(0).(func() structs.Workers); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.Workers)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookDeliveryList provides a mock function with given fields: name, opts
+func (_m *Interface) WebhookDeliveryList(name string, opts structs.WebhookDeliveryListOptions) (structs.WebhookDeliveries, error) {
+	ret := _m.Called(name, opts)
+
+	var r0 structs.WebhookDeliveries
+	if rf, ok := ret.Get(0).(func(string, structs.WebhookDeliveryListOptions) structs.WebhookDeliveries); ok {
+		r0 = rf(name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.WebhookDeliveries)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, structs.WebhookDeliveryListOptions) error); ok {
+		r1 = rf(name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookPreview provides a mock function with given fields: name, opts
+func (_m *Interface) WebhookPreview(name string, opts structs.WebhookPreviewOptions) (*structs.WebhookPreview, error) {
+	ret := _m.Called(name, opts)
+
+	var r0 *structs.WebhookPreview
+	if rf, ok := ret.Get(0).(func(string, structs.WebhookPreviewOptions) *structs.WebhookPreview); ok {
+		r0 = rf(name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.WebhookPreview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, structs.WebhookPreviewOptions) error); ok {
+		r1 = rf(name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookRedeliver provides a mock function with given fields: name, id
+func (_m *Interface) WebhookRedeliver(name string, id string) error {
+	ret := _m.Called(name, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WithContext provides a mock function with given fields: ctx
 func (_m *Interface) WithContext(ctx context.Context) structs.Provider {
 	ret := _m.Called(ctx)
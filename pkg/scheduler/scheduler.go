@@ -0,0 +1,315 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/convox/rack/pkg/structs"
+)
+
+// DefaultStopTimeout is how long Stop waits for running jobs to return once
+// their context is cancelled
+const DefaultStopTimeout = 10 * time.Second
+
+// Job is a function run periodically by a Scheduler, its context is cancelled
+// when the job times out or the scheduler stops
+type Job struct {
+	Name      string
+	Fn        func(ctx context.Context) error
+	Immediate bool          // run once when the scheduler starts
+	Interval  time.Duration // zero to register the job disabled
+	Jitter    time.Duration // random delay up to this added to each interval
+	Timeout   time.Duration // zero for no timeout
+}
+
+// Scheduler runs registered jobs on their intervals and records the outcome
+// of each run. A job that panics or times out is recorded as failed and runs
+// again on its next interval, a job still running from a timed out run is
+// skipped until it returns.
+type Scheduler struct {
+	// Report is called with the state of all jobs after each run
+	Report func(structs.Workers)
+
+	// StopTimeout bounds how long Stop waits for running jobs
+	StopTimeout time.Duration
+
+	cancel  context.CancelFunc
+	ctx     context.Context
+	done    chan struct{}
+	jobs    map[string]*job
+	lock    sync.Mutex
+	running sync.WaitGroup
+	started bool
+	wg      sync.WaitGroup
+}
+
+type job struct {
+	Job
+	state structs.Worker
+}
+
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		StopTimeout: DefaultStopTimeout,
+		cancel:      cancel,
+		ctx:         ctx,
+		done:        make(chan struct{}),
+		jobs:        map[string]*job{},
+	}
+}
+
+// Register adds a job, jobs must be registered before Start
+func (s *Scheduler) Register(j Job) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.started {
+		return fmt.Errorf("scheduler already started")
+	}
+
+	if _, ok := s.jobs[j.Name]; ok {
+		return fmt.Errorf("job already registered: %s", j.Name)
+	}
+
+	s.jobs[j.Name] = &job{
+		Job: j,
+		state: structs.Worker{
+			Name:     j.Name,
+			Disabled: j.Interval <= 0,
+			Interval: j.Interval,
+			Timeout:  j.Timeout,
+		},
+	}
+
+	return nil
+}
+
+// Run runs a job immediately and returns its error
+func (s *Scheduler) Run(name string) error {
+	s.lock.Lock()
+	j, ok := s.jobs[name]
+	s.lock.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no such job: %s", name)
+	}
+
+	return s.run(j)
+}
+
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.started {
+		return
+	}
+
+	s.started = true
+
+	for _, j := range s.jobs {
+		if j.state.Disabled {
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Stop ends the job loops, cancels the running jobs and waits up to
+// StopTimeout for them to return, including runs that have timed out
+func (s *Scheduler) Stop() {
+	close(s.done)
+	s.cancel()
+	s.wg.Wait()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		s.running.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.StopTimeout):
+	}
+}
+
+// Workers returns the state of all jobs sorted by name
+func (s *Scheduler) Workers() structs.Workers {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ws := structs.Workers{}
+
+	for _, j := range s.jobs {
+		ws = append(ws, j.state)
+	}
+
+	sort.Slice(ws, func(i, j int) bool { return ws[i].Name < ws[j].Name })
+
+	return ws
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	delay := j.next()
+
+	if j.Immediate {
+		delay = 0
+	}
+
+	for {
+		s.lock.Lock()
+		j.state.NextRun = time.Now().Add(delay).UTC()
+		s.lock.Unlock()
+
+		t := time.NewTimer(delay)
+
+		select {
+		case <-s.done:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		s.run(j)
+
+		delay = j.next()
+	}
+}
+
+func (s *Scheduler) run(j *job) error {
+	s.lock.Lock()
+
+	if j.state.Running {
+		s.lock.Unlock()
+		return fmt.Errorf("job still running: %s", j.Name)
+	}
+
+	start := time.Now().UTC()
+
+	j.state.LastRun = start
+	j.state.Running = true
+
+	s.running.Add(1)
+
+	s.lock.Unlock()
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	if j.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(s.ctx, j.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(s.ctx)
+	}
+
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer s.running.Done()
+
+		var err error
+
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic: %v", r)
+				}
+			}()
+
+			err = j.Fn(ctx)
+		}()
+
+		s.lock.Lock()
+		j.state.Running = false
+		s.lock.Unlock()
+
+		done <- err
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timeout after %s", j.Timeout)
+
+		if s.ctx.Err() != nil {
+			err = fmt.Errorf("stopped")
+		}
+	}
+
+	s.lock.Lock()
+
+	j.state.Duration = time.Since(start)
+	j.state.Runs++
+
+	if err != nil {
+		j.state.Error = err.Error()
+		j.state.Failures++
+	} else {
+		j.state.Error = ""
+		j.state.LastSuccess = start
+	}
+
+	s.lock.Unlock()
+
+	if s.Report != nil {
+		s.Report(s.Workers())
+	}
+
+	return err
+}
+
+func (j *job) next() time.Duration {
+	d := j.Interval
+
+	if j.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(j.Jitter)))
+	}
+
+	return d
+}
+
+// ParseIntervals parses interval overrides in the form "monitor=5m,cleanup=1h",
+// an interval of 0 disables a job
+func ParseIntervals(s string) (map[string]time.Duration, error) {
+	intervals := map[string]time.Duration{}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid worker interval: %s", part)
+		}
+
+		d, err := time.ParseDuration(kv[1])
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid worker interval: %s", part)
+		}
+
+		intervals[kv[0]] = d
+	}
+
+	return intervals, nil
+}
@@ -0,0 +1,267 @@
+package scheduler_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/scheduler"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	s := scheduler.New()
+
+	fail := false
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name: "job1",
+		Fn: func(ctx context.Context) error {
+			if fail {
+				return fmt.Errorf("err1")
+			}
+			return nil
+		},
+		Interval: time.Minute,
+		Timeout:  time.Second,
+	}))
+
+	require.NoError(t, s.Run("job1"))
+
+	fail = true
+
+	require.EqualError(t, s.Run("job1"), "err1")
+
+	ws := s.Workers()
+	require.Len(t, ws, 1)
+
+	w := ws[0]
+	require.Equal(t, "job1", w.Name)
+	require.Equal(t, "err1", w.Error)
+	require.Equal(t, 1, w.Failures)
+	require.Equal(t, 2, w.Runs)
+	require.Equal(t, time.Minute, w.Interval)
+	require.Equal(t, time.Second, w.Timeout)
+	require.False(t, w.Running)
+	require.False(t, w.LastRun.IsZero())
+	require.False(t, w.LastSuccess.IsZero())
+
+	fail = false
+
+	require.NoError(t, s.Run("job1"))
+	require.Equal(t, "", s.Workers()[0].Error)
+
+	require.EqualError(t, s.Run("job2"), "no such job: job2")
+}
+
+func TestRunPanic(t *testing.T) {
+	s := scheduler.New()
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "job1",
+		Fn:       func(ctx context.Context) error { panic("boom") },
+		Interval: time.Minute,
+	}))
+
+	require.EqualError(t, s.Run("job1"), "panic: boom")
+
+	w := s.Workers()[0]
+	require.Equal(t, "panic: boom", w.Error)
+	require.False(t, w.Running)
+}
+
+func TestRunTimeout(t *testing.T) {
+	s := scheduler.New()
+
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name: "job1",
+		Fn: func(ctx context.Context) error {
+			defer close(returned)
+			<-release
+			return nil
+		},
+		Interval: time.Minute,
+		Timeout:  10 * time.Millisecond,
+	}))
+
+	require.EqualError(t, s.Run("job1"), "timeout after 10ms")
+
+	w := s.Workers()[0]
+	require.Equal(t, "timeout after 10ms", w.Error)
+	require.True(t, w.Running)
+
+	require.EqualError(t, s.Run("job1"), "job still running: job1")
+
+	close(release)
+	<-returned
+
+	require.Eventually(t, func() bool { return !s.Workers()[0].Running }, time.Second, time.Millisecond)
+}
+
+func TestRegister(t *testing.T) {
+	s := scheduler.New()
+
+	require.NoError(t, s.Register(scheduler.Job{Name: "job1", Interval: time.Minute}))
+	require.NoError(t, s.Register(scheduler.Job{Name: "job0"}))
+
+	require.EqualError(t, s.Register(scheduler.Job{Name: "job1"}), "job already registered: job1")
+
+	ws := s.Workers()
+	require.Equal(t, []string{"job0", "job1"}, []string{ws[0].Name, ws[1].Name})
+	require.True(t, ws[0].Disabled)
+	require.False(t, ws[1].Disabled)
+
+	s.Start()
+	defer s.Stop()
+
+	require.EqualError(t, s.Register(scheduler.Job{Name: "job2"}), "scheduler already started")
+}
+
+func TestStart(t *testing.T) {
+	s := scheduler.New()
+
+	runs := make(chan struct{}, 10)
+	reports := make(chan structs.Workers, 10)
+
+	s.Report = func(ws structs.Workers) {
+		reports <- ws
+	}
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:      "immediate",
+		Fn:        func(ctx context.Context) error { runs <- struct{}{}; return nil },
+		Immediate: true,
+		Interval:  time.Hour,
+	}))
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "disabled",
+		Fn:       func(ctx context.Context) error { panic("should not run") },
+		Interval: 0,
+	}))
+
+	s.Start()
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("immediate job did not run")
+	}
+
+	ws := <-reports
+	require.Equal(t, "disabled", ws[0].Name)
+	require.Equal(t, 0, ws[0].Runs)
+	require.Equal(t, "immediate", ws[1].Name)
+	require.Equal(t, 1, ws[1].Runs)
+
+	require.Eventually(t, func() bool {
+		return s.Workers()[1].NextRun.After(time.Now().Add(59 * time.Minute))
+	}, time.Second, time.Millisecond)
+
+	s.Stop()
+}
+
+func TestStop(t *testing.T) {
+	s := scheduler.New()
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name: "job1",
+		Fn: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			stopped <- ctx.Err()
+			return ctx.Err()
+		},
+		Immediate: true,
+		Interval:  time.Hour,
+	}))
+
+	s.Start()
+
+	<-started
+
+	s.Stop()
+
+	select {
+	case err := <-stopped:
+		require.Equal(t, context.Canceled, err)
+	default:
+		t.Fatal("stop did not wait for the running job")
+	}
+
+	require.Equal(t, "stopped", s.Workers()[0].Error)
+}
+
+func TestStopTimeout(t *testing.T) {
+	s := scheduler.New()
+
+	s.StopTimeout = 50 * time.Millisecond
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:      "job1",
+		Fn:        func(ctx context.Context) error { close(started); <-release; return nil },
+		Immediate: true,
+		Interval:  time.Hour,
+	}))
+
+	s.Start()
+
+	<-started
+
+	start := time.Now()
+
+	s.Stop()
+
+	require.WithinDuration(t, start.Add(50*time.Millisecond), time.Now(), 500*time.Millisecond)
+}
+
+func TestJitter(t *testing.T) {
+	s := scheduler.New()
+
+	runs := make(chan time.Time, 10)
+
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "job1",
+		Fn:       func(ctx context.Context) error { runs <- time.Now(); return nil },
+		Interval: 10 * time.Millisecond,
+		Jitter:   20 * time.Millisecond,
+	}))
+
+	start := time.Now()
+
+	s.Start()
+
+	first := <-runs
+
+	s.Stop()
+
+	require.True(t, first.Sub(start) >= 10*time.Millisecond)
+}
+
+func TestParseIntervals(t *testing.T) {
+	intervals, err := scheduler.ParseIntervals("monitor=10m, spot-replace=2m,cleanup=0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{"cleanup": 0, "monitor": 10 * time.Minute, "spot-replace": 2 * time.Minute}, intervals)
+
+	intervals, err = scheduler.ParseIntervals("")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{}, intervals)
+
+	for _, s := range []string{"monitor", "=5m", "monitor=soon", "monitor=-1m"} {
+		_, err := scheduler.ParseIntervals(s)
+		require.EqualError(t, err, fmt.Sprintf("invalid worker interval: %s", s))
+	}
+}
@@ -0,0 +1,365 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Signatures are stored the way cosign stores them so `cosign verify` can
+// check an image signed by a rack: a payload in the simple signing format
+// for each signature, as the layers of an image tagged after the digest of
+// the signed image
+const (
+	PayloadMediaType    = "application/vnd.dev.cosign.simplesigning.v1+json"
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	payloadType = "cosign container image signature"
+)
+
+var (
+	ErrUnsigned  = errors.New("image is not signed")
+	ErrUntrusted = errors.New("image is not signed by a trusted key")
+)
+
+// Signature is a signed payload that names the digest of an image
+type Signature struct {
+	Payload   []byte `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+type payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+// Signer signs the sha256 digest of a payload with an ECDSA key and returns
+// the ASN.1 encoded signature
+type Signer interface {
+	Sign(digest []byte) ([]byte, error)
+}
+
+// SignerFunc is a function that is a Signer
+type SignerFunc func(digest []byte) ([]byte, error)
+
+func (fn SignerFunc) Sign(digest []byte) ([]byte, error) {
+	return fn(digest)
+}
+
+// NewKMSSigner returns a Signer for an ECC_NIST_P256 KMS key, the key never
+// leaves KMS
+func NewKMSSigner(k *kms.KMS, key string) Signer {
+	return SignerFunc(func(digest []byte) ([]byte, error) {
+		res, err := k.Sign(&kms.SignInput{
+			KeyId:            aws.String(key),
+			Message:          digest,
+			MessageType:      aws.String(kms.MessageTypeDigest),
+			SigningAlgorithm: aws.String(kms.SigningAlgorithmSpecEcdsaSha256),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return res.Signature, nil
+	})
+}
+
+// Sign signs the image in repository with digest
+func Sign(s Signer, repository, digest string) (*Signature, error) {
+	var p payload
+
+	p.Critical.Identity.DockerReference = repository
+	p.Critical.Image.DockerManifestDigest = digest
+	p.Critical.Type = payloadType
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+
+	sig, err := s.Sign(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+
+	return &Signature{Payload: data, Signature: base64.StdEncoding.EncodeToString(sig)}, nil
+}
+
+// Verify returns an error unless one of sigs is a signature of the image with
+// digest by one of keys
+func Verify(sigs []Signature, digest string, keys []*ecdsa.PublicKey) error {
+	if len(sigs) == 0 {
+		return ErrUnsigned
+	}
+
+	for _, s := range sigs {
+		if s.verify(digest, keys) {
+			return nil
+		}
+	}
+
+	return ErrUntrusted
+}
+
+func (s Signature) verify(digest string, keys []*ecdsa.PublicKey) bool {
+	var p payload
+
+	if err := json.Unmarshal(s.Payload, &p); err != nil {
+		return false
+	}
+
+	if p.Critical.Type != payloadType || p.Critical.Image.DockerManifestDigest != digest {
+		return false
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return false
+	}
+
+	sum := sha256.Sum256(s.Payload)
+
+	for _, k := range keys {
+		if ecdsa.VerifyASN1(k, sum[:], sig) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tag returns the tag of the signature image for the image with digest
+func Tag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// Image returns a signature image that holds sigs
+func Image(sigs []Signature) (v1.Image, error) {
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, types.OCIConfigJSON)
+
+	for _, s := range sigs {
+		var err error
+
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer:       payloadLayer(s.Payload),
+			MediaType:   PayloadMediaType,
+			Annotations: map[string]string{SignatureAnnotation: s.Signature},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return img, nil
+}
+
+// Push pushes a signature image that holds sigs for the image in repository
+// with digest
+func Push(ctx context.Context, repository, digest string, sigs []Signature, opts ...remote.Option) error {
+	tag, err := name.NewTag(fmt.Sprintf("%s:%s", repository, Tag(digest)))
+	if err != nil {
+		return err
+	}
+
+	img, err := Image(sigs)
+	if err != nil {
+		return err
+	}
+
+	return remote.Write(tag, img, append(opts, remote.WithContext(ctx))...)
+}
+
+// Signatures returns the signatures held by the signature image with
+// manifest, blob fetches the payload of each one
+func Signatures(manifest []byte, blob func(digest string) ([]byte, error)) ([]Signature, error) {
+	m, err := v1.ParseManifest(bytes.NewReader(manifest))
+	if err != nil {
+		return nil, fmt.Errorf("parse signature manifest: %w", err)
+	}
+
+	sigs := []Signature{}
+
+	for _, l := range m.Layers {
+		if l.MediaType != PayloadMediaType {
+			continue
+		}
+
+		data, err := blob(l.Digest.String())
+		if err != nil {
+			return nil, err
+		}
+
+		if sum := sha256.Sum256(data); fmt.Sprintf("sha256:%x", sum) != l.Digest.String() {
+			return nil, fmt.Errorf("signature payload does not match digest: %s", l.Digest)
+		}
+
+		sigs = append(sigs, Signature{Payload: data, Signature: l.Annotations[SignatureAnnotation]})
+	}
+
+	return sigs, nil
+}
+
+// VerifyConfig returns an error unless the manifest with digest describes the
+// image with config, or is an index with a platform manifest that does.
+// Manifests are keyed by digest and checked against it, config is the digest
+// of the image config as docker save names it.
+func VerifyConfig(manifests map[string][]byte, digest, config string) error {
+	data, ok := manifests[digest]
+	if !ok {
+		return fmt.Errorf("missing manifest: %s", digest)
+	}
+
+	if sum := sha256.Sum256(data); fmt.Sprintf("sha256:%x", sum) != digest {
+		return fmt.Errorf("manifest does not match digest: %s", digest)
+	}
+
+	config = strings.TrimSuffix(config[strings.LastIndex(config, "/")+1:], ".json")
+
+	var desc struct {
+		MediaType types.MediaType `json:"mediaType"`
+	}
+
+	if err := json.Unmarshal(data, &desc); err != nil {
+		return fmt.Errorf("parse manifest: %w", err)
+	}
+
+	if desc.MediaType.IsIndex() {
+		idx, err := v1.ParseIndexManifest(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("parse index: %w", err)
+		}
+
+		for _, m := range idx.Manifests {
+			if _, ok := manifests[m.Digest.String()]; !ok {
+				continue
+			}
+
+			if err := VerifyConfig(manifests, m.Digest.String(), config); err == nil {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("no manifest in index %s describes image: %s", digest, config)
+	}
+
+	m, err := v1.ParseManifest(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("parse manifest: %w", err)
+	}
+
+	if m.Config.Digest.Hex != config {
+		return fmt.Errorf("manifest %s does not describe image: %s", digest, config)
+	}
+
+	return nil
+}
+
+// ParsePublicKeys parses a comma delimited list of public keys
+func ParsePublicKeys(list string) ([]*ecdsa.PublicKey, error) {
+	keys := []*ecdsa.PublicKey{}
+
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		k, err := ParsePublicKey(s)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// ParsePublicKey parses an ECDSA public key, either PEM encoded like a
+// cosign.pub or the base64 encoded DER between its header and footer
+func ParsePublicKey(s string) (*ecdsa.PublicKey, error) {
+	var der []byte
+
+	if b, _ := pem.Decode([]byte(s)); b != nil {
+		der = b.Bytes
+	} else {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		der = data
+	}
+
+	return ParsePublicKeyDER(der)
+}
+
+// ParsePublicKeyDER parses a DER encoded ECDSA public key as returned by KMS
+func ParsePublicKeyDER(der []byte) (*ecdsa.PublicKey, error) {
+	k, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	ek, ok := k.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid public key: not an ecdsa key")
+	}
+
+	return ek, nil
+}
+
+// payloadLayer is a layer that holds a signature payload as is
+type payloadLayer []byte
+
+func (l payloadLayer) Digest() (v1.Hash, error) {
+	h, _, err := v1.SHA256(bytes.NewReader(l))
+	return h, err
+}
+
+func (l payloadLayer) DiffID() (v1.Hash, error) {
+	return l.Digest()
+}
+
+func (l payloadLayer) Compressed() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(l)), nil
+}
+
+func (l payloadLayer) Uncompressed() (io.ReadCloser, error) {
+	return l.Compressed()
+}
+
+func (l payloadLayer) Size() (int64, error) {
+	return int64(len(l)), nil
+}
+
+func (l payloadLayer) MediaType() (types.MediaType, error) {
+	return PayloadMediaType, nil
+}
@@ -0,0 +1,158 @@
+package signing_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/convox/rack/pkg/signing"
+	"github.com/stretchr/testify/require"
+)
+
+const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func testKey(t *testing.T) (*ecdsa.PrivateKey, signing.Signer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key, signing.SignerFunc(func(d []byte) ([]byte, error) {
+		return ecdsa.SignASN1(rand.Reader, key, d)
+	})
+}
+
+func TestSignVerify(t *testing.T) {
+	key, signer := testKey(t)
+	other, _ := testKey(t)
+
+	sig, err := signing.Sign(signer, "registry.example.org/app", digest)
+	require.NoError(t, err)
+
+	var p map[string]interface{}
+	require.NoError(t, json.Unmarshal(sig.Payload, &p))
+	require.Equal(t, map[string]interface{}{
+		"critical": map[string]interface{}{
+			"identity": map[string]interface{}{"docker-reference": "registry.example.org/app"},
+			"image":    map[string]interface{}{"docker-manifest-digest": digest},
+			"type":     "cosign container image signature",
+		},
+		"optional": nil,
+	}, p)
+
+	sigs := []signing.Signature{*sig}
+
+	require.NoError(t, signing.Verify(sigs, digest, []*ecdsa.PublicKey{&other.PublicKey, &key.PublicKey}))
+	require.Equal(t, signing.ErrUntrusted, signing.Verify(sigs, digest, []*ecdsa.PublicKey{&other.PublicKey}))
+	require.Equal(t, signing.ErrUntrusted, signing.Verify(sigs, "sha256:ffff", []*ecdsa.PublicKey{&key.PublicKey}))
+	require.Equal(t, signing.ErrUnsigned, signing.Verify(nil, digest, []*ecdsa.PublicKey{&key.PublicKey}))
+
+	tampered := *sig
+	tampered.Payload = append([]byte{}, sig.Payload...)
+	tampered.Payload[len(tampered.Payload)-2] = ' '
+	require.Equal(t, signing.ErrUntrusted, signing.Verify([]signing.Signature{tampered}, digest, []*ecdsa.PublicKey{&key.PublicKey}))
+}
+
+func TestTag(t *testing.T) {
+	require.Equal(t, "sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef.sig", signing.Tag(digest))
+}
+
+func TestImageSignatures(t *testing.T) {
+	_, signer := testKey(t)
+
+	sig1, err := signing.Sign(signer, "registry.example.org/app", digest)
+	require.NoError(t, err)
+
+	sig2, err := signing.Sign(signer, "registry.example.org/other", digest)
+	require.NoError(t, err)
+
+	img, err := signing.Image([]signing.Signature{*sig1, *sig2})
+	require.NoError(t, err)
+
+	data, err := img.RawManifest()
+	require.NoError(t, err)
+
+	var m struct {
+		MediaType string
+		Layers    []struct {
+			MediaType   string
+			Annotations map[string]string
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.Equal(t, "application/vnd.oci.image.manifest.v1+json", m.MediaType)
+	require.Len(t, m.Layers, 2)
+	require.Equal(t, signing.PayloadMediaType, m.Layers[0].MediaType)
+	require.Equal(t, sig1.Signature, m.Layers[0].Annotations[signing.SignatureAnnotation])
+
+	blobs := map[string][]byte{}
+
+	for _, s := range []*signing.Signature{sig1, sig2} {
+		blobs[fmt.Sprintf("sha256:%x", sha256.Sum256(s.Payload))] = s.Payload
+	}
+
+	sigs, err := signing.Signatures(data, func(d string) ([]byte, error) {
+		return blobs[d], nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []signing.Signature{*sig1, *sig2}, sigs)
+
+	_, err = signing.Signatures(data, func(d string) ([]byte, error) {
+		return []byte("other"), nil
+	})
+	require.EqualError(t, err, fmt.Sprintf("signature payload does not match digest: sha256:%x", sha256.Sum256(sig1.Payload)))
+}
+
+func TestVerifyConfig(t *testing.T) {
+	config := "4f3c0a37c1e0f8e7c3b9f2a4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708"
+
+	image := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":1,"digest":"sha256:%s"},"layers":[]}`, config))
+	imageDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(image))
+
+	index := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json","size":%d,"digest":"%s","platform":{"architecture":"amd64","os":"linux"}}]}`, len(image), imageDigest))
+	indexDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(index))
+
+	manifests := map[string][]byte{imageDigest: image, indexDigest: index}
+
+	require.NoError(t, signing.VerifyConfig(manifests, imageDigest, config+".json"))
+	require.NoError(t, signing.VerifyConfig(manifests, imageDigest, "blobs/sha256/"+config))
+	require.NoError(t, signing.VerifyConfig(manifests, indexDigest, config+".json"))
+
+	require.EqualError(t, signing.VerifyConfig(manifests, imageDigest, "ffff.json"), fmt.Sprintf("manifest %s does not describe image: ffff", imageDigest))
+	require.EqualError(t, signing.VerifyConfig(manifests, indexDigest, "ffff.json"), fmt.Sprintf("no manifest in index %s describes image: ffff", indexDigest))
+	require.EqualError(t, signing.VerifyConfig(manifests, "sha256:ffff", config), "missing manifest: sha256:ffff")
+	require.EqualError(t, signing.VerifyConfig(map[string][]byte{imageDigest: index}, imageDigest, config), fmt.Sprintf("manifest does not match digest: %s", imageDigest))
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	key1, _ := testKey(t)
+	key2, _ := testKey(t)
+
+	der1, err := x509.MarshalPKIXPublicKey(&key1.PublicKey)
+	require.NoError(t, err)
+
+	der2, err := x509.MarshalPKIXPublicKey(&key2.PublicKey)
+	require.NoError(t, err)
+
+	k, err := signing.ParsePublicKey(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der1})))
+	require.NoError(t, err)
+	require.True(t, key1.PublicKey.Equal(k))
+
+	keys, err := signing.ParsePublicKeys(fmt.Sprintf("%s, %s,", base64.StdEncoding.EncodeToString(der1), base64.StdEncoding.EncodeToString(der2)))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, key1.PublicKey.Equal(keys[0]))
+	require.True(t, key2.PublicKey.Equal(keys[1]))
+
+	keys, err = signing.ParsePublicKeys("")
+	require.NoError(t, err)
+	require.Len(t, keys, 0)
+
+	_, err = signing.ParsePublicKeys("not a key")
+	require.Error(t, err)
+}
@@ -562,18 +562,23 @@ func buildIgnores(root, service string) ([]string, error) {
 }
 
 func buildSources(m *manifest.Manifest, root, service string) ([]buildSource, error) {
-	data, err := buildDockerfile(m, root, service)
+	svc, err := m.Service(service)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	if data == nil {
-		return []buildSource{}, nil
+
+	// a buildpack build copies its whole build path into the workspace
+	if svc.Image == "" && svc.Build.Builder(root) != "" {
+		return []buildSource{{Local: svc.Build.Path, Remote: manifest.BuildpackWorkspace}}, nil
 	}
 
-	svc, err := m.Service(service)
+	data, err := buildDockerfile(m, root, service)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if data == nil {
+		return []buildSource{}, nil
+	}
 
 	bs := []buildSource{}
 	env := map[string]string{}
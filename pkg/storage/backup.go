@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"io"
+	"os"
+
+	"github.com/boltdb/bolt"
+)
+
+// Backup writes a consistent copy of the database to w while reads and
+// writes continue
+func (s *Storage) Backup(w io.Writer) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// Restore replaces all keys, expiries and the schema version with those of a
+// backup in a single transaction, watchers are not notified
+func (s *Storage) Restore(r io.Reader) error {
+	f, err := os.CreateTemp("", "storage-restore-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	src, err := bolt.Open(f.Name(), 0600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return src.View(func(stx *bolt.Tx) error {
+		return s.db.Update(func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{bucketExpires, bucketMeta, bucketRack} {
+				if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+					return err
+				}
+
+				b, err := tx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+
+				if sb := stx.Bucket(name); sb != nil {
+					if err := copyBucket(b, sb); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+func copyBucket(dst, src *bolt.Bucket) error {
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+
+		db, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+
+		return copyBucket(db, src.Bucket(k))
+	})
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+)
+
+var keyVersion = []byte("version")
+
+// Migration changes the stored data from one schema version to the next
+type Migration func(tx *bolt.Tx) error
+
+// Migrate applies the migrations after the current schema version in order,
+// migrations[0] moves the schema to version 1. Each migration commits with its
+// version so a failed migration can be retried.
+func (s *Storage) Migrate(migrations []Migration) error {
+	version, err := s.Version()
+	if err != nil {
+		return err
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			if err := migrations[i](tx); err != nil {
+				return fmt.Errorf("migration %d: %s", i+1, err)
+			}
+
+			return putVersion(tx, i+1)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Version returns the current schema version, zero for a new database
+func (s *Storage) Version() (int, error) {
+	version := 0
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(bucketMeta).Get(keyVersion); len(v) == 8 {
+			version = int(binary.BigEndian.Uint64(v))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func putVersion(tx *bolt.Tx, version int) error {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, uint64(version))
+
+	return tx.Bucket(bucketMeta).Put(keyVersion, v)
+}
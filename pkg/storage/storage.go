@@ -1,64 +1,158 @@
 package storage
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/boltdb/bolt"
 )
 
+var (
+	bucketExpires = []byte("expires") // full key => expiry as unix nanoseconds
+	bucketMeta    = []byte("meta")    // schema version
+	bucketRack    = []byte("rack")    // nested buckets per key path segment
+)
+
 type Storage struct {
-	db *bolt.DB
+	db       *bolt.DB
+	lock     sync.Mutex
+	watchers map[int]*watcher
+	next     int
 }
 
-type bucketFunc func(bucket *bolt.Bucket) error
-
 func Open(path string) (*Storage, error) {
-	db, err := bolt.Open(path, 0600, nil)
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
 	if err != nil {
 		return nil, err
 	}
 
-	s := &Storage{db: db}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketExpires, bucketMeta, bucketRack} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &Storage{db: db, watchers: map[int]*watcher{}}
 
 	return s, nil
 }
 
-func (s *Storage) bucket(key string, fn bucketFunc) error {
-	tx, err := s.db.Begin(true)
+func (s *Storage) Close() error {
+	s.lock.Lock()
+	for id, w := range s.watchers {
+		close(w.ch)
+		delete(s.watchers, id)
+	}
+	s.lock.Unlock()
+
+	return s.db.Close()
+}
+
+// Delete removes a key, deleting a missing key is not an error
+func (s *Storage) Delete(key string) error {
+	path, name, err := storageKeyParts(key)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
 
-	cur := tx.Bucket([]byte("rack"))
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := findBucket(tx, path)
+		if b == nil || b.Get([]byte(name)) == nil {
+			return nil
+		}
 
-	for _, kp := range strings.Split(key, "/") {
-		b, err := cur.CreateBucketIfNotExists([]byte(kp))
-		if err != nil {
+		if err := b.Delete([]byte(name)); err != nil {
 			return err
 		}
 
-		cur = b
-	}
+		if err := tx.Bucket(bucketExpires).Delete([]byte(key)); err != nil {
+			return err
+		}
 
-	if err := fn(cur); err != nil {
-		return err
-	}
+		s.notify(tx, Change{Key: key, Type: ChangeDelete})
 
-	return tx.Commit()
+		return nil
+	})
 }
 
-func (s *Storage) Close() error {
-	return s.db.Close()
+// Expire removes keys past their expiry and returns how many were removed
+func (s *Storage) Expire() (int, error) {
+	now := time.Now()
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		eb := tx.Bucket(bucketExpires)
+
+		expired := []string{}
+
+		err := eb.ForEach(func(k, v []byte) error {
+			if isExpired(v, now) {
+				expired = append(expired, string(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := eb.Delete([]byte(key)); err != nil {
+				return err
+			}
+
+			path, name, err := storageKeyParts(key)
+			if err != nil {
+				continue
+			}
+
+			if b := findBucket(tx, path); b != nil && b.Get([]byte(name)) != nil {
+				if err := b.Delete([]byte(name)); err != nil {
+					return err
+				}
+
+				s.notify(tx, Change{Key: key, Type: ChangeExpire})
+
+				removed++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
 }
 
+// List returns the names under a prefix, expired keys are skipped
 func (s *Storage) List(prefix string) ([]string, error) {
 	items := []string{}
+	now := time.Now()
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := findBucket(tx, prefix)
+		if b == nil {
+			return nil
+		}
 
-	err := s.bucket(prefix, func(bucket *bolt.Bucket) error {
-		return bucket.ForEach(func(k, v []byte) error {
+		eb := tx.Bucket(bucketExpires)
+
+		return b.ForEach(func(k, v []byte) error {
+			if v != nil && isExpired(eb.Get([]byte(prefix+"/"+string(k))), now) {
+				return nil
+			}
 			items = append(items, string(k))
 			return nil
 		})
@@ -91,11 +185,17 @@ func (s *Storage) Read(key string) ([]byte, error) {
 
 	var dcp []byte
 
-	err = s.bucket(path, func(bucket *bolt.Bucket) error {
-		data := bucket.Get([]byte(name))
-		if data == nil {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		var data []byte
+
+		if b := findBucket(tx, path); b != nil {
+			data = b.Get([]byte(name))
+		}
+
+		if data == nil || isExpired(tx.Bucket(bucketExpires).Get([]byte(key)), time.Now()) {
 			return fmt.Errorf("no such key: %s", key)
 		}
+
 		dcp = make([]byte, len(data))
 		copy(dcp, data)
 		return nil
@@ -107,6 +207,99 @@ func (s *Storage) Read(key string) ([]byte, error) {
 	return dcp, nil
 }
 
+func (s *Storage) Save(key string, v interface{}) error {
+	return s.SaveTTL(key, v, 0)
+}
+
+// SaveTTL saves a key that expires after ttl, zero to never expire
+func (s *Storage) SaveTTL(key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.WriteTTL(key, data, ttl)
+}
+
+func (s *Storage) Write(key string, data []byte) error {
+	return s.WriteTTL(key, data, 0)
+}
+
+// WriteTTL writes a key that expires after ttl, zero to never expire
+func (s *Storage) WriteTTL(key string, data []byte, ttl time.Duration) error {
+	path, name, err := storageKeyParts(key)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := createBucket(tx, path)
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put([]byte(name), data); err != nil {
+			return err
+		}
+
+		eb := tx.Bucket(bucketExpires)
+
+		if ttl > 0 {
+			exp := make([]byte, 8)
+			binary.BigEndian.PutUint64(exp, uint64(time.Now().Add(ttl).UnixNano()))
+
+			if err := eb.Put([]byte(key), exp); err != nil {
+				return err
+			}
+		} else if err := eb.Delete([]byte(key)); err != nil {
+			return err
+		}
+
+		dcp := make([]byte, len(data))
+		copy(dcp, data)
+
+		s.notify(tx, Change{Key: key, Type: ChangeWrite, Value: dcp})
+
+		return nil
+	})
+}
+
+func createBucket(tx *bolt.Tx, path string) (*bolt.Bucket, error) {
+	cur := tx.Bucket(bucketRack)
+
+	for _, kp := range strings.Split(path, "/") {
+		b, err := cur.CreateBucketIfNotExists([]byte(kp))
+		if err != nil {
+			return nil, err
+		}
+
+		cur = b
+	}
+
+	return cur, nil
+}
+
+// findBucket returns nil if any bucket along the path does not exist
+func findBucket(tx *bolt.Tx, path string) *bolt.Bucket {
+	cur := tx.Bucket(bucketRack)
+
+	for _, kp := range strings.Split(path, "/") {
+		if cur = cur.Bucket([]byte(kp)); cur == nil {
+			return nil
+		}
+	}
+
+	return cur
+}
+
+func isExpired(exp []byte, now time.Time) bool {
+	if len(exp) != 8 {
+		return false
+	}
+
+	return int64(binary.BigEndian.Uint64(exp)) <= now.UnixNano()
+}
+
 func storageKeyParts(key string) (string, string, error) {
 	parts := strings.Split(key, "/")
 
@@ -0,0 +1,247 @@
+package storage_test
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/convox/rack/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func testStorage(t *testing.T) *storage.Storage {
+	s, err := storage.Open(filepath.Join(t.TempDir(), "rack.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func TestSaveLoad(t *testing.T) {
+	s := testStorage(t)
+
+	require.NoError(t, s.Save("apps/app1/settings", map[string]string{"foo": "bar"}))
+
+	var v map[string]string
+
+	require.NoError(t, s.Load("apps/app1/settings", &v))
+	require.Equal(t, map[string]string{"foo": "bar"}, v)
+
+	_, err := s.Read("apps/app2/settings")
+	require.EqualError(t, err, "no such key: apps/app2/settings")
+
+	_, err = s.Read("apps")
+	require.EqualError(t, err, "cannot pop key: apps")
+}
+
+func TestList(t *testing.T) {
+	s := testStorage(t)
+
+	require.NoError(t, s.Write("apps/app1", []byte("1")))
+	require.NoError(t, s.Write("apps/app2", []byte("2")))
+
+	items, err := s.List("apps")
+	require.NoError(t, err)
+	require.Equal(t, []string{"app1", "app2"}, items)
+
+	items, err = s.List("missing")
+	require.NoError(t, err)
+	require.Equal(t, []string{}, items)
+}
+
+func TestDelete(t *testing.T) {
+	s := testStorage(t)
+
+	require.NoError(t, s.Write("apps/app1", []byte("1")))
+	require.NoError(t, s.Delete("apps/app1"))
+	require.NoError(t, s.Delete("apps/app1"))
+
+	_, err := s.Read("apps/app1")
+	require.EqualError(t, err, "no such key: apps/app1")
+}
+
+func TestTTL(t *testing.T) {
+	s := testStorage(t)
+
+	require.NoError(t, s.WriteTTL("events/e1", []byte("1"), time.Millisecond))
+	require.NoError(t, s.WriteTTL("events/e2", []byte("2"), time.Hour))
+	require.NoError(t, s.Write("events/e3", []byte("3")))
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := s.Read("events/e1")
+	require.EqualError(t, err, "no such key: events/e1")
+
+	items, err := s.List("events")
+	require.NoError(t, err)
+	require.Equal(t, []string{"e2", "e3"}, items)
+
+	n, err := s.Expire()
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	n, err = s.Expire()
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
+func TestTTLCleared(t *testing.T) {
+	s := testStorage(t)
+
+	require.NoError(t, s.WriteTTL("events/e1", []byte("1"), time.Millisecond))
+	require.NoError(t, s.Write("events/e1", []byte("2")))
+
+	time.Sleep(5 * time.Millisecond)
+
+	data, err := s.Read("events/e1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), data)
+}
+
+func TestWatch(t *testing.T) {
+	s := testStorage(t)
+
+	ch, cancel := s.Watch("apps/")
+
+	require.NoError(t, s.Write("apps/app1", []byte("1")))
+	require.NoError(t, s.Write("racks/rack1", []byte("2")))
+	require.NoError(t, s.Delete("apps/app1"))
+	require.NoError(t, s.WriteTTL("apps/app2", []byte("3"), time.Millisecond))
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := s.Expire()
+	require.NoError(t, err)
+
+	require.Equal(t, storage.Change{Key: "apps/app1", Type: storage.ChangeWrite, Value: []byte("1")}, <-ch)
+	require.Equal(t, storage.Change{Key: "apps/app1", Type: storage.ChangeDelete}, <-ch)
+	require.Equal(t, storage.Change{Key: "apps/app2", Type: storage.ChangeWrite, Value: []byte("3")}, <-ch)
+	require.Equal(t, storage.Change{Key: "apps/app2", Type: storage.ChangeExpire}, <-ch)
+
+	cancel()
+	cancel()
+
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func TestWatchRollback(t *testing.T) {
+	s := testStorage(t)
+
+	ch, cancel := s.Watch("")
+	defer cancel()
+
+	err := s.Migrate([]storage.Migration{
+		func(tx *bolt.Tx) error { return fmt.Errorf("err1") },
+	})
+	require.EqualError(t, err, "migration 1: err1")
+
+	require.NoError(t, s.Write("apps/app1", []byte("1")))
+
+	require.Equal(t, "apps/app1", (<-ch).Key)
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := storage.Open(filepath.Join(dir, "rack.db"))
+	require.NoError(t, err)
+
+	version, err := s.Version()
+	require.NoError(t, err)
+	require.Equal(t, 0, version)
+
+	ran := []int{}
+
+	migration := func(n int) storage.Migration {
+		return func(tx *bolt.Tx) error {
+			ran = append(ran, n)
+			return nil
+		}
+	}
+
+	require.NoError(t, s.Migrate([]storage.Migration{migration(1), migration(2)}))
+	require.Equal(t, []int{1, 2}, ran)
+	require.NoError(t, s.Close())
+
+	s, err = storage.Open(filepath.Join(dir, "rack.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Migrate([]storage.Migration{migration(1), migration(2), migration(3)}))
+	require.Equal(t, []int{1, 2, 3}, ran)
+
+	version, err = s.Version()
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+
+	err = s.Migrate([]storage.Migration{migration(1)})
+	require.EqualError(t, err, "schema version 3 is newer than 1")
+}
+
+func TestMigrateError(t *testing.T) {
+	s := testStorage(t)
+
+	err := s.Migrate([]storage.Migration{
+		func(tx *bolt.Tx) error { return nil },
+		func(tx *bolt.Tx) error {
+			if _, err := tx.CreateBucket([]byte("partial")); err != nil {
+				return err
+			}
+			return fmt.Errorf("err1")
+		},
+	})
+	require.EqualError(t, err, "migration 2: err1")
+
+	version, err := s.Version()
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+}
+
+func TestBackupRestore(t *testing.T) {
+	s := testStorage(t)
+
+	require.NoError(t, s.Migrate([]storage.Migration{func(tx *bolt.Tx) error { return nil }}))
+	require.NoError(t, s.Write("apps/app1", []byte("1")))
+	require.NoError(t, s.WriteTTL("events/e1", []byte("2"), time.Hour))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, s.Backup(&buf))
+
+	r := testStorage(t)
+
+	require.NoError(t, r.Write("apps/app2", []byte("3")))
+	require.NoError(t, r.Restore(&buf))
+
+	data, err := r.Read("apps/app1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), data)
+
+	_, err = r.Read("apps/app2")
+	require.EqualError(t, err, "no such key: apps/app2")
+
+	items, err := r.List("events")
+	require.NoError(t, err)
+	require.Equal(t, []string{"e1"}, items)
+
+	version, err := r.Version()
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	s := testStorage(t)
+
+	require.NoError(t, s.Write("apps/app1", []byte("1")))
+
+	require.Error(t, s.Restore(bytes.NewReader([]byte("invalid"))))
+
+	data, err := s.Read("apps/app1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), data)
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	ChangeDelete = "delete"
+	ChangeExpire = "expire"
+	ChangeWrite  = "write"
+)
+
+// watchBuffer is the number of changes a watcher can fall behind before
+// further changes to it are dropped
+const watchBuffer = 100
+
+type Change struct {
+	Key   string
+	Type  string
+	Value []byte // only set for writes
+}
+
+type watcher struct {
+	ch     chan Change
+	prefix string
+}
+
+// Watch returns a feed of committed changes to keys under prefix, call the
+// returned func to stop watching
+func (s *Storage) Watch(prefix string) (<-chan Change, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	w := &watcher{ch: make(chan Change, watchBuffer), prefix: prefix}
+
+	id := s.next
+	s.next++
+
+	s.watchers[id] = w
+
+	cancel := func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		if _, ok := s.watchers[id]; ok {
+			close(w.ch)
+			delete(s.watchers, id)
+		}
+	}
+
+	return w.ch, cancel
+}
+
+// notify sends a change to matching watchers once tx commits
+func (s *Storage) notify(tx *bolt.Tx, c Change) {
+	tx.OnCommit(func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		for _, w := range s.watchers {
+			if !strings.HasPrefix(c.Key, w.prefix) {
+				continue
+			}
+
+			select {
+			case w.ch <- c:
+			default:
+			}
+		}
+	})
+}
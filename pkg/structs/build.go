@@ -15,38 +15,75 @@ type Build struct {
 	Process        string `json:"process"`
 	Release        string `json:"release"`
 	Reason         string `json:"reason"`
+	Sbom           string `json:"sbom"`
 	Status         string `json:"status"`
 	WildcardDomain bool   `json:"wildcard-domain"`
 
 	Started time.Time `json:"started"`
 	Ended   time.Time `json:"ended"`
 
+	Transitions []BuildTransition `json:"transitions"`
+
 	Tags map[string]string `json:"-"`
 }
 
 type Builds []Build
 
+// BuildTransition records when a build moved to a status
+type BuildTransition struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
 type BuildCreateOptions struct {
 	BuildArgs      *[]string `flag:"build-args" param:"build-args"`
+	Concurrency    *int      `flag:"concurrency" param:"concurrency"`
 	Description    *string   `flag:"description,d" param:"description"`
 	Development    *bool     `flag:"development" param:"development"`
 	Manifest       *string   `flag:"manifest,m" param:"manifest"`
 	NoCache        *bool     `flag:"no-cache" param:"no-cache"`
+	SbomDenylist   *string   `flag:"sbom-denylist" param:"sbom-denylist"`
 	WildcardDomain *bool     `flag:"wildcard-domain" param:"wildcard-domain"`
 
 	GitSha *string `param:"git-sha"`
 }
 
+type BuildExportOptions struct {
+	Compression *string `flag:"compression" query:"compression"`
+}
+
+type BuildImportOptions struct {
+	Index  *string `query:"index"`
+	Verify *bool   `flag:"verify" query:"verify"`
+}
+
+// BuildImportIndex lists the objects a build archive was uploaded as, in the
+// order they make up the archive
+type BuildImportIndex struct {
+	Chunks []BuildImportChunk `json:"chunks"`
+}
+
+type BuildImportChunk struct {
+	Key    string `json:"key"`
+	Sha256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
 type BuildListOptions struct {
 	Limit *int `flag:"limit,l" query:"limit"`
 }
 
+type BuildSbomOptions struct {
+	Service *string `flag:"service,s" query:"service"`
+}
+
 type BuildUpdateOptions struct {
 	Ended      *time.Time `param:"ended"`
 	Entrypoint *string    `param:"entrypoint"`
 	Logs       *string    `param:"logs"`
 	Manifest   *string    `param:"manifest"`
 	Release    *string    `param:"release"`
+	Sbom       *string    `param:"sbom"`
 	Started    *time.Time `param:"started"`
 	Status     *string    `param:"status"`
 }
@@ -10,3 +10,31 @@ type Capacity struct {
 	ProcessMemory  int64 `json:"process-memory"`
 	ProcessWidth   int64 `json:"process-width"`
 }
+
+type CapacityPlan struct {
+	Fits              bool             `json:"fits"`
+	Instances         int              `json:"instances"`
+	InstancesRequired int              `json:"instances-required"`
+	Pending           CapacityPendings `json:"pending"`
+}
+
+// CapacityPending is a service with tasks that would not be placed on the
+// current instances, Oversized is set when a single task is larger than an
+// instance
+type CapacityPending struct {
+	App       string `json:"app"`
+	Service   string `json:"service"`
+	Count     int    `json:"count"`
+	Oversized bool   `json:"oversized"`
+}
+
+type CapacityPendings []CapacityPending
+
+type CapacityPlanOptions struct {
+	App     *string `query:"app"`
+	Count   *int    `flag:"count" query:"count"`
+	Cpu     *int    `flag:"cpu" query:"cpu"`
+	Memory  *int    `flag:"memory" query:"memory"`
+	Release *string `flag:"release" query:"release"`
+	Service *string `query:"service"`
+}
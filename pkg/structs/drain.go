@@ -0,0 +1,132 @@
+package structs
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+type Drain struct {
+	App       string   `json:"app"`
+	Name      string   `json:"name"`
+	Processes []string `json:"processes,omitempty"`
+	Regex     string   `json:"regex,omitempty"`
+	Services  []string `json:"services,omitempty"`
+	Type      string   `json:"type"`
+	Url       string   `json:"url"`
+
+	regex *regexp.Regexp
+}
+
+type Drains []Drain
+
+type DrainCreateOptions struct {
+	Processes *[]string `flag:"process" param:"processes"`
+	Regex     *string   `flag:"regex" param:"regex"`
+	Services  *[]string `flag:"service,s" param:"services"`
+}
+
+var drainName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+func (ds Drains) Less(i, j int) bool { return ds[i].Name < ds[j].Name }
+
+// NewDrain builds a drain, deriving its type from the url scheme
+func NewDrain(app, name, u string, opts DrainCreateOptions) (*Drain, error) {
+	if !drainName.MatchString(name) {
+		return nil, fmt.Errorf("invalid drain name: %s", name)
+	}
+
+	t, err := DrainType(u)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Drain{
+		App:  app,
+		Name: name,
+		Type: t,
+		Url:  u,
+	}
+
+	if opts.Processes != nil {
+		d.Processes = *opts.Processes
+	}
+
+	if opts.Regex != nil {
+		if _, err := regexp.Compile(*opts.Regex); err != nil {
+			return nil, fmt.Errorf("invalid regex: %s", err)
+		}
+		d.Regex = *opts.Regex
+	}
+
+	if opts.Services != nil {
+		d.Services = *opts.Services
+	}
+
+	return d, nil
+}
+
+// DrainType returns syslog, https or s3 for a drain url
+func DrainType(u string) (string, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+
+	if pu.Host == "" {
+		return "", fmt.Errorf("invalid drain url: %s", u)
+	}
+
+	switch pu.Scheme {
+	case "tcp", "tcp+tls", "udp":
+		return "syslog", nil
+	case "https":
+		return "https", nil
+	case "s3":
+		return "s3", nil
+	default:
+		return "", fmt.Errorf("invalid drain url scheme: %s", pu.Scheme)
+	}
+}
+
+// Match reports whether a log line from stream should be sent to the drain,
+// streams are named kind/service/process
+func (d *Drain) Match(stream, message string) (bool, error) {
+	parts := strings.SplitN(stream, "/", 3)
+
+	if len(d.Services) > 0 && (len(parts) < 2 || !contains(d.Services, parts[1])) {
+		return false, nil
+	}
+
+	if len(d.Processes) > 0 && (len(parts) < 3 || !contains(d.Processes, parts[2])) {
+		return false, nil
+	}
+
+	if d.Regex != "" {
+		// compiled on the first match and kept for the rest of the events
+		if d.regex == nil {
+			r, err := regexp.Compile(d.Regex)
+			if err != nil {
+				return false, err
+			}
+			d.regex = r
+		}
+
+		if !d.regex.MatchString(message) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,14 +1,53 @@
 package structs
 
-// type Event struct {
-//   Action    string            `json:"action"` // app:create, release:create, release:promote, etc.
-//   Status    string            `json:"status"` // success or error
-//   Data      map[string]string `json:"data"`   // {"rack": "example-rack", "app": "example-app", "id": "R123456789", "message": "unable to load release"}
-//   Timestamp time.Time         `json:"timestamp"`
-// }
+import (
+	"path"
+	"time"
+)
+
+type Event struct {
+	Id        string            `json:"id"`
+	Action    string            `json:"action"` // app:create, release:create, release:promote, etc.
+	Data      map[string]string `json:"data"`   // {"rack": "example-rack", "app": "example-app", "id": "R123456789", "message": "unable to load release"}
+	Status    string            `json:"status"` // start, success or error
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+type Events []Event
+
+type EventListOptions struct {
+	Action *string        `flag:"action" query:"action"`
+	App    *string        `flag:"app,a" query:"app"`
+	Limit  *int           `flag:"limit,l" query:"limit"`
+	Since  *time.Duration `default:"24h" flag:"since" query:"since"`
+	Status *string        `flag:"status" query:"status"`
+	Until  *time.Duration `flag:"until" query:"until"`
+}
 
 type EventSendOptions struct {
 	Data   map[string]string `param:"data"`
 	Error  *string           `param:"error"`
 	Status *string           `param:"status"`
 }
+
+// Match returns true if the event passes the action glob, app and status filters
+func (e Event) Match(opts EventListOptions) bool {
+	if opts.Action != nil {
+		if ok, _ := path.Match(*opts.Action, e.Action); !ok {
+			return false
+		}
+	}
+
+	if opts.App != nil && e.Data["app"] != *opts.App {
+		return false
+	}
+
+	if opts.Status != nil && e.Status != *opts.Status {
+		return false
+	}
+
+	return true
+}
+
+// oldest first
+func (es Events) Less(i, j int) bool { return es[i].Timestamp.Before(es[j].Timestamp) }
@@ -19,6 +19,11 @@ type Instance struct {
 
 type Instances []Instance
 
+type InstanceDrainOptions struct {
+	Terminate *bool `param:"terminate"`
+	Timeout   *int  `flag:"timeout" param:"timeout"`
+}
+
 type InstanceShellOptions struct {
 	Command *string `header:"Command"`
 	Height  *int    `header:"Height"`
@@ -2,9 +2,34 @@ package structs
 
 import "time"
 
+// LogsCursorPrefix is sent after the results of a search to let the client know a cursor for the next page is coming next
+const LogsCursorPrefix = "5B1C8E0D-6A2F-4C3B-9D7E-0F4A2B8C6E19:"
+
 type LogsOptions struct {
-	Filter *string        `flag:"filter" header:"Filter"`
-	Follow *bool          `header:"Follow"`
-	Prefix *bool          `header:"Prefix"`
-	Since  *time.Duration `default:"2m" flag:"since" header:"Since"`
+	Cursor  *string        `header:"Cursor"`
+	End     *time.Time     `header:"End"`
+	Fields  *[]string      `flag:"field" header:"Fields"`
+	Filter  *string        `flag:"filter" header:"Filter"`
+	Follow  *bool          `header:"Follow"`
+	Limit   *int           `header:"Limit"`
+	Prefix  *bool          `header:"Prefix"`
+	Process *string        `flag:"process" header:"Process"`
+	Regex   *string        `flag:"regex" header:"Regex"`
+	Service *string        `flag:"service" header:"Service"`
+	Since   *time.Duration `default:"2m" flag:"since" header:"Since"`
+	Start   *time.Time     `header:"Start"`
+	Until   *time.Duration `flag:"until" header:"Until"`
+}
+
+// Search returns true if the options select a bounded, paginated search
+func (o LogsOptions) Search() bool {
+	return o.Cursor != nil || o.End != nil || o.Limit != nil || o.Start != nil
+}
+
+type LogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	App       string    `json:"app"`
+	Service   string    `json:"service"`
+	Process   string    `json:"process"`
+	Message   string    `json:"message"`
 }
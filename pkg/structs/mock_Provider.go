@@ -171,6 +171,20 @@ func (_m *MockProvider) AppUpdate(name string, opts AppUpdateOptions) error {
 	return r0
 }
 
+// BuildCancel provides a mock function with given fields: app, id
+func (_m *MockProvider) BuildCancel(app string, id string) error {
+	ret := _m.Called(app, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(app, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BuildCreate provides a mock function with given fields: app, url, opts
 func (_m *MockProvider) BuildCreate(app string, url string, opts BuildCreateOptions) (*Build, error) {
 	ret := _m.Called(app, url, opts)
@@ -194,13 +208,13 @@ func (_m *MockProvider) BuildCreate(app string, url string, opts BuildCreateOpti
 	return r0, r1
 }
 
-// BuildExport provides a mock function with given fields: app, id, w
-func (_m *MockProvider) BuildExport(app string, id string, w io.Writer) error {
-	ret := _m.Called(app, id, w)
+// BuildExport provides a mock function with given fields: app, id, w, opts
+func (_m *MockProvider) BuildExport(app string, id string, w io.Writer, opts BuildExportOptions) error {
+	ret := _m.Called(app, id, w, opts)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, io.Writer) error); ok {
-		r0 = rf(app, id, w)
+	if rf, ok := ret.Get(0).(func(string, string, io.Writer, BuildExportOptions) error); ok {
+		r0 = rf(app, id, w, opts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -231,13 +245,13 @@ func (_m *MockProvider) BuildGet(app string, id string) (*Build, error) {
 	return r0, r1
 }
 
-// BuildImport provides a mock function with given fields: app, r
-func (_m *MockProvider) BuildImport(app string, r io.Reader) (*Build, error) {
-	ret := _m.Called(app, r)
+// BuildImport provides a mock function with given fields: app, r, opts
+func (_m *MockProvider) BuildImport(app string, r io.Reader, opts BuildImportOptions) (*Build, error) {
+	ret := _m.Called(app, r, opts)
 
 	var r0 *Build
-	if rf, ok := ret.Get(0).(func(string, io.Reader) *Build); ok {
-		r0 = rf(app, r)
+	if rf, ok := ret.Get(0).(func(string, io.Reader, BuildImportOptions) *Build); ok {
+		r0 = rf(app, r, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*Build)
@@ -245,8 +259,8 @@ func (_m *MockProvider) BuildImport(app string, r io.Reader) (*Build, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, io.Reader) error); ok {
-		r1 = rf(app, r)
+	if rf, ok := ret.Get(1).(func(string, io.Reader, BuildImportOptions) error); ok {
+		r1 = rf(app, r, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -300,6 +314,29 @@ func (_m *MockProvider) BuildLogs(app string, id string, opts LogsOptions) (io.R
 	return r0, r1
 }
 
+// BuildSbom provides a mock function with given fields: app, id, opts
+func (_m *MockProvider) BuildSbom(app string, id string, opts BuildSbomOptions) (io.ReadCloser, error) {
+	ret := _m.Called(app, id, opts)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, string, BuildSbomOptions) io.ReadCloser); ok {
+		r0 = rf(app, id, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, BuildSbomOptions) error); ok {
+		r1 = rf(app, id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BuildUpdate provides a mock function with given fields: app, id, opts
 func (_m *MockProvider) BuildUpdate(app string, id string, opts BuildUpdateOptions) (*Build, error) {
 	ret := _m.Called(app, id, opts)
@@ -346,6 +383,29 @@ func (_m *MockProvider) CapacityGet() (*Capacity, error) {
 	return r0, r1
 }
 
+// CapacityPlan provides a mock function with given fields: opts
+func (_m *MockProvider) CapacityPlan(opts CapacityPlanOptions) (*CapacityPlan, error) {
+	ret := _m.Called(opts)
+
+	var r0 *CapacityPlan
+	if rf, ok := ret.Get(0).(func(CapacityPlanOptions) *CapacityPlan); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*CapacityPlan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(CapacityPlanOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CertificateApply provides a mock function with given fields: app, service, port, id
 func (_m *MockProvider) CertificateApply(app string, service string, port int, id string) error {
 	ret := _m.Called(app, service, port, id)
@@ -457,6 +517,103 @@ func (_m *MockProvider) DeleteDB(resource string) error {
 	return r0
 }
 
+// DrainCreate provides a mock function with given fields: app, name, url, opts
+func (_m *MockProvider) DrainCreate(app string, name string, url string, opts DrainCreateOptions) (*Drain, error) {
+	ret := _m.Called(app, name, url, opts)
+
+	var r0 *Drain
+	if rf, ok := ret.Get(0).(func(string, string, string, DrainCreateOptions) *Drain); ok {
+		r0 = rf(app, name, url, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Drain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string, DrainCreateOptions) error); ok {
+		r1 = rf(app, name, url, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DrainDelete provides a mock function with given fields: app, name
+func (_m *MockProvider) DrainDelete(app string, name string) error {
+	ret := _m.Called(app, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(app, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DrainList provides a mock function with given fields: app
+func (_m *MockProvider) DrainList(app string) (Drains, error) {
+	ret := _m.Called(app)
+
+	var r0 Drains
+	if rf, ok := ret.Get(0).(func(string) Drains); ok {
+		r0 = rf(app)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Drains)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(app)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DrainTest provides a mock function with given fields: app, name
+func (_m *MockProvider) DrainTest(app string, name string) error {
+	ret := _m.Called(app, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(app, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EventList provides a mock function with given fields: opts
+func (_m *MockProvider) EventList(opts EventListOptions) (Events, error) {
+	ret := _m.Called(opts)
+
+	var r0 Events
+	if rf, ok := ret.Get(0).(func(EventListOptions) Events); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Events)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(EventListOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // EventSend provides a mock function with given fields: action, opts
 func (_m *MockProvider) EventSend(action string, opts EventSendOptions) error {
 	ret := _m.Called(action, opts)
@@ -536,6 +693,20 @@ func (_m *MockProvider) Initialize(opts ProviderOptions) error {
 	return r0
 }
 
+// InstanceDrain provides a mock function with given fields: id, opts
+func (_m *MockProvider) InstanceDrain(id string, opts InstanceDrainOptions) error {
+	ret := _m.Called(id, opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, InstanceDrainOptions) error); ok {
+		r0 = rf(id, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InstanceKeyroll provides a mock function with given fields:
 func (_m *MockProvider) InstanceKeyroll() error {
 	ret := _m.Called()
@@ -1567,6 +1738,89 @@ func (_m *MockProvider) SystemUpdate(opts SystemUpdateOptions) error {
 	return r0
 }
 
+// SystemWorkers provides a mock function with given fields: 
+func (_m *MockProvider) SystemWorkers() (Workers, error) {
+	ret := _m.Called()
+
+	var r0 Workers
+	if rf, ok := ret.Get(0).(func() Workers); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Workers)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookDeliveryList provides a mock function with given fields: name, opts
+func (_m *MockProvider) WebhookDeliveryList(name string, opts WebhookDeliveryListOptions) (WebhookDeliveries, error) {
+	ret := _m.Called(name, opts)
+
+	var r0 WebhookDeliveries
+	if rf, ok := ret.Get(0).(func(string, WebhookDeliveryListOptions) WebhookDeliveries); ok {
+		r0 = rf(name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(WebhookDeliveries)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, WebhookDeliveryListOptions) error); ok {
+		r1 = rf(name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookPreview provides a mock function with given fields: name, opts
+func (_m *MockProvider) WebhookPreview(name string, opts WebhookPreviewOptions) (*WebhookPreview, error) {
+	ret := _m.Called(name, opts)
+
+	var r0 *WebhookPreview
+	if rf, ok := ret.Get(0).(func(string, WebhookPreviewOptions) *WebhookPreview); ok {
+		r0 = rf(name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WebhookPreview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, WebhookPreviewOptions) error); ok {
+		r1 = rf(name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookRedeliver provides a mock function with given fields: name, id
+func (_m *MockProvider) WebhookRedeliver(name string, id string) error {
+	ret := _m.Called(name, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WithContext provides a mock function with given fields: ctx
 func (_m *MockProvider) WithContext(ctx context.Context) Provider {
 	ret := _m.Called(ctx)
@@ -17,15 +17,18 @@ type Provider interface {
 	AppMetrics(name string, opts MetricsOptions) (Metrics, error)
 	AppUpdate(name string, opts AppUpdateOptions) error
 
+	BuildCancel(app, id string) error
 	BuildCreate(app, url string, opts BuildCreateOptions) (*Build, error)
-	BuildExport(app, id string, w io.Writer) error
+	BuildExport(app, id string, w io.Writer, opts BuildExportOptions) error
 	BuildGet(app, id string) (*Build, error)
-	BuildImport(app string, r io.Reader) (*Build, error)
+	BuildImport(app string, r io.Reader, opts BuildImportOptions) (*Build, error)
 	BuildLogs(app, id string, opts LogsOptions) (io.ReadCloser, error)
 	BuildList(app string, opts BuildListOptions) (Builds, error)
+	BuildSbom(app, id string, opts BuildSbomOptions) (io.ReadCloser, error)
 	BuildUpdate(app, id string, opts BuildUpdateOptions) (*Build, error)
 
 	CapacityGet() (*Capacity, error)
+	CapacityPlan(opts CapacityPlanOptions) (*CapacityPlan, error)
 
 	CertificateApply(app, service string, port int, id string) error
 	CertificateCreate(pub, key string, opts CertificateCreateOptions) (*Certificate, error)
@@ -33,12 +36,19 @@ type Provider interface {
 	CertificateGenerate(domains []string) (*Certificate, error)
 	CertificateList() (Certificates, error)
 
+	DrainCreate(app, name, url string, opts DrainCreateOptions) (*Drain, error)
+	DrainDelete(app, name string) error
+	DrainList(app string) (Drains, error)
+	DrainTest(app, name string) error
+
+	EventList(opts EventListOptions) (Events, error)
 	EventSend(action string, opts EventSendOptions) error
 
 	FilesDelete(app, pid string, files []string) error
 	FilesDownload(app, pid string, file string) (io.Reader, error)
 	FilesUpload(app, pid string, r io.Reader) error
 
+	InstanceDrain(id string, opts InstanceDrainOptions) error
 	InstanceKeyroll() error
 	InstanceList() (Instances, error)
 	InstanceShell(id string, rw io.ReadWriter, opts InstanceShellOptions) (int, error)
@@ -98,8 +108,14 @@ type Provider interface {
 	SystemResourceUpdate(name string, opts ResourceUpdateOptions) (*Resource, error)
 	SystemUninstall(name string, w io.Writer, opts SystemUninstallOptions) error
 	SystemUpdate(opts SystemUpdateOptions) error
+	SystemWorkers() (Workers, error)
 	Sync(string) error
 	SyncInstancesIpInSecurityGroup() error
+
+	WebhookDeliveryList(name string, opts WebhookDeliveryListOptions) (WebhookDeliveries, error)
+	WebhookPreview(name string, opts WebhookPreviewOptions) (*WebhookPreview, error)
+	WebhookRedeliver(name, id string) error
+
 	WithContext(ctx context.Context) Provider
 	Workers() error
 }
@@ -12,23 +12,32 @@ func init() {
 	routes["AppLogs"] = "SOCKET /apps/{name}/logs"
 	routes["AppMetrics"] = "GET /apps/{name}/metrics"
 	routes["AppUpdate"] = "PUT /apps/{name}"
+	routes["BuildCancel"] = "POST /apps/{app}/builds/{id}/cancel"
 	routes["BuildCreate"] = "POST /apps/{app}/builds"
 	routes["BuildExport"] = "GET /apps/{app}/builds/{id}.tgz"
 	routes["BuildGet"] = "GET /apps/{app}/builds/{id}"
 	routes["BuildImport"] = "POST /apps/{app}/builds/import"
 	routes["BuildLogs"] = "SOCKET /apps/{app}/builds/{id}/logs"
 	routes["BuildList"] = "GET /apps/{app}/builds"
+	routes["BuildSbom"] = "GET /apps/{app}/builds/{id}/sbom"
 	routes["BuildUpdate"] = "PUT /apps/{app}/builds/{id}"
 	routes["CapacityGet"] = "GET /system/capacity"
+	routes["CapacityPlan"] = "GET /system/capacity/plan"
 	routes["CertificateApply"] = "PUT /apps/{app}/ssl/{service}/{port}"
 	routes["CertificateCreate"] = "POST /certificates"
 	routes["CertificateDelete"] = "DELETE /certificates/{id}"
 	routes["CertificateGenerate"] = "POST /certificates/generate"
 	routes["CertificateList"] = "GET /certificates"
+	routes["DrainCreate"] = "POST /apps/{app}/drains"
+	routes["DrainDelete"] = "DELETE /apps/{app}/drains/{name}"
+	routes["DrainList"] = "GET /apps/{app}/drains"
+	routes["DrainTest"] = "POST /apps/{app}/drains/{name}/test"
+	routes["EventList"] = "GET /events"
 	routes["EventSend"] = "POST /events"
 	routes["FilesDelete"] = "DELETE /apps/{app}/processes/{pid}/files"
 	routes["FilesDownload"] = "GET /apps/{app}/processes/{pid}/files"
 	routes["FilesUpload"] = "POST /apps/{app}/processes/{pid}/files"
+	routes["InstanceDrain"] = "POST /instances/{id}/drain"
 	routes["InstanceKeyroll"] = "POST /instances/keyroll"
 	routes["InstanceList"] = "GET /instances"
 	routes["InstanceShell"] = "SOCKET /instances/{id}/shell"
@@ -74,6 +83,10 @@ func init() {
 	routes["SystemResourceUpdate"] = "PUT /resources/{name}"
 	routes["SystemUninstall"] = ""
 	routes["SystemUpdate"] = "PUT /system"
+	routes["SystemWorkers"] = "GET /system/workers"
+	routes["WebhookDeliveryList"] = "GET /resources/{name}/deliveries"
+	routes["WebhookPreview"] = "POST /resources/{name}/preview"
+	routes["WebhookRedeliver"] = "POST /resources/{name}/deliveries/{id}/redeliver"
 	routes["Workers"] = ""
 }
 
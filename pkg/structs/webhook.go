@@ -0,0 +1,46 @@
+package structs
+
+import "time"
+
+type WebhookAttempt struct {
+	Attempt  int           `json:"attempt"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+	Status   int           `json:"status"`
+	Time     time.Time     `json:"time"`
+}
+
+type WebhookDelivery struct {
+	Id         string           `json:"id"`
+	Action     string           `json:"action"`
+	Attempts   []WebhookAttempt `json:"attempts"`
+	Created    time.Time        `json:"created"`
+	Event      string           `json:"event"`
+	Redelivery string           `json:"redelivery,omitempty"`
+	Status     string           `json:"status"`
+	Updated    time.Time        `json:"updated"`
+}
+
+type WebhookDeliveries []WebhookDelivery
+
+type WebhookPreview struct {
+	ContentType string `json:"content-type"`
+	Matched     bool   `json:"matched"`
+	Payload     string `json:"payload"`
+}
+
+type WebhookPreviewOptions struct {
+	Action   *string `flag:"action" param:"action"`
+	App      *string `flag:"app,a" param:"app"`
+	Filter   *string `flag:"filter" param:"filter"`
+	Status   *string `flag:"status" param:"status"`
+	Template *string `flag:"template" param:"template"`
+}
+
+type WebhookDeliveryListOptions struct {
+	Limit  *int    `flag:"limit,l" query:"limit"`
+	Status *string `flag:"status" query:"status"`
+}
+
+// newest first
+func (ds WebhookDeliveries) Less(i, j int) bool { return ds[i].Created.After(ds[j].Created) }
@@ -0,0 +1,21 @@
+package structs
+
+import "time"
+
+// Worker is the state of a provider background job
+type Worker struct {
+	Name        string        `json:"name"`
+	Disabled    bool          `json:"disabled"`
+	Duration    time.Duration `json:"duration"` // of the last run
+	Error       string        `json:"error,omitempty"`
+	Failures    int           `json:"failures"`
+	Interval    time.Duration `json:"interval"`
+	LastRun     time.Time     `json:"last-run"`
+	LastSuccess time.Time     `json:"last-success"`
+	NextRun     time.Time     `json:"next-run"`
+	Running     bool          `json:"running"`
+	Runs        int           `json:"runs"`
+	Timeout     time.Duration `json:"timeout"`
+}
+
+type Workers []Worker
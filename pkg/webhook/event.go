@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+)
+
+// Event is the message published to the rack notification topic, it matches
+// the event struct in provider/aws so templates see the same fields
+type Event struct {
+	Action    string            `json:"action"`
+	Data      map[string]string `json:"data"`
+	Status    string            `json:"status"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// App returns the app an event is about, app events name the app in the name field
+func (e Event) App() string {
+	if e.Data["app"] != "" {
+		return e.Data["app"]
+	}
+
+	if strings.HasPrefix(e.Action, "app:") {
+		return e.Data["name"]
+	}
+
+	return ""
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Filter selects the events sent to a webhook, values for the same key are
+// alternatives and every key with values must match
+type Filter struct {
+	Actions  []string
+	Apps     []string
+	Statuses []string
+}
+
+// ParseFilter parses a comma separated filter like action=release:*,status=error,app=web-*
+// where each value is a glob
+func ParseFilter(s string) (*Filter, error) {
+	f := &Filter{}
+
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+
+		if term == "" {
+			continue
+		}
+
+		parts := strings.SplitN(term, "=", 2)
+
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid filter: %s", term)
+		}
+
+		if _, err := path.Match(parts[1], ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern: %s", parts[1])
+		}
+
+		switch parts[0] {
+		case "action":
+			f.Actions = append(f.Actions, parts[1])
+		case "app":
+			f.Apps = append(f.Apps, parts[1])
+		case "status":
+			f.Statuses = append(f.Statuses, parts[1])
+		default:
+			return nil, fmt.Errorf("invalid filter key: %s", parts[0])
+		}
+	}
+
+	return f, nil
+}
+
+// Match returns true if the event should be delivered
+func (f *Filter) Match(e Event) bool {
+	return matchAny(f.Actions, e.Action) && matchAny(f.Apps, e.App()) && matchAny(f.Statuses, e.Status)
+}
+
+func matchAny(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+
+	return false
+}
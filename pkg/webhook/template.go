@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Templates are the built in payload formats, any other value is parsed as a template
+var Templates = map[string]string{
+	"json":  `{{ json . }}`,
+	"slack": `{"text":{{ json (summary .) }}}`,
+	"text":  `{{ summary . }}`,
+}
+
+// NewTemplate returns the named built in template or parses s as a text/template,
+// an empty string selects json
+func NewTemplate(s string) (*template.Template, error) {
+	if s == "" {
+		s = "json"
+	}
+
+	if t, ok := Templates[s]; ok {
+		s = t
+	}
+
+	t, err := template.New("payload").Funcs(templateHelpers()).Option("missingkey=zero").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %s", err)
+	}
+
+	return t, nil
+}
+
+// Render executes a payload template for an event
+func Render(t *template.Template, e Event) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := t.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ContentType returns the content type for a rendered payload
+func ContentType(payload []byte) string {
+	if json.Valid(payload) {
+		return "application/json"
+	}
+
+	return "text/plain"
+}
+
+// Summary describes an event on one line, e.g. [rack1] release:promote success app=app1 id=R1234
+func Summary(e Event) string {
+	keys := []string{}
+
+	for k := range e.Data {
+		if k != "rack" {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	parts := []string{fmt.Sprintf("[%s]", e.Data["rack"]), e.Action, e.Status}
+
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, e.Data[k]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func templateHelpers() template.FuncMap {
+	return template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"lower":   strings.ToLower,
+		"summary": Summary,
+		"upper":   strings.ToUpper,
+	}
+}
@@ -0,0 +1,86 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+var fxEvent = webhook.Event{
+	Action:    "release:promote",
+	Data:      map[string]string{"app": "app1", "id": "R1234", "rack": "rack1"},
+	Status:    "error",
+	Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestFilter(t *testing.T) {
+	cases := map[string]bool{
+		"":                                  true,
+		"action=release:*":                  true,
+		"action=build:*":                    false,
+		"action=build:*,action=release:*":   true,
+		"status=error":                      true,
+		"status=success":                    false,
+		"app=app*,status=error":             true,
+		"app=app2, action=release:promote":  false,
+		"action=release:promote,app=app1  ": true,
+	}
+
+	for s, want := range cases {
+		f, err := webhook.ParseFilter(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, f.Match(fxEvent), s)
+	}
+}
+
+func TestFilterAppEvents(t *testing.T) {
+	f, err := webhook.ParseFilter("app=app1")
+	require.NoError(t, err)
+
+	require.True(t, f.Match(webhook.Event{Action: "app:create", Data: map[string]string{"name": "app1"}}))
+	require.False(t, f.Match(webhook.Event{Action: "resource:create", Data: map[string]string{"name": "app1"}}))
+}
+
+func TestFilterInvalid(t *testing.T) {
+	_, err := webhook.ParseFilter("action")
+	require.EqualError(t, err, "invalid filter: action")
+
+	_, err = webhook.ParseFilter("service=web")
+	require.EqualError(t, err, "invalid filter key: service")
+
+	_, err = webhook.ParseFilter("action=[")
+	require.EqualError(t, err, "invalid filter pattern: [")
+}
+
+func TestTemplates(t *testing.T) {
+	cases := map[string]string{
+		"":      `{"action":"release:promote","data":{"app":"app1","id":"R1234","rack":"rack1"},"status":"error","timestamp":"2021-01-02T03:04:05Z"}`,
+		"json":  `{"action":"release:promote","data":{"app":"app1","id":"R1234","rack":"rack1"},"status":"error","timestamp":"2021-01-02T03:04:05Z"}`,
+		"slack": `{"text":"[rack1] release:promote error app=app1 id=R1234"}`,
+		"text":  `[rack1] release:promote error app=app1 id=R1234`,
+		`{"summary":{{ json (printf "%s failed" .Action) }},"severity":"{{ if eq .Status "error" }}critical{{ else }}info{{ end }}","app":"{{ index .Data "app" }}"}`: `{"summary":"release:promote failed","severity":"critical","app":"app1"}`,
+		`{{ upper .Status }} {{ .Data.missing }}`: `ERROR `,
+	}
+
+	for s, want := range cases {
+		tmpl, err := webhook.NewTemplate(s)
+		require.NoError(t, err, s)
+
+		data, err := webhook.Render(tmpl, fxEvent)
+		require.NoError(t, err, s)
+		require.Equal(t, want, string(data), s)
+	}
+}
+
+func TestTemplateInvalid(t *testing.T) {
+	_, err := webhook.NewTemplate("{{ .Action ")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid template: ")
+}
+
+func TestContentType(t *testing.T) {
+	require.Equal(t, "application/json", webhook.ContentType([]byte(`{"text":"hi"}`)))
+	require.Equal(t, "text/plain", webhook.ContentType([]byte(`hi`)))
+}
@@ -388,6 +388,14 @@ func (p *Provider) cleanup(app *structs.App) error {
 		fmt.Printf("fn=cleanup level=error msg=\"error deleting ecr repo: %s\"", err)
 	}
 
+	if _, err := p.pruneBuildCache(reg, map[string]bool{}, time.Now().UTC()); err != nil {
+		fmt.Printf("fn=cleanup level=error msg=\"error deleting build cache: %s\"", err)
+	}
+
+	if err := p.drainsDeleteAll(app.Name); err != nil {
+		fmt.Printf("fn=cleanup level=error msg=\"error deleting drains: %s\"", err)
+	}
+
 	err = p.releaseDeleteAll(app.Name)
 	if err != nil {
 		fmt.Printf("fn=cleanup level=error msg=\"%s\"", err)
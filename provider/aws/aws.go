@@ -59,12 +59,18 @@ type Provider struct {
 	CustomEncryptionKey               string
 	Development                       bool
 	DynamoBuilds                      string
+	DynamoEvents                      string
+	DynamoLeases                      string
 	DynamoReleases                    string
 	DockerTLS                         *structs.TLSPemCertBytes
+	DrainFunction                     string
 	EcsPollInterval                   int
 	EncryptionKey                     string
 	Fargate                           bool
 	HighAvailability                  bool
+	ImageSignatureRequired            bool
+	ImageSigningKey                   string
+	ImageSigningTrustedKeys           string
 	InstancesIpToIncludInWhiteListing string
 	Internal                          bool
 	InternalOnly                      bool
@@ -97,6 +103,7 @@ type Provider struct {
 	Vpc                               string
 	VpcCidr                           string
 	WhiteListSpecified                bool
+	WorkerIntervals                   string
 	MaintainTimerState                bool
 	Metrics                           *metrics.Metrics
 	SkipCache                         bool
@@ -165,12 +172,18 @@ func (p *Provider) loadParams() error {
 	p.CloudformationTopic = labels["rack.CloudformationTopic"]
 	p.Cluster = labels["rack.Cluster"]
 	p.CustomEncryptionKey = labels["rack.CustomEncryptionKey"]
+	p.DrainFunction = labels["rack.DrainFunction"]
 	p.DynamoBuilds = labels["rack.DynamoBuilds"]
+	p.DynamoEvents = labels["rack.DynamoEvents"]
+	p.DynamoLeases = labels["rack.DynamoLeases"]
 	p.DynamoReleases = labels["rack.DynamoReleases"]
 	p.EcsPollInterval = intParam(labels["rack.EcsPollInterval"], 1)
 	p.EncryptionKey = labels["rack.EncryptionKey"]
 	p.Fargate = labels["rack.Fargate"] == "Yes"
 	p.HighAvailability = labels["rack.HighAvailability"] == "true"
+	p.ImageSignatureRequired = labels["rack.ImageSignatureRequired"] == "Yes"
+	p.ImageSigningKey = labels["rack.ImageSigningKey"]
+	p.ImageSigningTrustedKeys = labels["rack.ImageSigningTrustedKeys"]
 	p.InstancesIpToIncludInWhiteListing = labels["rack.InstancesIpToIncludInWhiteListing"]
 	p.Internal = labels["rack.Internal"] == "Yes"
 	p.InternalOnly = labels["rack.InternalOnly"] == "Yes"
@@ -199,6 +212,7 @@ func (p *Provider) loadParams() error {
 	p.Vpc = labels["rack.Vpc"]
 	p.VpcCidr = labels["rack.VpcCidr"]
 	p.WhiteListSpecified = labels["rack.WhiteListSpecified"] == "Yes"
+	p.WorkerIntervals = labels["rack.WorkerIntervals"]
 
 	if v, has := labels["rack.DockerTlsCA"]; has && len(v) > 0 {
 		cacert, err := base64.StdEncoding.DecodeString(labels["rack.DockerTlsCA"])
@@ -3,7 +3,7 @@ package aws
 import (
 	"archive/tar"
 	"bytes"
-	"compress/gzip"
+	"crypto/ecdsa"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -18,14 +18,17 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/rack/pkg/helpers"
 	"github.com/convox/rack/pkg/manifest"
 	"github.com/convox/rack/pkg/manifest1"
 	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/signing"
 	"github.com/convox/rack/pkg/structs"
 	docker "github.com/fsouza/go-dockerclient"
 )
@@ -37,10 +40,28 @@ var regexpECRImage = regexp.MustCompile(`(\d+)\.dkr\.ecr\.([^.]+)\.amazonaws\.co
 // StackID is formatted like arn:aws:cloudformation:us-east-1:332653055745:stack/dev/d164aa20-ba89-11e6-b65c-50d5ca632656
 var regexpStackID = regexp.MustCompile(`arn:[^:]+:cloudformation:([^.]+):(\d+):stack/([^.]+)/([^.]+)`)
 
+// BuildCancel stops a queued or running build
+func (p *Provider) BuildCancel(app, id string) error {
+	log := Logger.At("BuildCancel").Namespace("app=%q id=%q", app, id).Start()
+
+	b, err := p.BuildGet(app, id)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	if err := p.cancelBuild(b, "cancelled"); err != nil {
+		return log.Error(err)
+	}
+
+	p.buildFinished(app)
+
+	return log.Success()
+}
+
 func (p *Provider) BuildCreate(app, url string, opts structs.BuildCreateOptions) (*structs.Build, error) {
 	log := Logger.At("BuildCreate").Namespace("app=%q url=%q", app, url).Start()
 
-	_, err := p.AppGet(app)
+	a, err := p.AppGet(app)
 	if err != nil {
 		log.Error(err)
 		return nil, err
@@ -48,6 +69,8 @@ func (p *Provider) BuildCreate(app, url string, opts structs.BuildCreateOptions)
 
 	b := structs.NewBuild(app)
 
+	setBuildStatus(b, b.Status)
+
 	if opts.Description != nil {
 		b.Description = *opts.Description
 	}
@@ -73,6 +96,42 @@ func (p *Provider) BuildCreate(app, url string, opts structs.BuildCreateOptions)
 		return nil, err
 	}
 
+	switch buildQueue(a) {
+	case buildQueueQueue:
+		// every build goes through the queue so only the oldest queued build
+		// is started, by whichever process claims it first
+		if err := p.queueBuild(b, url, opts); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+
+		if err := p.startQueuedBuild(app); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+
+		b, err := p.BuildGet(app, b.Id)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+
+		return b, log.Success()
+	case buildQueueSupersede:
+		active, err := p.activeBuilds(app)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+
+		for i := range active {
+			if err := p.cancelBuild(&active[i], fmt.Sprintf("superseded by %s", b.Id)); err != nil {
+				log.Error(err)
+				return nil, err
+			}
+		}
+	}
+
 	if err := p.runBuild(b, url, opts); err != nil {
 		log.Error(err)
 		return nil, err
@@ -82,7 +141,7 @@ func (p *Provider) BuildCreate(app, url string, opts structs.BuildCreateOptions)
 }
 
 // BuildExport exports a build artifact
-func (p *Provider) BuildExport(app, id string, w io.Writer) error {
+func (p *Provider) BuildExport(app, id string, w io.Writer, opts structs.BuildExportOptions) error {
 	log := Logger.At("BuildExport").Start()
 
 	build, err := p.BuildGet(app, id)
@@ -144,32 +203,45 @@ func (p *Provider) BuildExport(app, id string, w io.Writer) error {
 		return err
 	}
 
-	gz := gzip.NewWriter(w)
-	tw := tar.NewWriter(gz)
-
-	dataHeader := &tar.Header{
-		Typeflag: tar.TypeReg,
-		Name:     "build.json",
-		Mode:     0600,
-		Size:     int64(len(bjson)),
+	cw, err := newBuildArchiveWriter(w, helpers.DefaultString(opts.Compression, buildArchiveGzip))
+	if err != nil {
+		log.Error(err)
+		return err
 	}
 
-	if err := tw.WriteHeader(dataHeader); err != nil {
+	tw := tar.NewWriter(cw)
+
+	em := &exportManifest{Files: map[string]exportFile{}}
+
+	if err := writeArchiveFile(tw, em, "build.json", bytes.NewReader(bjson), int64(len(bjson))); err != nil {
 		log.Error(err)
 		return err
 	}
 
-	if _, err := tw.Write(bjson); err != nil {
+	repo, err := p.appRepository(build.App)
+	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	repo, err := p.appRepository(build.App)
+	signatures, err := p.buildSignatures(repo.Name, build.Id, services)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
+	if len(signatures) > 0 {
+		sjson, err := json.MarshalIndent(signatures, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := writeArchiveFile(tw, em, "signatures.json", bytes.NewReader(sjson), int64(len(sjson))); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
 	if err := p.dockerLogin(); err != nil {
 		log.Error(err)
 		return err
@@ -233,26 +305,36 @@ func (p *Provider) BuildExport(app, id string, w io.Writer) error {
 		return err
 	}
 
-	header := &tar.Header{
-		Typeflag: tar.TypeReg,
-		Name:     name,
-		Mode:     0600,
-		Size:     stat.Size(),
+	fd, err := os.Open(file)
+	if err != nil {
+		log.Error(err)
+		return err
 	}
+	defer fd.Close()
 
-	if err := tw.WriteHeader(header); err != nil {
+	log.Step("layers").Logf("file=%q", file)
+	_, layers, err := readImageArchive(fd)
+	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	fd, err := os.Open(file)
-	if err != nil {
+	if _, err := fd.Seek(0, io.SeekStart); err != nil {
 		log.Error(err)
 		return err
 	}
 
 	log.Step("copy").Logf("file=%q", file)
-	if _, err := io.Copy(tw, fd); err != nil {
+	if err := writeArchiveFile(tw, em, name, fd, stat.Size()); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	f := em.Files[name]
+	f.Layers = layers
+	em.Files[name] = f
+
+	if err := writeExportManifest(tw, em); err != nil {
 		log.Error(err)
 		return err
 	}
@@ -262,7 +344,7 @@ func (p *Provider) BuildExport(app, id string, w io.Writer) error {
 		return err
 	}
 
-	if err := gz.Close(); err != nil {
+	if err := cw.Close(); err != nil {
 		log.Error(err)
 		return err
 	}
@@ -295,7 +377,7 @@ func (p *Provider) BuildGet(app, id string) (*structs.Build, error) {
 }
 
 // BuildImport imports a build artifact
-func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error) {
+func (p *Provider) BuildImport(app string, r io.Reader, opts structs.BuildImportOptions) (*structs.Build, error) {
 	log := Logger.At("BuildImport").Namespace("app=%s", app).Start()
 
 	var sourceBuild structs.Build
@@ -319,16 +401,56 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 		return nil, err
 	}
 
-	gz, err := gzip.NewReader(r)
+	// an archive uploaded in chunks is read back from them, and the chunks
+	// are only removed once the import succeeds so that it can be retried
+	var index *structs.BuildImportIndex
+
+	if opts.Index != nil {
+		i, cr, err := p.importChunks(app, *opts.Index)
+		if err != nil {
+			return nil, log.Error(err)
+		}
+		defer cr.Close()
+
+		index = i
+		r = cr
+	}
+
+	if opts.Verify != nil && *opts.Verify {
+		fd, err := os.CreateTemp("", "")
+		if err != nil {
+			return nil, log.Error(err)
+		}
+		defer os.Remove(fd.Name())
+		defer fd.Close()
+
+		log.Step("verify").Logf("file=%q", fd.Name())
+		if err := verifyBuildArchive(io.TeeReader(r, fd)); err != nil {
+			return nil, log.Error(fmt.Errorf("build archive is corrupt: %s", err))
+		}
+
+		if _, err := fd.Seek(0, io.SeekStart); err != nil {
+			return nil, log.Error(err)
+		}
+
+		r = fd
+	}
+
+	ar, err := openBuildArchive(r)
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
+	defer ar.Close()
 
-	tr := tar.NewReader(gz)
+	tr := tar.NewReader(ar)
 
+	var em *exportManifest
 	var manifest imageManifest
 
+	files := map[string]exportFile{}
+	signatures := map[string]exportedSignatures{}
+
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -342,9 +464,20 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 			continue
 		}
 
+		if header.Name == exportManifestName {
+			if err := json.NewDecoder(tr).Decode(&em); err != nil {
+				return nil, log.Error(fmt.Errorf("%s: %s", exportManifestName, err))
+			}
+			continue
+		}
+
+		dr := newDigestReader(tr)
+
+		var layers map[string]string
+
 		if header.Name == "build.json" {
 			var buf bytes.Buffer
-			io.Copy(&buf, tr)
+			io.Copy(&buf, dr)
 
 			if err := json.Unmarshal(buf.Bytes(), &sourceBuild); err != nil {
 				log.Error(err)
@@ -354,13 +487,20 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 			targetBuild.Id = generateId("B", 10)
 		}
 
+		if header.Name == "signatures.json" {
+			if err := json.NewDecoder(dr).Decode(&signatures); err != nil {
+				log.Error(err)
+				return nil, err
+			}
+		}
+
 		if strings.HasSuffix(header.Name, ".tar") {
 			log.Step("load").Logf("tar=%q", header.Name)
 
 			cmd := exec.Command("docker", "load")
 
 			pr, pw := io.Pipe()
-			tee := io.TeeReader(tr, pw)
+			tee := io.TeeReader(dr, pw)
 			outb := &bytes.Buffer{}
 
 			cmd.Stdin = pr
@@ -372,12 +512,17 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 			}
 
 			log.Step("manifest").Logf("tar=%q", header.Name)
-			manifest, err = extractImageManifest(tee)
+			manifest, layers, err = readImageArchive(tee)
 			if err != nil {
 				log.Error(err)
 				return nil, err
 			}
 
+			if _, err := io.Copy(io.Discard, tee); err != nil {
+				log.Error(err)
+				return nil, err
+			}
+
 			if err := pw.Close(); err != nil {
 				log.Error(err)
 				return nil, err
@@ -393,6 +538,37 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 				return nil, fmt.Errorf("invalid image manifest: no data")
 			}
 		}
+
+		if _, err := io.Copy(io.Discard, dr); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+
+		f := dr.file()
+		f.Layers = layers
+		files[header.Name] = f
+	}
+
+	// archives exported before the manifest was added can not be checked.
+	// without verify the images are already loaded into the local docker
+	// daemon by now, but none is tagged or pushed and no build is recorded
+	// unless the check passes
+	if em != nil {
+		log.Step("check").Logf("files=%d", len(files))
+		if err := em.verify(files); err != nil {
+			return nil, log.Error(fmt.Errorf("build archive is corrupt: %s", err))
+		}
+	}
+
+	// signatures carried in the archive are checked against the keys the
+	// rack trusts, and only images that pass are signed again once pushed
+	var keys []*ecdsa.PublicKey
+
+	if len(signatures) > 0 || p.ImageSignatureRequired {
+		keys, err = p.imageSigningKeys()
+		if err != nil {
+			return nil, log.Error(err)
+		}
 	}
 
 	for _, tags := range manifest {
@@ -401,6 +577,20 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 			ps := strings.Split(fps, ".")[0]
 			target := fmt.Sprintf("%s:%s.%s", repo.URI, ps, targetBuild.Id)
 
+			es, signed := signatures[ps]
+
+			switch {
+			case signed && len(keys) > 0:
+				log.Step("verify").Logf("image=%q", image)
+				if err := verifyImportedImage(ps, tags.Config, es, keys); err != nil {
+					return nil, log.Error(err)
+				}
+			case p.ImageSignatureRequired:
+				return nil, log.Error(fmt.Errorf("service %s: %w", ps, signing.ErrUnsigned))
+			default:
+				signed = false
+			}
+
 			log.Step("tag").Logf("from=%q to=%q", image, target)
 			if out, err := exec.Command("docker", "tag", image, target).CombinedOutput(); err != nil {
 				return nil, log.Error(fmt.Errorf("%s: %s\n", lastline(out), err.Error()))
@@ -410,6 +600,13 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 			if out, err := exec.Command("docker", "push", target).CombinedOutput(); err != nil {
 				return nil, log.Error(fmt.Errorf("%s: %s\n", lastline(out), err.Error()))
 			}
+
+			if signed && p.ImageSigningKey != "" {
+				log.Step("sign").Logf("image=%q", target)
+				if err := p.signImage(repo, fmt.Sprintf("%s.%s", ps, targetBuild.Id)); err != nil {
+					return nil, log.Error(err)
+				}
+			}
 		}
 	}
 
@@ -428,7 +625,7 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 		return nil, log.Error(err)
 	}
 
-	targetBuild.Status = "complete"
+	setBuildStatus(targetBuild, "complete")
 	targetBuild.Release = rr.Id
 
 	if err := p.buildSave(targetBuild); err != nil {
@@ -436,6 +633,14 @@ func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error)
 		return nil, err
 	}
 
+	if index != nil {
+		for _, c := range index.Chunks {
+			p.ObjectDelete(app, c.Key)
+		}
+
+		p.ObjectDelete(app, *opts.Index)
+	}
+
 	p.EventSend("build:create", structs.EventSendOptions{Data: map[string]string{"app": targetBuild.App, "id": targetBuild.Id, "release_id": targetBuild.Id}})
 
 	log.Successf("build=%q release=%q", targetBuild.Id, rr.Id)
@@ -482,12 +687,40 @@ func (p *Provider) BuildList(app string, opts structs.BuildListOptions) (structs
 	return builds, nil
 }
 
+// BuildUpdate saves changes to a build only while its status is the one read
+// so that a build cancelled in the meantime is read again and stays cancelled
 func (p *Provider) BuildUpdate(app, id string, opts structs.BuildUpdateOptions) (*structs.Build, error) {
+	for {
+		b, err := p.buildUpdate(app, id, opts)
+		if err == errBuildChanged {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.Status != nil && !buildActive(*b) {
+			p.buildFinished(app)
+		}
+
+		return b, nil
+	}
+}
+
+func (p *Provider) buildUpdate(app, id string, opts structs.BuildUpdateOptions) (*structs.Build, error) {
 	b, err := p.BuildGet(app, id)
 	if err != nil {
 		return nil, err
 	}
 
+	status := b.Status
+
+	// the process of a cancelled build may still save its logs on the way out
+	// but it can not change the outcome of the build
+	if b.Status == "cancelled" {
+		opts = structs.BuildUpdateOptions{Logs: opts.Logs}
+	}
+
 	if opts.Ended != nil {
 		b.Ended = *opts.Ended
 	}
@@ -508,15 +741,19 @@ func (p *Provider) BuildUpdate(app, id string, opts structs.BuildUpdateOptions)
 		b.Release = *opts.Release
 	}
 
+	if opts.Sbom != nil {
+		b.Sbom = *opts.Sbom
+	}
+
 	if opts.Started != nil {
 		b.Started = *opts.Started
 	}
 
 	if opts.Status != nil {
-		b.Status = *opts.Status
+		setBuildStatus(b, *opts.Status)
 	}
 
-	if err := p.buildSave(b); err != nil {
+	if err := p.buildSaveIf(b, status); err != nil {
 		return nil, err
 	}
 
@@ -524,6 +761,12 @@ func (p *Provider) BuildUpdate(app, id string, opts structs.BuildUpdateOptions)
 }
 
 func (p *Provider) buildSave(b *structs.Build) error {
+	return p.buildSaveIf(b, "")
+}
+
+// buildSaveIf saves a build only while its saved status is status, or
+// unconditionally for a blank status, and returns errBuildChanged if it is not
+func (p *Provider) buildSaveIf(b *structs.Build, status string) error {
 	_, err := p.AppGet(b.App)
 	if err != nil {
 		return err
@@ -582,10 +825,23 @@ func (p *Provider) buildSave(b *structs.Build) error {
 		req.Item["release"] = &dynamodb.AttributeValue{S: aws.String(b.Release)}
 	}
 
+	if b.Sbom != "" {
+		req.Item["sbom"] = &dynamodb.AttributeValue{S: aws.String(b.Sbom)}
+	}
+
 	if !b.Ended.IsZero() {
 		req.Item["ended"] = &dynamodb.AttributeValue{S: aws.String(b.Ended.Format(sortableTime))}
 	}
 
+	if len(b.Transitions) > 0 {
+		transitions, err := json.Marshal(b.Transitions)
+		if err != nil {
+			return err
+		}
+
+		req.Item["transitions"] = &dynamodb.AttributeValue{B: transitions}
+	}
+
 	if len(b.Tags) > 0 {
 		tags, err := json.Marshal(b.Tags)
 		if err != nil {
@@ -595,7 +851,16 @@ func (p *Provider) buildSave(b *structs.Build) error {
 		req.Item["tags"] = &dynamodb.AttributeValue{B: tags}
 	}
 
+	if status != "" {
+		req.ConditionExpression = aws.String("#status = :status")
+		req.ExpressionAttributeNames = map[string]*string{"#status": aws.String("status")}
+		req.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{":status": {S: aws.String(status)}}
+	}
+
 	_, err = p.dynamodb().PutItem(req)
+	if ae, ok := err.(awserr.Error); ok && ae.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return errBuildChanged
+	}
 
 	return err
 }
@@ -835,10 +1100,12 @@ func (p *Provider) runBuild(build *structs.Build, burl string, opts structs.Buil
 	}
 
 	push := fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s:{service}.{build}", aid, p.Region, reg)
+	cacheRepo := ""
 
 	switch a.Tags["Generation"] {
 	case "2":
 		push = fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", aid, p.Region, reg)
+		cacheRepo = fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", aid, p.Region, buildCacheRepository(reg))
 	}
 
 	rk, err := p.describeStack(p.Rack)
@@ -932,6 +1199,42 @@ func (p *Provider) runBuild(build *structs.Build, burl string, opts structs.Buil
 		},
 	}...)
 
+	if cacheRepo != "" {
+		env = append(env, &ecs.KeyValuePair{
+			Name:  aws.String("BUILD_CACHE_REPO"),
+			Value: aws.String(cacheRepo),
+		})
+	}
+
+	if opts.Concurrency != nil {
+		env = append(env, &ecs.KeyValuePair{
+			Name:  aws.String("BUILD_CONCURRENCY"),
+			Value: aws.String(strconv.Itoa(*opts.Concurrency)),
+		})
+	}
+
+	// apps created before BuildSbom existed keep generating them
+	if a.Tags["Generation"] == "2" && a.Parameters["BuildSbom"] != "No" {
+		env = append(env, &ecs.KeyValuePair{
+			Name:  aws.String("BUILD_SBOM"),
+			Value: aws.String("true"),
+		})
+	}
+
+	if opts.SbomDenylist != nil {
+		env = append(env, &ecs.KeyValuePair{
+			Name:  aws.String("BUILD_SBOM_DENYLIST"),
+			Value: aws.String(*opts.SbomDenylist),
+		})
+	}
+
+	if p.ImageSigningKey != "" {
+		env = append(env, &ecs.KeyValuePair{
+			Name:  aws.String("BUILD_SIGNING_KEY"),
+			Value: aws.String(p.ImageSigningKey),
+		})
+	}
+
 	if opts.BuildArgs != nil {
 		for _, v := range *opts.BuildArgs {
 			if len(strings.SplitN(v, "=", 2)) != 2 {
@@ -967,17 +1270,12 @@ func (p *Provider) runBuild(build *structs.Build, burl string, opts structs.Buil
 		return err
 	}
 
-	b, err := p.BuildGet(build.App, build.Id)
+	started, err := p.recordBuildTask(build.App, build.Id, *task.TaskArn)
 	if err != nil {
 		return err
 	}
-
-	b.Status = "running"
-
-	b.Tags["task"] = *task.TaskArn
-
-	if err := p.buildSave(b); err != nil {
-		return err
+	if !started {
+		return nil
 	}
 
 	if _, err := p.waitForTask(*task.TaskArn); err != nil {
@@ -1042,6 +1340,12 @@ func (p *Provider) buildFromItem(item map[string]*dynamodb.AttributeValue) *stru
 		json.Unmarshal(item["tags"].B, &tags)
 	}
 
+	var transitions []structs.BuildTransition
+
+	if item["transitions"] != nil {
+		json.Unmarshal(item["transitions"].B, &transitions)
+	}
+
 	wildcard, _ := strconv.ParseBool(coalesce(item["wildcard-domain"], "false"))
 
 	return &structs.Build{
@@ -1054,10 +1358,12 @@ func (p *Provider) buildFromItem(item map[string]*dynamodb.AttributeValue) *stru
 		Logs:           coalesce(item["logs"], ""),
 		Release:        coalesce(item["release"], ""),
 		Reason:         coalesce(item["reason"], ""),
+		Sbom:           coalesce(item["sbom"], ""),
 		Status:         coalesce(item["status"], ""),
 		Started:        started,
 		Ended:          ended,
 		Tags:           tags,
+		Transitions:    transitions,
 		WildcardDomain: wildcard,
 	}
 }
@@ -1103,41 +1409,11 @@ func (p *Provider) dockerLogin() error {
 }
 
 type imageManifest []struct {
+	Config   string
+	Layers   []string
 	RepoTags []string
 }
 
-func extractImageManifest(r io.Reader) (imageManifest, error) {
-	mtr := tar.NewReader(r)
-
-	var manifest imageManifest
-
-	for {
-		mh, err := mtr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		if mh.Name == "manifest.json" {
-			var mdata bytes.Buffer
-
-			if _, err := io.Copy(&mdata, mtr); err != nil {
-				return nil, err
-			}
-
-			if err := json.Unmarshal(mdata.Bytes(), &manifest); err != nil {
-				return nil, err
-			}
-
-			return manifest, nil
-		}
-	}
-
-	return nil, fmt.Errorf("unable to locate manifest")
-}
-
 func (p *Provider) buildsDeleteAll(app *structs.App) error {
 	// query dynamo for all builds belonging to app
 	qi := &dynamodb.QueryInput{
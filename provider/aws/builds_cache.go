@@ -0,0 +1,149 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/manifest"
+	"github.com/convox/rack/pkg/structs"
+)
+
+// buildCacheTTL matches the cache ttl the daemonless builder gives kaniko,
+// older cache images are never used again
+const buildCacheTTL = 14 * 24 * time.Hour
+
+// buildCacheRepository returns the prefix of the repositories that hold the
+// daemonless build layer cache for an app registry, the builder adds the name
+// of the service
+func buildCacheRepository(reg string) string {
+	return fmt.Sprintf("%s/cache", reg)
+}
+
+// buildCacheRepositories returns the cache repositories for an app registry
+// keyed by service name
+func (p *Provider) buildCacheRepositories(reg string) (map[string]string, error) {
+	prefix := buildCacheRepository(reg) + "/"
+	repos := map[string]string{}
+
+	err := p.ecr().DescribeRepositoriesPages(&ecr.DescribeRepositoriesInput{}, func(page *ecr.DescribeRepositoriesOutput, last bool) bool {
+		for _, r := range page.Repositories {
+			if name := aws.StringValue(r.RepositoryName); strings.HasPrefix(name, prefix) {
+				repos[strings.TrimPrefix(name, prefix)] = name
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+func (p *Provider) cleanupAppBuildCache(a structs.App) (int, error) {
+	if a.Generation != "2" {
+		return 0, nil
+	}
+
+	reg, err := p.appRepositoryName(a)
+	if err != nil {
+		return 0, err
+	}
+
+	var services map[string]bool
+
+	if a.Release != "" {
+		r, err := p.ReleaseGet(a.Name, a.Release)
+		if err != nil {
+			return 0, err
+		}
+
+		env, err := helpers.AppEnvironment(p, a.Name)
+		if err != nil {
+			return 0, err
+		}
+
+		m, err := manifest.Load([]byte(r.Manifest), env)
+		if err != nil {
+			return 0, err
+		}
+
+		services = map[string]bool{}
+
+		for _, s := range m.Services {
+			services[s.Name] = true
+		}
+	}
+
+	return p.pruneBuildCache(reg, services, time.Now().UTC().Add(-buildCacheTTL))
+}
+
+// pruneBuildCache deletes the cache images pushed before expired. If services
+// is not nil the cache repositories of any other service are deleted as well.
+// It returns the number of images and repositories removed.
+func (p *Provider) pruneBuildCache(reg string, services map[string]bool, expired time.Time) (int, error) {
+	repos, err := p.buildCacheRepositories(reg)
+	if err != nil {
+		return 0, err
+	}
+
+	names := []string{}
+
+	for service := range repos {
+		names = append(names, service)
+	}
+
+	sort.Strings(names)
+
+	removed := 0
+
+	for _, service := range names {
+		repo := repos[service]
+
+		if services != nil && !services[service] {
+			_, err := p.ecr().DeleteRepository(&ecr.DeleteRepositoryInput{
+				Force:          aws.Bool(true),
+				RepositoryName: aws.String(repo),
+			})
+			if err != nil {
+				return removed, err
+			}
+
+			removed++
+			continue
+		}
+
+		ids := []*ecr.ImageIdentifier{}
+
+		err := p.ecr().DescribeImagesPages(&ecr.DescribeImagesInput{RepositoryName: aws.String(repo)}, func(page *ecr.DescribeImagesOutput, last bool) bool {
+			for _, d := range page.ImageDetails {
+				if d.ImagePushedAt != nil && d.ImagePushedAt.Before(expired) {
+					ids = append(ids, &ecr.ImageIdentifier{ImageDigest: d.ImageDigest})
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return removed, err
+		}
+
+		for i := 0; i < len(ids); i += 100 {
+			_, err := p.ecr().BatchDeleteImage(&ecr.BatchDeleteImageInput{
+				ImageIds:       ids[i:min(i+100, len(ids))],
+				RepositoryName: aws.String(repo),
+			})
+			if err != nil {
+				return removed, err
+			}
+		}
+
+		removed += len(ids)
+	}
+
+	return removed, nil
+}
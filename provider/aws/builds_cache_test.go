@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneBuildCache(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleBuildCacheDescribeRepositories,
+		cycleBuildCacheDescribeImages,
+		cycleECR("BatchDeleteImage",
+			`{"imageIds":[{"imageDigest":"sha256:old"}],"repositoryName":"convox-app1-registry/cache/web"}`,
+			`{"failures":[],"imageIds":[{"imageDigest":"sha256:old"}]}`,
+		),
+		cycleECR("DeleteRepository",
+			`{"force":true,"repositoryName":"convox-app1-registry/cache/worker"}`,
+			`{"repository":{"repositoryName":"convox-app1-registry/cache/worker"}}`,
+		),
+	)
+	defer closer()
+
+	removed, err := p.pruneBuildCache("convox-app1-registry", map[string]bool{"web": true}, time.Unix(1700000000, 0))
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+}
+
+func TestPruneBuildCacheAll(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleBuildCacheDescribeRepositories,
+		cycleECR("DeleteRepository",
+			`{"force":true,"repositoryName":"convox-app1-registry/cache/web"}`,
+			`{"repository":{"repositoryName":"convox-app1-registry/cache/web"}}`,
+		),
+		cycleECR("DeleteRepository",
+			`{"force":true,"repositoryName":"convox-app1-registry/cache/worker"}`,
+			`{"repository":{"repositoryName":"convox-app1-registry/cache/worker"}}`,
+		),
+	)
+	defer closer()
+
+	removed, err := p.pruneBuildCache("convox-app1-registry", map[string]bool{}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+}
+
+var cycleBuildCacheDescribeImages = cycleECR("DescribeImages",
+	`{"repositoryName":"convox-app1-registry/cache/web"}`,
+	`{
+		"imageDetails": [
+			{"imageDigest": "sha256:old", "imagePushedAt": 1600000000},
+			{"imageDigest": "sha256:new", "imagePushedAt": 1800000000}
+		]
+	}`,
+)
+
+var cycleBuildCacheDescribeRepositories = cycleECR("DescribeRepositories",
+	`{}`,
+	`{
+		"repositories": [
+			{"repositoryName": "convox-app1-registry"},
+			{"repositoryName": "convox-app1-registry/cache/web"},
+			{"repositoryName": "convox-app1-registry/cache/worker"},
+			{"repositoryName": "convox-app2-registry/cache/web"}
+		]
+	}`,
+)
+
+func cycleECR(operation, req, res string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "AmazonEC2ContainerRegistry_V20150921." + operation,
+			Body:       req,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       res,
+		},
+	}
+}
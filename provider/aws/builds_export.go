@@ -0,0 +1,414 @@
+package aws
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/convox/rack/pkg/structs"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressions a build archive can be exported with, imports tell them apart
+// by their magic bytes
+const (
+	buildArchiveGzip = "gzip"
+	buildArchiveZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// exportManifestName is the last file of a build archive, it describes the
+// files before it
+const exportManifestName = "export.json"
+
+// exportManifest holds the checksums of the files in a build archive and of
+// the layers in its image archives
+type exportManifest struct {
+	Files map[string]exportFile `json:"files"`
+}
+
+type exportFile struct {
+	Sha256 string            `json:"sha256"`
+	Size   int64             `json:"size"`
+	Layers map[string]string `json:"layers,omitempty"`
+}
+
+// check returns an error when a file read from an archive does not match
+// the one that was exported
+func (f exportFile) check(name string, got exportFile) error {
+	if got.Size != f.Size {
+		return fmt.Errorf("%s: size %d does not match %d", name, got.Size, f.Size)
+	}
+
+	if got.Sha256 != f.Sha256 {
+		return fmt.Errorf("%s: checksum mismatch", name)
+	}
+
+	layers := []string{}
+
+	for l := range f.Layers {
+		layers = append(layers, l)
+	}
+
+	sort.Strings(layers)
+
+	for _, l := range layers {
+		digest, ok := got.Layers[l]
+		if !ok {
+			return fmt.Errorf("%s: layer %s missing", name, l)
+		}
+		if digest != f.Layers[l] {
+			return fmt.Errorf("%s: layer %s checksum mismatch", name, l)
+		}
+	}
+
+	return nil
+}
+
+// verify checks the files read from an archive against the manifest
+func (m *exportManifest) verify(files map[string]exportFile) error {
+	names := []string{}
+
+	for name := range m.Files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		got, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%s: missing from archive", name)
+		}
+
+		if err := m.Files[name].check(name, got); err != nil {
+			return err
+		}
+	}
+
+	for name := range files {
+		if _, ok := m.Files[name]; !ok {
+			return fmt.Errorf("%s: not in %s", name, exportManifestName)
+		}
+	}
+
+	return nil
+}
+
+// newBuildArchiveWriter compresses a build archive written to w
+func newBuildArchiveWriter(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case "", buildArchiveGzip:
+		return gzip.NewWriter(w), nil
+	case buildArchiveZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression: %s", compression)
+	}
+}
+
+// openBuildArchive decompresses a build archive exported with any of the
+// compressions
+func openBuildArchive(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unknown build archive compression")
+	}
+}
+
+// writeArchiveFile adds a file to a build archive and records its checksum
+func writeArchiveFile(tw *tar.Writer, m *exportManifest, name string, r io.Reader, size int64) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0600,
+		Size:     size,
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	dr := newDigestReader(r)
+
+	if _, err := io.Copy(tw, dr); err != nil {
+		return err
+	}
+
+	m.Files[name] = dr.file()
+
+	return nil
+}
+
+// writeExportManifest adds the manifest to the end of a build archive
+func writeExportManifest(tw *tar.Writer, m *exportManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     exportManifestName,
+		Mode:     0600,
+		Size:     int64(len(data)),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// digestReader keeps the checksum and size of what is read through it
+type digestReader struct {
+	hash hash.Hash
+	r    io.Reader
+	size int64
+}
+
+func newDigestReader(r io.Reader) *digestReader {
+	h := sha256.New()
+
+	return &digestReader{hash: h, r: io.TeeReader(r, h)}
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.size += int64(n)
+	return n, err
+}
+
+func (d *digestReader) file() exportFile {
+	return exportFile{Sha256: hex.EncodeToString(d.hash.Sum(nil)), Size: d.size}
+}
+
+// readImageArchive reads an archive written by docker save to the end and
+// returns its manifest and the checksum of each layer of its images
+func readImageArchive(r io.Reader) (imageManifest, map[string]string, error) {
+	tr := tar.NewReader(r)
+
+	var manifest imageManifest
+
+	found := false
+	sums := map[string]string{}
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if h.Name == "manifest.json" {
+			if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+				return nil, nil, err
+			}
+			found = true
+			continue
+		}
+
+		dr := newDigestReader(tr)
+
+		if _, err := io.Copy(io.Discard, dr); err != nil {
+			return nil, nil, err
+		}
+
+		sums[h.Name] = "sha256:" + dr.file().Sha256
+	}
+
+	if !found {
+		return nil, nil, fmt.Errorf("unable to locate manifest")
+	}
+
+	layers := map[string]string{}
+
+	for _, image := range manifest {
+		for _, l := range image.Layers {
+			sum, ok := sums[l]
+			if !ok {
+				return nil, nil, fmt.Errorf("layer not found: %s", l)
+			}
+			layers[l] = sum
+		}
+	}
+
+	return manifest, layers, nil
+}
+
+// verifyBuildArchive reads a whole build archive and checks every file and
+// image layer in it against its manifest
+func verifyBuildArchive(r io.Reader) error {
+	ar, err := openBuildArchive(r)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+
+	tr := tar.NewReader(ar)
+
+	var em *exportManifest
+
+	files := map[string]exportFile{}
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if h.Name == exportManifestName {
+			if err := json.NewDecoder(tr).Decode(&em); err != nil {
+				return fmt.Errorf("%s: %s", exportManifestName, err)
+			}
+			continue
+		}
+
+		dr := newDigestReader(tr)
+
+		var layers map[string]string
+
+		if strings.HasSuffix(h.Name, ".tar") {
+			if _, layers, err = readImageArchive(dr); err != nil {
+				return fmt.Errorf("%s: %s", h.Name, err)
+			}
+		}
+
+		if _, err := io.Copy(io.Discard, dr); err != nil {
+			return err
+		}
+
+		f := dr.file()
+		f.Layers = layers
+		files[h.Name] = f
+	}
+
+	if em == nil {
+		return fmt.Errorf("build archive has no %s to verify against", exportManifestName)
+	}
+
+	return em.verify(files)
+}
+
+// chunkReader reads the objects a build archive was uploaded as one after
+// another and checks each against the index as it ends
+type chunkReader struct {
+	chunks  []structs.BuildImportChunk
+	current io.ReadCloser
+	digest  *digestReader
+	fetch   func(key string) (io.ReadCloser, error)
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.chunks) == 0 {
+				return 0, io.EOF
+			}
+
+			rc, err := c.fetch(c.chunks[0].Key)
+			if err != nil {
+				return 0, err
+			}
+
+			c.current = rc
+			c.digest = newDigestReader(rc)
+		}
+
+		n, err := c.digest.Read(p)
+		if err == io.EOF {
+			chunk := c.chunks[0]
+
+			c.current.Close()
+			c.current = nil
+			c.chunks = c.chunks[1:]
+
+			if got := c.digest.file(); got.Sha256 != chunk.Sha256 || got.Size != chunk.Size {
+				return n, fmt.Errorf("chunk %s: checksum mismatch", chunk.Key)
+			}
+
+			if n > 0 {
+				return n, nil
+			}
+
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (c *chunkReader) Close() error {
+	if c.current != nil {
+		return c.current.Close()
+	}
+
+	return nil
+}
+
+// importChunks returns the build archive uploaded as the objects listed in
+// the index at key
+func (p *Provider) importChunks(app, key string) (*structs.BuildImportIndex, *chunkReader, error) {
+	r, err := p.ObjectFetch(app, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	var index structs.BuildImportIndex
+
+	if err := json.NewDecoder(r).Decode(&index); err != nil {
+		return nil, nil, fmt.Errorf("invalid import index: %s", err)
+	}
+
+	cr := &chunkReader{
+		chunks: index.Chunks,
+		fetch:  func(key string) (io.ReadCloser, error) { return p.ObjectFetch(app, key) },
+	}
+
+	return &index, cr, nil
+}
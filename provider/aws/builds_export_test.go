@@ -0,0 +1,158 @@
+package aws
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildArchiveVerify(t *testing.T) {
+	for _, compression := range []string{buildArchiveGzip, buildArchiveZstd} {
+		t.Run(compression, func(t *testing.T) {
+			data := buildArchiveFixture(t, compression, nil)
+
+			require.NoError(t, verifyBuildArchive(bytes.NewReader(data)))
+		})
+	}
+}
+
+func TestBuildArchiveVerifyChecksum(t *testing.T) {
+	data := buildArchiveFixture(t, buildArchiveZstd, func(m *exportManifest) {
+		f := m.Files["build.json"]
+		f.Sha256 = strings.Repeat("0", 64)
+		m.Files["build.json"] = f
+	})
+
+	require.EqualError(t, verifyBuildArchive(bytes.NewReader(data)), "build.json: checksum mismatch")
+}
+
+func TestBuildArchiveVerifyLayer(t *testing.T) {
+	data := buildArchiveFixture(t, buildArchiveGzip, func(m *exportManifest) {
+		f := m.Files["app1.B1.tar"]
+		f.Layers["l1/layer.tar"] = "sha256:" + strings.Repeat("0", 64)
+		m.Files["app1.B1.tar"] = f
+	})
+
+	require.EqualError(t, verifyBuildArchive(bytes.NewReader(data)), "app1.B1.tar: layer l1/layer.tar checksum mismatch")
+}
+
+func TestBuildArchiveVerifyMissing(t *testing.T) {
+	data := buildArchiveFixture(t, buildArchiveGzip, func(m *exportManifest) {
+		m.Files["signatures.json"] = exportFile{Sha256: strings.Repeat("0", 64), Size: 2}
+	})
+
+	require.EqualError(t, verifyBuildArchive(bytes.NewReader(data)), "signatures.json: missing from archive")
+}
+
+func TestBuildArchiveVerifyNoManifest(t *testing.T) {
+	var buf bytes.Buffer
+
+	cw, err := newBuildArchiveWriter(&buf, buildArchiveGzip)
+	require.NoError(t, err)
+
+	tw := tar.NewWriter(cw)
+	require.NoError(t, writeArchiveFile(tw, &exportManifest{Files: map[string]exportFile{}}, "build.json", strings.NewReader("{}"), 2))
+	require.NoError(t, tw.Close())
+	require.NoError(t, cw.Close())
+
+	require.EqualError(t, verifyBuildArchive(&buf), "build archive has no export.json to verify against")
+}
+
+func TestBuildArchiveCompression(t *testing.T) {
+	_, err := newBuildArchiveWriter(io.Discard, "bzip2")
+	require.EqualError(t, err, "unknown compression: bzip2")
+
+	_, err = openBuildArchive(strings.NewReader("not an archive"))
+	require.EqualError(t, err, "unknown build archive compression")
+}
+
+func TestChunkReader(t *testing.T) {
+	objects := map[string]string{"c1": "build ", "c2": "archive"}
+
+	chunks := []structs.BuildImportChunk{
+		buildImportChunk("c1", "build "),
+		buildImportChunk("c2", "archive"),
+	}
+
+	cr := &chunkReader{chunks: chunks, fetch: fetchFixture(objects)}
+
+	data, err := io.ReadAll(cr)
+	require.NoError(t, err)
+	require.Equal(t, "build archive", string(data))
+
+	objects["c2"] = "archivf"
+
+	cr = &chunkReader{chunks: chunks, fetch: fetchFixture(objects)}
+
+	_, err = io.ReadAll(cr)
+	require.EqualError(t, err, "chunk c2: checksum mismatch")
+}
+
+// buildArchiveFixture returns a build archive of one image with one layer,
+// with its manifest changed by fn
+func buildArchiveFixture(t *testing.T, compression string, fn func(m *exportManifest)) []byte {
+	var image bytes.Buffer
+
+	itw := tar.NewWriter(&image)
+
+	for name, body := range map[string]string{
+		"l1/layer.tar":  "layer1",
+		"manifest.json": `[{"Config":"c1.json","Layers":["l1/layer.tar"],"RepoTags":["app1:web.B1"]}]`,
+	} {
+		require.NoError(t, itw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0600, Size: int64(len(body))}))
+		_, err := itw.Write([]byte(body))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, itw.Close())
+
+	_, layers, err := readImageArchive(bytes.NewReader(image.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"l1/layer.tar": fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("layer1")))}, layers)
+
+	var buf bytes.Buffer
+
+	cw, err := newBuildArchiveWriter(&buf, compression)
+	require.NoError(t, err)
+
+	tw := tar.NewWriter(cw)
+
+	em := &exportManifest{Files: map[string]exportFile{}}
+
+	require.NoError(t, writeArchiveFile(tw, em, "build.json", strings.NewReader(`{"id":"B1"}`), 11))
+	require.NoError(t, writeArchiveFile(tw, em, "app1.B1.tar", bytes.NewReader(image.Bytes()), int64(image.Len())))
+
+	f := em.Files["app1.B1.tar"]
+	f.Layers = layers
+	em.Files["app1.B1.tar"] = f
+
+	if fn != nil {
+		fn(em)
+	}
+
+	require.NoError(t, writeExportManifest(tw, em))
+	require.NoError(t, tw.Close())
+	require.NoError(t, cw.Close())
+
+	return buf.Bytes()
+}
+
+func buildImportChunk(key, data string) structs.BuildImportChunk {
+	sum := sha256.Sum256([]byte(data))
+
+	return structs.BuildImportChunk{Key: key, Sha256: hex.EncodeToString(sum[:]), Size: int64(len(data))}
+}
+
+func fetchFixture(objects map[string]string) func(string) (io.ReadCloser, error) {
+	return func(key string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(objects[key])), nil
+	}
+}
@@ -0,0 +1,337 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/logger"
+	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+)
+
+// builds of an app with BuildQueue set to Queue run one at a time, with
+// Supersede a new build cancels the ones before it
+const (
+	buildQueueParallel  = "Parallel"
+	buildQueueQueue     = "Queue"
+	buildQueueSupersede = "Supersede"
+)
+
+// builds queued or running are looked for in this many of the latest builds
+const buildQueueDepth = 100
+
+// errBuildChanged is returned when a build was saved by another process
+// since it was read
+var errBuildChanged = errors.New("build changed")
+
+// setBuildStatus moves a build to status and records when it did
+func setBuildStatus(b *structs.Build, status string) {
+	if b.Status == status && len(b.Transitions) > 0 {
+		return
+	}
+
+	b.Status = status
+	b.Transitions = append(b.Transitions, structs.BuildTransition{Status: status, Time: helpers.TimeNow().UTC()})
+}
+
+// buildActive returns true for a build that is queued or running
+func buildActive(b structs.Build) bool {
+	return b.Status == "queued" || b.Status == "running"
+}
+
+// buildQueue returns how builds of an app wait for each other
+func buildQueue(a *structs.App) string {
+	switch q := a.Parameters["BuildQueue"]; q {
+	case buildQueueQueue, buildQueueSupersede:
+		return q
+	default:
+		return buildQueueParallel
+	}
+}
+
+// buildTimeout returns how long a build of an app may run, zero for no limit
+func buildTimeout(a *structs.App) (time.Duration, error) {
+	v := a.Parameters["BuildTimeout"]
+	if v == "" {
+		return 0, nil
+	}
+
+	minutes, err := strconv.Atoi(v)
+	if err != nil || minutes < 0 {
+		return 0, fmt.Errorf("invalid BuildTimeout: %s", v)
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// buildRunning returns when a build started running
+func buildRunning(b structs.Build) time.Time {
+	for i := len(b.Transitions) - 1; i >= 0; i-- {
+		if b.Transitions[i].Status == "running" {
+			return b.Transitions[i].Time
+		}
+	}
+
+	return b.Started
+}
+
+// activeBuilds returns the builds of an app that are queued or running,
+// latest first
+func (p *Provider) activeBuilds(app string) (structs.Builds, error) {
+	bs, err := p.BuildList(app, structs.BuildListOptions{Limit: options.Int(buildQueueDepth)})
+	if err != nil {
+		return nil, err
+	}
+
+	active := structs.Builds{}
+
+	for _, b := range bs {
+		if buildActive(b) {
+			active = append(active, b)
+		}
+	}
+
+	return active, nil
+}
+
+// queueBuild saves a build to be started once the builds of its app before
+// it finish, the options it runs with are kept on the build until then
+func (p *Provider) queueBuild(b *structs.Build, url string, opts structs.BuildCreateOptions) error {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+
+	b.Tags["url"] = url
+	b.Tags["options"] = string(data)
+
+	setBuildStatus(b, "queued")
+
+	return p.buildSave(b)
+}
+
+// cancelBuild stops the process of a queued or running build and marks it
+// cancelled for reason. The build is only saved while its status is the one
+// read, if its process started in the meantime it is read again so that the
+// task it recorded is stopped as well.
+func (p *Provider) cancelBuild(b *structs.Build, reason string) error {
+	for {
+		if !buildActive(*b) && b.Status != "created" {
+			return fmt.Errorf("build %s is %s", b.Id, b.Status)
+		}
+
+		if task := b.Tags["task"]; task != "" {
+			if err := p.stopBuildTask(task, reason); err != nil {
+				return err
+			}
+		}
+
+		status := b.Status
+
+		delete(b.Tags, "url")
+		delete(b.Tags, "options")
+
+		setBuildStatus(b, "cancelled")
+
+		b.Ended = helpers.TimeNow().UTC()
+		b.Reason = reason
+
+		switch err := p.buildSaveIf(b, status); err {
+		case nil:
+			return nil
+		case errBuildChanged:
+		default:
+			return err
+		}
+
+		nb, err := p.BuildGet(b.App, b.Id)
+		if err != nil {
+			return err
+		}
+
+		*b = *nb
+	}
+}
+
+// recordBuildTask marks a build running in task unless it was cancelled or
+// finished since its task was started, in which case the task is stopped and
+// false is returned. A cancel before the task was recorded could not stop it.
+func (p *Provider) recordBuildTask(app, id, task string) (bool, error) {
+	for {
+		b, err := p.BuildGet(app, id)
+		if err != nil {
+			return false, err
+		}
+
+		if !buildActive(*b) && b.Status != "created" {
+			return false, p.stopBuildTask(task, coalesces(b.Reason, b.Status))
+		}
+
+		status := b.Status
+
+		setBuildStatus(b, "running")
+
+		b.Tags["task"] = task
+
+		switch err := p.buildSaveIf(b, status); err {
+		case nil:
+			return true, nil
+		case errBuildChanged:
+		default:
+			return false, err
+		}
+	}
+}
+
+func (p *Provider) stopBuildTask(task, reason string) error {
+	_, err := p.ecs().StopTask(&ecs.StopTaskInput{
+		Cluster: aws.String(p.BuildCluster),
+		Reason:  aws.String(reason),
+		Task:    aws.String(task),
+	})
+
+	return err
+}
+
+// startQueuedBuild starts the oldest queued build of an app once none of its
+// builds are running. Several processes may try to start the same build, it
+// is claimed by moving it from queued to running and only the process whose
+// claim succeeds runs it. Builds are claimed oldest first so a process that
+// missed a claim never starts a build after it while it runs.
+func (p *Provider) startQueuedBuild(app string) error {
+	active, err := p.activeBuilds(app)
+	if err != nil {
+		return err
+	}
+
+	var next *structs.Build
+
+	for i := range active {
+		if active[i].Status == "running" {
+			return nil
+		}
+
+		next = &active[i]
+	}
+
+	if next == nil {
+		return nil
+	}
+
+	var opts structs.BuildCreateOptions
+
+	if err := json.Unmarshal([]byte(next.Tags["options"]), &opts); err != nil {
+		return fmt.Errorf("build %s: invalid options: %s", next.Id, err)
+	}
+
+	url := next.Tags["url"]
+
+	delete(next.Tags, "url")
+	delete(next.Tags, "options")
+
+	setBuildStatus(next, "running")
+
+	switch err := p.buildSaveIf(next, "queued"); err {
+	case nil:
+	case errBuildChanged:
+		return nil
+	default:
+		return err
+	}
+
+	if err := p.runBuild(next, url, opts); err != nil {
+		setBuildStatus(next, "failed")
+
+		next.Ended = helpers.TimeNow().UTC()
+		next.Reason = err.Error()
+
+		// a build cancelled in the meantime stays cancelled
+		if err := p.buildSaveIf(next, "running"); err != nil && err != errBuildChanged {
+			return err
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// buildFinished starts the next queued build of an app in the background
+// when its builds run one at a time
+func (p *Provider) buildFinished(app string) {
+	a, err := p.AppGet(app)
+	if err != nil || buildQueue(a) != buildQueueQueue {
+		return
+	}
+
+	go func() {
+		if err := p.startQueuedBuild(app); err != nil {
+			Logger.At("buildFinished").Namespace("app=%q", app).Error(err)
+		}
+	}()
+}
+
+// expireBuilds cancels the builds of each app that ran past the app build
+// timeout and starts queued builds whose turn has come
+func (p *Provider) expireBuilds(ctx context.Context, log *logger.Logger) error {
+	as, err := p.AppList()
+	if err != nil {
+		return log.Error(err)
+	}
+
+	for i := range as {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		a := &as[i]
+
+		log := log.Replace("app", a.Name)
+
+		if err := p.expireAppBuilds(a); err != nil {
+			log.Error(err)
+			continue
+		}
+
+		if buildQueue(a) == buildQueueQueue {
+			if err := p.startQueuedBuild(a.Name); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Provider) expireAppBuilds(a *structs.App) error {
+	timeout, err := buildTimeout(a)
+	if err != nil || timeout == 0 {
+		return err
+	}
+
+	active, err := p.activeBuilds(a.Name)
+	if err != nil {
+		return err
+	}
+
+	for i := range active {
+		b := &active[i]
+
+		if b.Status != "running" || helpers.TimeNow().Sub(buildRunning(*b)) < timeout {
+			continue
+		}
+
+		if err := p.cancelBuild(b, fmt.Sprintf("timed out after %s", timeout)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,484 @@
+package aws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/require"
+)
+
+var buildQueueTestNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestSetBuildStatus(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	b := structs.NewBuild("app1")
+
+	setBuildStatus(b, b.Status)
+	setBuildStatus(b, "running")
+	setBuildStatus(b, "running")
+	setBuildStatus(b, "complete")
+
+	require.Equal(t, "complete", b.Status)
+	require.Equal(t, []structs.BuildTransition{
+		{Status: "created", Time: buildQueueTestNow},
+		{Status: "running", Time: buildQueueTestNow},
+		{Status: "complete", Time: buildQueueTestNow},
+	}, b.Transitions)
+}
+
+func TestBuildQueueParameters(t *testing.T) {
+	a := &structs.App{Parameters: map[string]string{}}
+
+	require.Equal(t, buildQueueParallel, buildQueue(a))
+
+	timeout, err := buildTimeout(a)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), timeout)
+
+	a.Parameters["BuildQueue"] = "Supersede"
+	a.Parameters["BuildTimeout"] = "30"
+
+	require.Equal(t, buildQueueSupersede, buildQueue(a))
+
+	timeout, err = buildTimeout(a)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, timeout)
+
+	a.Parameters["BuildTimeout"] = "30m"
+
+	_, err = buildTimeout(a)
+	require.EqualError(t, err, "invalid BuildTimeout: 30m")
+}
+
+func TestBuildCancel(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	p, closer := testInternalProvider(
+		cycleBuildQueueGetItem("running", `{"task":"task1"}`),
+		cycleECS("StopTask", `{"cluster":"cluster-build","reason":"cancelled","task":"task1"}`, `{"task":{"taskArn":"task1"}}`),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"reason": "cancelled", "status": "cancelled"}, `{"task":"task1"}`, []structs.BuildTransition{
+			{Status: "running", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+			{Status: "cancelled", Time: buildQueueTestNow},
+		}), "running", 200, `{}`),
+		cycleBuildQueueDescribeStack(nil),
+	)
+	defer closer()
+
+	p.BuildCluster = "cluster-build"
+	p.DynamoBuilds = "convox-builds"
+
+	require.NoError(t, p.BuildCancel("app1", "B1"))
+}
+
+// a build cancelled before its task was recorded could not stop the task, it
+// is stopped once the task is recorded instead of running to completion
+func TestBuildCancelBeforeTask(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	conflict := `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`
+
+	p, closer := testInternalProvider(
+		// the cancel is saved while the build has no task
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"reason": "cancelled", "status": "cancelled"}, `{}`, []structs.BuildTransition{
+			{Status: "cancelled", Time: buildQueueTestNow},
+		}), "created", 200, `{}`),
+		// the build process then finds it cancelled and stops its task
+		cycleBuildQueueGetItem("cancelled", `{}`),
+		cycleECS("StopTask", `{"cluster":"cluster-build","reason":"cancelled","task":"task2"}`, `{"task":{"taskArn":"task2"}}`),
+		// the same race the other way around, the build records its task
+		// first and the cancel reads it again to stop the task
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"reason": "cancelled", "status": "cancelled"}, `{}`, []structs.BuildTransition{
+			{Status: "cancelled", Time: buildQueueTestNow},
+		}), "created", 400, conflict),
+		cycleBuildQueueGetItem("running", `{"task":"task2"}`),
+		cycleECS("StopTask", `{"cluster":"cluster-build","reason":"cancelled","task":"task2"}`, `{"task":{"taskArn":"task2"}}`),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"reason": "cancelled", "status": "cancelled"}, `{"task":"task2"}`, []structs.BuildTransition{
+			{Status: "running", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+			{Status: "cancelled", Time: buildQueueTestNow},
+		}), "running", 200, `{}`),
+	)
+	defer closer()
+
+	p.BuildCluster = "cluster-build"
+	p.DynamoBuilds = "convox-builds"
+
+	require.NoError(t, p.cancelBuild(&structs.Build{App: "app1", Id: "B1", Status: "created", Tags: map[string]string{}}, "cancelled"))
+
+	started, err := p.recordBuildTask("app1", "B1", "task2")
+	require.NoError(t, err)
+	require.False(t, started)
+
+	require.NoError(t, p.cancelBuild(&structs.Build{App: "app1", Id: "B1", Status: "created", Tags: map[string]string{}}, "cancelled"))
+}
+
+// a build claimed as running and cancelled while its task starts is not
+// marked running again
+func TestRecordBuildTaskCancelled(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	p, closer := testInternalProvider(
+		cycleBuildQueueGetItem("running", `{}`),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"status": "running"}, `{"task":"task2"}`, []structs.BuildTransition{
+			{Status: "running", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+		}), "running", 400, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`),
+		cycleBuildQueueGetItem("cancelled", `{}`),
+		cycleECS("StopTask", `{"cluster":"cluster-build","reason":"cancelled","task":"task2"}`, `{"task":{"taskArn":"task2"}}`),
+	)
+	defer closer()
+
+	p.BuildCluster = "cluster-build"
+	p.DynamoBuilds = "convox-builds"
+
+	started, err := p.recordBuildTask("app1", "B1", "task2")
+	require.NoError(t, err)
+	require.False(t, started)
+}
+
+func TestBuildCreateQueued(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	opts := structs.BuildCreateOptions{}
+
+	data, err := json.Marshal(opts)
+	require.NoError(t, err)
+
+	tags, err := json.Marshal(map[string]string{"options": string(data), "url": "object://app1/source.tgz"})
+	require.NoError(t, err)
+
+	params := map[string]string{"BuildQueue": "Queue"}
+
+	p, closer := testInternalProvider(
+		cycleBuildQueueDescribeStack(params),
+		cycleBuildQueueDescribeStack(params),
+		cycleBuildQueuePutItem(t, map[string]string{"id": "B123", "status": "created"}, `{}`, []structs.BuildTransition{
+			{Status: "created", Time: buildQueueTestNow},
+		}),
+		cycleBuildQueueDescribeStack(params),
+		cycleBuildQueuePutItem(t, map[string]string{"id": "B123", "status": "queued"}, string(tags), []structs.BuildTransition{
+			{Status: "created", Time: buildQueueTestNow},
+			{Status: "queued", Time: buildQueueTestNow},
+		}),
+		cycleBuildQueueDescribeStack(params),
+		cycleBuildQueueQuery(`[{"app":{"S":"app1"},"created":{"S":"20260102.030405.000000000"},"id":{"S":"B123"},"status":{"S":"queued"}},{"app":{"S":"app1"},"created":{"S":"20260102.030000.000000000"},"id":{"S":"B1"},"status":{"S":"running"}}]`),
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				RequestURI: "/",
+				Operation:  "DynamoDB_20120810.GetItem",
+				Body:       `{"ConsistentRead":true,"Key":{"id":{"S":"B123"}},"TableName":"convox-builds"}`,
+			},
+			Response: awsutil.Response{
+				StatusCode: 200,
+				Body:       `{"Item":{"app":{"S":"app1"},"created":{"S":"20260102.030405.000000000"},"id":{"S":"B123"},"status":{"S":"queued"}}}`,
+			},
+		},
+	)
+	defer closer()
+
+	p.DynamoBuilds = "convox-builds"
+
+	b, err := p.BuildCreate("app1", "object://app1/source.tgz", opts)
+	require.NoError(t, err)
+	require.Equal(t, "queued", b.Status)
+}
+
+func TestStartQueuedBuildClaimed(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	p, closer := testInternalProvider(
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueQuery(buildQueueQueuedItems(t)),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueClaim(t, 400, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`),
+	)
+	defer closer()
+
+	p.DynamoBuilds = "convox-builds"
+
+	// another process claimed the build first and runs it
+	require.NoError(t, p.startQueuedBuild("app1"))
+}
+
+func TestStartQueuedBuildRunError(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	p, closer := testInternalProvider(
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueQuery(buildQueueQueuedItems(t)),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueClaim(t, 200, `{}`),
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				Method:     "POST",
+				RequestURI: "/",
+				Body:       `Action=DescribeStacks&StackName=convox&Version=2010-05-15`,
+			},
+			Response: awsutil.Response{
+				StatusCode: 400,
+				Body:       `<ErrorResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/"><Error><Type>Sender</Type><Code>ValidationError</Code><Message>Stack with id convox does not exist</Message></Error></ErrorResponse>`,
+			},
+		},
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"reason": "stack not found: convox", "status": "failed"}, `{}`, []structs.BuildTransition{
+			{Status: "queued", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+			{Status: "running", Time: buildQueueTestNow},
+			{Status: "failed", Time: buildQueueTestNow},
+		}), "running", 200, `{}`),
+	)
+	defer closer()
+
+	p.DynamoBuilds = "convox-builds"
+
+	// a claimed build that could not be run fails rather than holding up
+	// the builds queued after it
+	require.EqualError(t, p.startQueuedBuild("app1"), "stack not found: convox")
+}
+
+func TestBuildCancelFinished(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleBuildQueueGetItem("complete", `{}`),
+	)
+	defer closer()
+
+	p.DynamoBuilds = "convox-builds"
+
+	require.EqualError(t, p.BuildCancel("app1", "B1"), "build B1 is complete")
+}
+
+func TestBuildUpdateCancelled(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleBuildQueueGetItem("cancelled", `{}`),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"logs": "object:///logs", "status": "cancelled"}, `{}`, []structs.BuildTransition{
+			{Status: "running", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+		}), "cancelled", 200, `{}`),
+	)
+	defer closer()
+
+	p.DynamoBuilds = "convox-builds"
+
+	// the logs of the build are saved but it stays cancelled
+	failed, logs := "failed", "object:///logs"
+
+	b, err := p.BuildUpdate("app1", "B1", structs.BuildUpdateOptions{Logs: &logs, Status: &failed})
+	require.NoError(t, err)
+	require.Equal(t, "cancelled", b.Status)
+	require.Equal(t, "object:///logs", b.Logs)
+}
+
+// a build cancelled while its process saves the outcome stays cancelled
+func TestBuildUpdateCancelledRace(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	p, closer := testInternalProvider(
+		cycleBuildQueueGetItem("running", `{}`),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"logs": "object:///logs", "status": "complete"}, `{}`, []structs.BuildTransition{
+			{Status: "running", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+			{Status: "complete", Time: buildQueueTestNow},
+		}), "running", 400, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`),
+		cycleBuildQueueGetItem("cancelled", `{}`),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"logs": "object:///logs", "status": "cancelled"}, `{}`, []structs.BuildTransition{
+			{Status: "running", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+		}), "cancelled", 200, `{}`),
+	)
+	defer closer()
+
+	p.DynamoBuilds = "convox-builds"
+
+	complete, logs := "complete", "object:///logs"
+
+	b, err := p.BuildUpdate("app1", "B1", structs.BuildUpdateOptions{Logs: &logs, Status: &complete})
+	require.NoError(t, err)
+	require.Equal(t, "cancelled", b.Status)
+}
+
+func TestExpireAppBuilds(t *testing.T) {
+	defer buildQueueTestClock()()
+
+	p, closer := testInternalProvider(
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueQuery(`[{"app":{"S":"app1"},"created":{"S":"20260102.025000.000000000"},"id":{"S":"B1"},"status":{"S":"running"},"tags":{"B":"eyJ0YXNrIjoidGFzazEifQ=="},"transitions":{"B":"`+buildQueueTransitions(t, []structs.BuildTransition{{Status: "running", Time: buildQueueTestNow.Add(-31 * time.Minute)}})+`"}}]`),
+		cycleECS("StopTask", `{"cluster":"cluster-build","reason":"timed out after 30m0s","task":"task1"}`, `{"task":{"taskArn":"task1"}}`),
+		cycleBuildQueueDescribeStack(nil),
+		cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"reason": "timed out after 30m0s", "status": "cancelled"}, `{"task":"task1"}`, []structs.BuildTransition{
+			{Status: "running", Time: buildQueueTestNow.Add(-31 * time.Minute)},
+			{Status: "cancelled", Time: buildQueueTestNow},
+		}), "running", 200, `{}`),
+	)
+	defer closer()
+
+	p.BuildCluster = "cluster-build"
+	p.DynamoBuilds = "convox-builds"
+
+	require.NoError(t, p.expireAppBuilds(&structs.App{Name: "app1", Parameters: map[string]string{"BuildTimeout": "30"}}))
+}
+
+func buildQueueTestClock() func() {
+	now := helpers.TimeNow
+	helpers.TimeNow = func() time.Time { return buildQueueTestNow }
+	return func() { helpers.TimeNow = now }
+}
+
+func buildQueueTransitions(t *testing.T, ts []structs.BuildTransition) string {
+	data, err := json.Marshal(ts)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func cycleECS(operation, req, res string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "AmazonEC2ContainerServiceV20141113." + operation,
+			Body:       req,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       res,
+		},
+	}
+}
+
+func cycleBuildQueueGetItem(status, tags string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.GetItem",
+			Body:       `{"ConsistentRead":true,"Key":{"id":{"S":"B1"}},"TableName":"convox-builds"}`,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body: fmt.Sprintf(`{"Item":{"app":{"S":"app1"},"created":{"S":"20260102.030305.000000000"},"id":{"S":"B1"},"status":{"S":%q},"tags":{"B":%q},"transitions":{"B":%q}}}`,
+				status,
+				base64.StdEncoding.EncodeToString([]byte(tags)),
+				base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(`[{"status":"running","time":%q}]`, buildQueueTestNow.Add(-1*time.Minute).Format(time.RFC3339)))),
+			),
+		},
+	}
+}
+
+// buildQueueQueuedItems returns build B1 of app1 queued a minute ago
+func buildQueueQueuedItems(t *testing.T) string {
+	tags := base64.StdEncoding.EncodeToString([]byte(`{"options":"{}","url":"object://app1/source.tgz"}`))
+	ts := buildQueueTransitions(t, []structs.BuildTransition{{Status: "queued", Time: buildQueueTestNow.Add(-1 * time.Minute)}})
+
+	return fmt.Sprintf(`[{"app":{"S":"app1"},"created":{"S":"20260102.030305.000000000"},"id":{"S":"B1"},"status":{"S":"queued"},"tags":{"B":%q},"transitions":{"B":%q}}]`, tags, ts)
+}
+
+// cycleBuildQueueClaim returns the cycle that moves build B1 of app1 from
+// queued to running only if it is still queued
+func cycleBuildQueueClaim(t *testing.T, code int, res string) awsutil.Cycle {
+	return cycleBuildQueueIf(cycleBuildQueuePutItem(t, map[string]string{"status": "running"}, `{}`, []structs.BuildTransition{
+		{Status: "queued", Time: buildQueueTestNow.Add(-1 * time.Minute)},
+		{Status: "running", Time: buildQueueTestNow},
+	}), "queued", code, res)
+}
+
+// cycleBuildQueueIf makes a save of a build conditional on its saved status
+func cycleBuildQueueIf(c awsutil.Cycle, status string, code int, res string) awsutil.Cycle {
+	c.Request.Body = strings.TrimSuffix(c.Request.Body, "}") + fmt.Sprintf(`,"ConditionExpression":"#status = :status","ExpressionAttributeNames":{"#status":"status"},"ExpressionAttributeValues":{":status":{"S":%q}}}`, status)
+	c.Response = awsutil.Response{StatusCode: code, Body: res}
+
+	return c
+}
+
+func cycleBuildQueueQuery(items string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.Query",
+			Body:       `{"IndexName":"app.created","KeyConditions":{"app":{"AttributeValueList":[{"S":"app1"}],"ComparisonOperator":"EQ"}},"Limit":100,"ScanIndexForward":false,"TableName":"convox-builds"}`,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       fmt.Sprintf(`{"Items":%s}`, items),
+		},
+	}
+}
+
+// cycleBuildQueuePutItem returns the cycle that saves build B1 of app1 with
+// the string attributes in attrs
+func cycleBuildQueuePutItem(t *testing.T, attrs map[string]string, tags string, ts []structs.BuildTransition) awsutil.Cycle {
+	item := map[string]string{
+		"app":             `{"S":"app1"}`,
+		"created":         `{"S":"20160904.223813.000000000"}`,
+		"ended":           `{"S":"20160904.224132.000000000"}`,
+		"id":              `{"S":"B1"}`,
+		"transitions":     fmt.Sprintf(`{"B":%q}`, buildQueueTransitions(t, ts)),
+		"wildcard-domain": `{"S":"false"}`,
+	}
+
+	for k, v := range attrs {
+		item[k] = fmt.Sprintf(`{"S":%q}`, v)
+	}
+
+	if tags != "{}" {
+		item["tags"] = fmt.Sprintf(`{"B":%q}`, base64.StdEncoding.EncodeToString([]byte(tags)))
+	}
+
+	keys := []string{}
+	for k := range item {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := []string{}
+	for _, k := range keys {
+		fields = append(fields, fmt.Sprintf("%q:%s", k, item[k]))
+	}
+
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.PutItem",
+			Body:       fmt.Sprintf(`{"Item":{%s},"TableName":"convox-builds"}`, strings.Join(fields, ",")),
+		},
+		Response: awsutil.Response{StatusCode: 200, Body: `{}`},
+	}
+}
+
+func cycleBuildQueueDescribeStack(params map[string]string) awsutil.Cycle {
+	members := ""
+
+	for k, v := range params {
+		members += fmt.Sprintf("<member><ParameterKey>%s</ParameterKey><ParameterValue>%s</ParameterValue></member>", k, v)
+	}
+
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			Method:     "POST",
+			RequestURI: "/",
+			Body:       `Action=DescribeStacks&StackName=convox-app1&Version=2010-05-15`,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body: fmt.Sprintf(`<DescribeStacksResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/"><DescribeStacksResult><Stacks><member>
+				<Tags>
+					<member><Key>Name</Key><Value>app1</Value></member>
+					<member><Key>Type</Key><Value>app</Value></member>
+					<member><Key>System</Key><Value>convox</Value></member>
+					<member><Key>Rack</Key><Value>convox</Value></member>
+					<member><Key>Generation</Key><Value>2</Value></member>
+				</Tags>
+				<StackName>convox-app1</StackName>
+				<StackStatus>UPDATE_COMPLETE</StackStatus>
+				<Parameters>%s</Parameters>
+			</member></Stacks></DescribeStacksResult></DescribeStacksResponse>`, members),
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/convox/rack/pkg/structs"
+)
+
+// BuildSbom returns the software bill of materials stored for a build, a JSON
+// object with the document for each service or the document for one service.
+// Services built for several platforms have a document for each platform
+// stored as service@platform, asking for the service returns them by platform.
+func (p *Provider) BuildSbom(app, id string, opts structs.BuildSbomOptions) (io.ReadCloser, error) {
+	b, err := p.BuildGet(app, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Sbom == "" {
+		return nil, fmt.Errorf("no sbom for build: %s", id)
+	}
+
+	u, err := url.Parse(b.Sbom)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "object" {
+		return nil, fmt.Errorf("unknown sbom location: %s", b.Sbom)
+	}
+
+	r, err := p.ObjectFetch(b.App, u.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Service == nil {
+		return r, nil
+	}
+
+	defer r.Close()
+
+	sboms := map[string]json.RawMessage{}
+
+	if err := json.NewDecoder(r).Decode(&sboms); err != nil {
+		return nil, err
+	}
+
+	if doc, ok := sboms[*opts.Service]; ok {
+		return io.NopCloser(bytes.NewReader(doc)), nil
+	}
+
+	platforms := map[string]json.RawMessage{}
+
+	for k, doc := range sboms {
+		if strings.HasPrefix(k, *opts.Service+"@") {
+			platforms[strings.TrimPrefix(k, *opts.Service+"@")] = doc
+		}
+	}
+
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("no sbom for service %s in build: %s", *opts.Service, id)
+	}
+
+	data, err := json.Marshal(platforms)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
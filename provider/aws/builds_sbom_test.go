@@ -0,0 +1,139 @@
+package aws_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSbom(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleBuildGetItemSbom,
+		cycleObjectListStackResources,
+		cycleBuildFetchSbom,
+	)
+	defer provider.Close()
+
+	r, err := provider.BuildSbom("httpd", "B123", structs.BuildSbomOptions{})
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"web":{"bomFormat":"CycloneDX","version":1},"worker":{"bomFormat":"CycloneDX","version":2}}`, string(data))
+}
+
+func TestBuildSbomService(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleBuildGetItemSbom,
+		cycleObjectListStackResources,
+		cycleBuildFetchSbom,
+	)
+	defer provider.Close()
+
+	r, err := provider.BuildSbom("httpd", "B123", structs.BuildSbomOptions{Service: options.String("web")})
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"bomFormat":"CycloneDX","version":1}`, string(data))
+}
+
+func TestBuildSbomServicePlatforms(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleBuildGetItemSbom,
+		cycleObjectListStackResources,
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				Method:     "GET",
+				RequestURI: "/convox-httpd-settings-139bidzalmbtu/build/B123/sbom",
+			},
+			Response: awsutil.Response{
+				StatusCode: 200,
+				Body:       `{"web@linux/amd64":{"bomFormat":"CycloneDX","version":1},"web@linux/arm64":{"bomFormat":"CycloneDX","version":2},"web2":{"bomFormat":"CycloneDX","version":3}}`,
+			},
+		},
+	)
+	defer provider.Close()
+
+	r, err := provider.BuildSbom("httpd", "B123", structs.BuildSbomOptions{Service: options.String("web")})
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"linux/amd64":{"bomFormat":"CycloneDX","version":1},"linux/arm64":{"bomFormat":"CycloneDX","version":2}}`, string(data))
+}
+
+func TestBuildSbomUnknownService(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleBuildGetItemSbom,
+		cycleObjectListStackResources,
+		cycleBuildFetchSbom,
+	)
+	defer provider.Close()
+
+	_, err := provider.BuildSbom("httpd", "B123", structs.BuildSbomOptions{Service: options.String("other")})
+	require.EqualError(t, err, "no sbom for service other in build: B123")
+}
+
+func TestBuildSbomMissing(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleBuildGetItem,
+	)
+	defer provider.Close()
+
+	_, err := provider.BuildSbom("httpd", "B123", structs.BuildSbomOptions{})
+	require.EqualError(t, err, "no sbom for build: B123")
+}
+
+var cycleBuildGetItemSbom = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "DynamoDB_20120810.GetItem",
+		Body: `{
+			"ConsistentRead": true,
+			"Key": {
+				"id": {
+					"S": "B123"
+				}
+			},
+			"TableName": "convox-builds"
+		}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `{
+			"Item": {
+				"status": {
+					"S": "complete"
+				},
+				"created": {
+					"S": "20160404.143416.178278576"
+				},
+				"app": {
+					"S": "httpd"
+				},
+				"sbom": {
+					"S": "object:///build/B123/sbom"
+				},
+				"id": {
+					"S": "B123"
+				}
+			}
+		}`,
+	},
+}
+
+var cycleBuildFetchSbom = awsutil.Cycle{
+	Request: awsutil.Request{
+		Method:     "GET",
+		RequestURI: "/convox-httpd-settings-139bidzalmbtu/build/B123/sbom",
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body:       `{"web":{"bomFormat":"CycloneDX","version":1},"worker":{"bomFormat":"CycloneDX","version":2}}`,
+	},
+}
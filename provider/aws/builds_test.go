@@ -142,6 +142,7 @@ func TestBuildExport(t *testing.T) {
 		cycleBuildDescribeStacks,
 		cycleBuildDescribeStacks,
 		cycleBuildDescribeRepositories,
+		cycleBuildBatchGetImageNotFound,
 		cycleBuildGetAuthorizationToken,
 	)
 	defer provider.Close()
@@ -155,7 +156,7 @@ func TestBuildExport(t *testing.T) {
 
 	buf := &bytes.Buffer{}
 
-	err := provider.BuildExport("httpd", "B123", buf)
+	err := provider.BuildExport("httpd", "B123", buf, structs.BuildExportOptions{})
 	assert.NoError(t, err)
 
 	gz, err := gzip.NewReader(buf)
@@ -702,6 +703,36 @@ var cycleBuildDescribeTasks = awsutil.Cycle{
 	},
 }
 
+var cycleBuildBatchGetImageNotFound = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "AmazonEC2ContainerRegistry_V20150921.BatchGetImage",
+		Body: `{
+			"acceptedMediaTypes": [
+				"application/vnd.docker.distribution.manifest.v2+json",
+				"application/vnd.docker.distribution.manifest.list.v2+json",
+				"application/vnd.oci.image.manifest.v1+json",
+				"application/vnd.oci.image.index.v1+json"
+			],
+			"imageIds": [{"imageTag": "web.BAFVEWUCAYT"}],
+			"repositoryName": "convox-httpd-hqvvfosgxt"
+		}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `{
+			"failures": [
+				{
+					"failureCode": "ImageNotFound",
+					"failureReason": "Requested image not found",
+					"imageId": {"imageTag": "web.BAFVEWUCAYT"}
+				}
+			],
+			"images": []
+		}`,
+	},
+}
+
 var cycleBuildGetAuthorizationToken = awsutil.Cycle{
 	Request: awsutil.Request{
 		RequestURI: "/",
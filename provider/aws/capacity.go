@@ -3,9 +3,16 @@ package aws
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/rack/pkg/capacity"
+	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/manifest"
+	"github.com/convox/rack/pkg/manifest1"
 	"github.com/convox/rack/pkg/structs"
 )
 
@@ -177,3 +184,373 @@ func (p *Provider) clusterServices() (ECSServices, error) {
 
 	return services, nil
 }
+
+// capacityService is the scale of a service and the host ports each of its
+// tasks reserves
+type capacityService struct {
+	Count  int
+	Cpu    int64
+	Memory int64
+	Ports  []int64
+}
+
+// CapacityPlan simulates a scale change to a service, or the promotion of a
+// release, by packing the tasks it would start onto the free resources of the
+// current instances
+func (p *Provider) CapacityPlan(opts structs.CapacityPlanOptions) (*structs.CapacityPlan, error) {
+	log := Logger.At("CapacityPlan").Start()
+
+	app := cs(opts.App, "")
+	service := cs(opts.Service, "")
+	release := cs(opts.Release, "")
+
+	if app == "" {
+		return nil, log.Error(fmt.Errorf("app required"))
+	}
+
+	if service == "" && release == "" {
+		return nil, log.Error(fmt.Errorf("service or release required"))
+	}
+
+	targets, err := p.capacityTargets(app, service, release, opts)
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	ires, err := p.listAndDescribeContainerInstances()
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	nodes := map[string]*capacity.Node{}
+	empty := capacity.Node{}
+
+	for _, ci := range ires.ContainerInstances {
+		if ci.Status == nil || *ci.Status != "ACTIVE" {
+			continue
+		}
+
+		n := capacityNode(ci.RemainingResources)
+		n.Id = *ci.Ec2InstanceId
+		nodes[*ci.ContainerInstanceArn] = &n
+
+		if r := capacityNode(ci.RegisteredResources); r.Memory > empty.Memory {
+			empty = r
+		}
+	}
+
+	services, err := p.clusterServices()
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	groups := map[string]structs.CapacityPending{}
+	tasks := []capacity.Task{}
+
+	add := func(app, service string, svc capacityService, count int) {
+		group := fmt.Sprintf("%s/%s", app, service)
+
+		groups[group] = structs.CapacityPending{App: app, Service: service}
+
+		for i := 0; i < count; i++ {
+			tasks = append(tasks, capacity.Task{Group: group, Cpu: svc.Cpu, Memory: svc.Memory, Ports: svc.Ports})
+		}
+	}
+
+	seen := map[string]bool{}
+
+	for _, s := range services {
+		if s.LaunchType != nil && *s.LaunchType != "EC2" {
+			continue
+		}
+
+		sa, sn, current, err := p.capacityTaskDefinition(s.TaskDefinition)
+		if err != nil {
+			return nil, log.Error(err)
+		}
+
+		if sa != app || (release == "" && sn != service) {
+			missing := int(ci(s.DesiredCount, 0) - ci(s.RunningCount, 0) - ci(s.PendingCount, 0))
+			add(sa, sn, current, missing)
+			continue
+		}
+
+		// tasks of a changed service are replaced so their resources are freed
+		if err := p.capacityFree(nodes, s.ServiceName); err != nil {
+			return nil, log.Error(err)
+		}
+
+		seen[sn] = true
+
+		if t, ok := targets[sn]; ok {
+			if t.Ports == nil {
+				t.Ports = current.Ports
+			}
+			add(app, sn, t, t.Count)
+		}
+	}
+
+	for name, t := range targets {
+		if !seen[name] {
+			add(app, name, t, t.Count)
+		}
+	}
+
+	ns := []capacity.Node{}
+
+	for _, n := range nodes {
+		ns = append(ns, *n)
+	}
+
+	sort.Slice(ns, func(i, j int) bool { return ns[i].Id < ns[j].Id })
+
+	r := capacity.Plan(ns, empty, tasks)
+
+	plan := &structs.CapacityPlan{
+		Fits:              r.Fits(),
+		Instances:         len(ns),
+		InstancesRequired: len(ns) + r.Added,
+		Pending:           structs.CapacityPendings{},
+	}
+
+	for group, count := range r.Pending {
+		cp := groups[group]
+		cp.Count = count
+		cp.Oversized = r.Oversized[group] > 0
+		plan.Pending = append(plan.Pending, cp)
+	}
+
+	sort.Slice(plan.Pending, func(i, j int) bool {
+		if plan.Pending[i].App != plan.Pending[j].App {
+			return plan.Pending[i].App < plan.Pending[j].App
+		}
+		return plan.Pending[i].Service < plan.Pending[j].Service
+	})
+
+	log.Success()
+
+	return plan, nil
+}
+
+// capacityTargets returns the scale each service of the app would have after
+// the change
+func (p *Provider) capacityTargets(app, service, release string, opts structs.CapacityPlanOptions) (map[string]capacityService, error) {
+	a, err := p.AppGet(app)
+	if err != nil {
+		return nil, err
+	}
+
+	formation := func(name, def string) (capacityService, error) {
+		return capacityFormation(coalesces(a.Parameters[fmt.Sprintf("%sFormation", upperName(name))], def))
+	}
+
+	targets := map[string]capacityService{}
+
+	if release == "" {
+		t, err := formation(service, "")
+		if err != nil {
+			return nil, fmt.Errorf("could not read formation for service: %s", service)
+		}
+
+		if opts.Count != nil {
+			t.Count = *opts.Count
+		}
+
+		if opts.Cpu != nil {
+			t.Cpu = int64(*opts.Cpu)
+		}
+
+		if opts.Memory != nil {
+			t.Memory = int64(*opts.Memory)
+		}
+
+		targets[service] = t
+
+		return targets, nil
+	}
+
+	r, err := p.ReleaseGet(app, release)
+	if err != nil {
+		return nil, err
+	}
+
+	// existing services keep their formation, new ones start at the manifest defaults
+	switch a.Tags["Generation"] {
+	case "", "1":
+		m, err := manifest1.Load([]byte(r.Manifest))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ms := range m.Services {
+			if targets[ms.Name], err = formation(ms.Name, ms.DefaultParams()); err != nil {
+				return nil, err
+			}
+		}
+	case "2":
+		env, err := helpers.AppEnvironment(p, app)
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := manifest.Load([]byte(r.Manifest), env)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ms := range m.Services {
+			def := fmt.Sprintf("%d,%d,%d", ms.Scale.Count.Min, ms.Scale.Cpu, ms.Scale.Memory)
+
+			if targets[ms.Name], err = formation(ms.Name, def); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown generation for app: %s", app)
+	}
+
+	return targets, nil
+}
+
+// capacityFree returns the resources held by the running tasks of an ecs
+// service to the nodes they run on
+func (p *Provider) capacityFree(nodes map[string]*capacity.Node, service *string) error {
+	arns := []*string{}
+
+	err := p.ecs().ListTasksPages(&ecs.ListTasksInput{
+		Cluster:     aws.String(p.Cluster),
+		ServiceName: service,
+	}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+		arns = append(arns, page.TaskArns...)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(arns); i += 100 {
+		res, err := p.describeTasks(&ecs.DescribeTasksInput{
+			Cluster: aws.String(p.Cluster),
+			Tasks:   arns[i:min(i+100, len(arns))],
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, t := range res.Tasks {
+			n, ok := nodes[cs(t.ContainerInstanceArn, "")]
+			if !ok {
+				continue
+			}
+
+			_, _, svc, err := p.capacityTaskDefinition(t.TaskDefinitionArn)
+			if err != nil {
+				return err
+			}
+
+			n.Cpu += svc.Cpu
+			n.Memory += svc.Memory
+
+			for _, port := range svc.Ports {
+				delete(n.Ports, port)
+			}
+		}
+	}
+
+	return nil
+}
+
+// capacityTaskDefinition returns the app, service and per task resources of
+// a task definition
+func (p *Provider) capacityTaskDefinition(arn *string) (string, string, capacityService, error) {
+	res, err := p.describeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: arn,
+	})
+	if err != nil {
+		return "", "", capacityService{}, err
+	}
+
+	app := ""
+	service := ""
+	svc := capacityService{Ports: []int64{}}
+
+	for _, cd := range res.TaskDefinition.ContainerDefinitions {
+		if v := cd.DockerLabels["convox.app"]; v != nil {
+			app = *v
+		}
+
+		for _, e := range cd.Environment {
+			switch cs(e.Name, "") {
+			case "APP":
+				app = coalesces(app, *e.Value)
+			case "PROCESS", "SERVICE":
+				service = coalesces(service, *e.Value)
+			}
+		}
+
+		if cd.Cpu != nil {
+			svc.Cpu += *cd.Cpu
+		}
+
+		svc.Memory += ci(cd.Memory, ci(cd.MemoryReservation, 0))
+
+		for _, pm := range cd.PortMappings {
+			if hp := ci(pm.HostPort, 0); hp > 0 {
+				svc.Ports = append(svc.Ports, hp)
+			}
+		}
+	}
+
+	return app, service, svc, nil
+}
+
+func capacityFormation(formation string) (capacityService, error) {
+	parts := strings.Split(formation, ",")
+
+	if len(parts) < 3 {
+		return capacityService{}, fmt.Errorf("invalid formation: %s", formation)
+	}
+
+	count, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return capacityService{}, err
+	}
+
+	cpu, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return capacityService{}, err
+	}
+
+	memory, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return capacityService{}, err
+	}
+
+	// a count of -1 disables a generation 1 service
+	if count < 0 {
+		count = 0
+	}
+
+	return capacityService{Count: count, Cpu: cpu, Memory: memory}, nil
+}
+
+func capacityNode(rs []*ecs.Resource) capacity.Node {
+	n := capacity.Node{Ports: map[int64]bool{}}
+
+	for _, r := range rs {
+		switch cs(r.Name, "") {
+		case "CPU":
+			n.Cpu = ci(r.IntegerValue, 0)
+		case "MEMORY":
+			n.Memory = ci(r.IntegerValue, 0)
+		case "PORTS":
+			for _, s := range r.StringSetValue {
+				if port, err := strconv.ParseInt(*s, 10, 64); err == nil {
+					n.Ports[port] = true
+				}
+			}
+		}
+	}
+
+	return n
+}
@@ -3,6 +3,7 @@ package aws_test
 import (
 	"testing"
 
+	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 	"github.com/convox/rack/pkg/test/awsutil"
 	"github.com/stretchr/testify/assert"
@@ -35,6 +36,49 @@ func TestCapacityGet(t *testing.T) {
 	}, r)
 }
 
+func TestCapacityPlan(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleCapacityPlanDescribeStacks,
+		cycleCapacityListContainerInstances,
+		cycleCapacityDescribeContainerInstances,
+		cycleCapacityListServices,
+		cycleCapacityDescribeServices,
+		cycleCapacityPlanDescribeTaskDefinition,
+		cycleCapacityPlanListTasks,
+		cycleCapacityPlanDescribeTasks,
+		cycleCapacityPlanDescribeTaskDefinition,
+	)
+	defer provider.Close()
+
+	r, err := provider.CapacityPlan(structs.CapacityPlanOptions{
+		App:     options.String("myapp"),
+		Count:   options.Int(10),
+		Memory:  options.Int(1024),
+		Service: options.String("worker"),
+	})
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, &structs.CapacityPlan{
+		Fits:              false,
+		Instances:         3,
+		InstancesRequired: 10,
+		Pending: structs.CapacityPendings{
+			{App: "myapp", Service: "worker", Count: 7},
+		},
+	}, r)
+}
+
+func TestCapacityPlanOptions(t *testing.T) {
+	provider := StubAwsProvider()
+	defer provider.Close()
+
+	_, err := provider.CapacityPlan(structs.CapacityPlanOptions{})
+	assert.EqualError(t, err, "app required")
+
+	_, err = provider.CapacityPlan(structs.CapacityPlanOptions{App: options.String("myapp")})
+	assert.EqualError(t, err, "service or release required")
+}
+
 var cycleCapacityDescribeContainerInstances = awsutil.Cycle{
 	awsutil.Request{
 		RequestURI: "/",
@@ -304,3 +348,99 @@ var cycleCapacityListServices = awsutil.Cycle{
 		Body:       `{"serviceArns":["arn:aws:ecs:us-west-2:901416387788:service/convox-test-myapp-staging-worker-SCELGCIYSKF"]}`,
 	},
 }
+
+var cycleCapacityPlanDescribeStacks = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Body:       `Action=DescribeStacks&StackName=convox-myapp&Version=2010-05-15`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `
+			<DescribeStacksResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/">
+				<DescribeStacksResult>
+					<Stacks>
+						<member>
+							<Tags>
+								<member><Key>Name</Key><Value>myapp</Value></member>
+								<member><Key>Type</Key><Value>app</Value></member>
+								<member><Key>System</Key><Value>convox</Value></member>
+								<member><Key>Rack</Key><Value>convox</Value></member>
+							</Tags>
+							<StackId>arn:aws:cloudformation:us-east-1:132866487567:stack/convox-myapp/53df3c30-f763-11e5-bd5d-50d5cd148236</StackId>
+							<StackStatus>UPDATE_COMPLETE</StackStatus>
+							<StackName>convox-myapp</StackName>
+							<CreationTime>2016-03-31T17:09:28.583Z</CreationTime>
+							<Parameters>
+								<member><ParameterKey>WorkerFormation</ParameterKey><ParameterValue>2,200,256</ParameterValue></member>
+							</Parameters>
+						</member>
+					</Stacks>
+				</DescribeStacksResult>
+				<ResponseMetadata>
+					<RequestId>d5220358-f764-11e5-8dd2-4fd8ab3b2e1b</RequestId>
+				</ResponseMetadata>
+			</DescribeStacksResponse>
+		`,
+	},
+}
+
+var cycleCapacityPlanDescribeTaskDefinition = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "AmazonEC2ContainerServiceV20141113.DescribeTaskDefinition",
+		Body:       `{"taskDefinition":"arn:aws:ecs:us-west-2:901416387788:task-definition/convox-test-myapp-staging-worker:1"}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `{
+			"taskDefinition":{
+				"family":"convox-test-myapp-staging-worker",
+				"containerDefinitions":[
+					{
+						"name":"worker",
+						"cpu":200,
+						"memory":256,
+						"image":"test-image:1",
+						"environment":[{"name":"APP","value":"myapp"},{"name":"PROCESS","value":"worker"}],
+						"portMappings":[{"hostPort":5000,"containerPort":80}]
+					}
+				]
+			}
+		}`,
+	},
+}
+
+var cycleCapacityPlanDescribeTasks = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "AmazonEC2ContainerServiceV20141113.DescribeTasks",
+		Body:       `{"cluster":"cluster-test","tasks":["arn:aws:ecs:us-east-1:901416387788:task/320a8b6a-c243-47d3-a1d1-6db5dfcb3f58"]}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `{
+			"tasks": [
+				{
+					"containerInstanceArn": "arn:aws:ecs:us-east-1:901416387788:container-instance/0ac4bb1c-be98-4202-a9c1-03153e91c05e",
+					"lastStatus": "RUNNING",
+					"taskArn": "arn:aws:ecs:us-east-1:901416387788:task/320a8b6a-c243-47d3-a1d1-6db5dfcb3f58",
+					"taskDefinitionArn": "arn:aws:ecs:us-west-2:901416387788:task-definition/convox-test-myapp-staging-worker:1"
+				}
+			],
+			"failures": []
+		}`,
+	},
+}
+
+var cycleCapacityPlanListTasks = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "AmazonEC2ContainerServiceV20141113.ListTasks",
+		Body:       `{"cluster":"cluster-test","serviceName":"convox-test-myapp-staging-worker-SCELGCIYSKF"}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body:       `{"taskArns":["arn:aws:ecs:us-east-1:901416387788:task/320a8b6a-c243-47d3-a1d1-6db5dfcb3f58"]}`,
+	},
+}
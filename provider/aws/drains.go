@@ -0,0 +1,187 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/convox/rack/pkg/structs"
+)
+
+// the drain lambda uses the subscription filter name to find the app
+const drainFilterPrefix = "convox-drains:"
+
+func (p *Provider) DrainCreate(app, name, url string, opts structs.DrainCreateOptions) (*structs.Drain, error) {
+	if p.DrainFunction == "" {
+		return nil, fmt.Errorf("drains require a rack update")
+	}
+
+	if _, err := p.AppGet(app); err != nil {
+		return nil, err
+	}
+
+	d, err := structs.NewDrain(app, name, url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := p.SettingExists(drainKey(app, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return nil, fmt.Errorf("drain already exists: %s", name)
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	// the drain is only saved once its app logs are subscribed so that a
+	// failed subscription leaves no drain behind
+	group, err := p.appOutput(app, "LogGroup")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.cloudwatchlogs().PutSubscriptionFilter(&cloudwatchlogs.PutSubscriptionFilterInput{
+		DestinationArn: aws.String(p.DrainFunction),
+		FilterName:     aws.String(drainFilterPrefix + app),
+		FilterPattern:  aws.String(""),
+		LogGroupName:   aws.String(group),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.SettingPut(drainKey(app, name), string(data)); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (p *Provider) DrainDelete(app, name string) error {
+	if err := p.drainExists(app, name); err != nil {
+		return err
+	}
+
+	if err := p.SettingDelete(drainKey(app, name)); err != nil {
+		return err
+	}
+
+	ds, err := p.DrainList(app)
+	if err != nil {
+		return err
+	}
+
+	if len(ds) > 0 {
+		return nil
+	}
+
+	return p.drainUnsubscribe(app)
+}
+
+func (p *Provider) DrainList(app string) (structs.Drains, error) {
+	keys, err := p.SettingList(structs.SettingListOptions{Prefix: drainKey(app, "")})
+	if err != nil {
+		return nil, err
+	}
+
+	ds := structs.Drains{}
+
+	for _, key := range keys {
+		data, err := p.SettingGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		var d structs.Drain
+
+		if err := json.Unmarshal([]byte(data), &d); err != nil {
+			return nil, err
+		}
+
+		ds = append(ds, d)
+	}
+
+	return ds, nil
+}
+
+// DrainTest writes a log line to a stream that the drain lambda only sends to the named drain
+func (p *Provider) DrainTest(app, name string) error {
+	if err := p.drainExists(app, name); err != nil {
+		return err
+	}
+
+	group, err := p.appOutput(app, "LogGroup")
+	if err != nil {
+		return err
+	}
+
+	_, err = p.putLogEvents(&cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(fmt.Sprintf("drain/%s/test", name)),
+		LogEvents: []*cloudwatchlogs.InputLogEvent{
+			{
+				Message:   aws.String(fmt.Sprintf("convox drain test: %s/%s", app, name)),
+				Timestamp: aws.Int64(time.Now().UnixNano() / int64(time.Millisecond)),
+			},
+		},
+	})
+
+	return err
+}
+
+func (p *Provider) drainExists(app, name string) error {
+	exists, err := p.SettingExists(drainKey(app, name))
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return errorNotFound(fmt.Sprintf("drain not found: %s", name))
+	}
+
+	return nil
+}
+
+func (p *Provider) drainsDeleteAll(app string) error {
+	keys, err := p.SettingList(structs.SettingListOptions{Prefix: drainKey(app, "")})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := p.SettingDelete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Provider) drainUnsubscribe(app string) error {
+	group, err := p.appOutput(app, "LogGroup")
+	if err != nil {
+		return err
+	}
+
+	_, err = p.cloudwatchlogs().DeleteSubscriptionFilter(&cloudwatchlogs.DeleteSubscriptionFilterInput{
+		FilterName:   aws.String(drainFilterPrefix + app),
+		LogGroupName: aws.String(group),
+	})
+	if err != nil && awsError(err) != "ResourceNotFoundException" {
+		return err
+	}
+
+	return nil
+}
+
+func drainKey(app, name string) string {
+	return fmt.Sprintf("system/drains/%s/%s", app, name)
+}
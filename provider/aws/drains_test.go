@@ -0,0 +1,166 @@
+package aws_test
+
+import (
+	"testing"
+
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainCreateUnsupported(t *testing.T) {
+	provider := StubAwsProvider()
+	defer provider.Close()
+
+	_, err := provider.DrainCreate("app1", "drain1", "https://logs.example.org", structs.DrainCreateOptions{})
+
+	assert.EqualError(t, err, "drains require a rack update")
+}
+
+func TestDrainCreateSubscribeError(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleDrainDescribeStacks,
+		cycleDrainHeadMissing,
+		cycleDrainDescribeStacks,
+		cycleDrainPutSubscriptionFilterError,
+	)
+	defer provider.Close()
+
+	provider.DrainFunction = "arn:aws:lambda:us-test-1:123456789012:function:convox-drain"
+
+	// the drain is not saved when the subscription fails
+	_, err := provider.DrainCreate("app1", "drain1", "https://logs.example.org", structs.DrainCreateOptions{})
+
+	assert.EqualError(t, err, "LimitExceededException: too many filters")
+}
+
+func TestDrainDeleteNotFound(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleDrainHeadMissing,
+	)
+	defer provider.Close()
+
+	err := provider.DrainDelete("app1", "drain1")
+
+	assert.EqualError(t, err, "drain not found: drain1")
+}
+
+func TestDrainList(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleDrainList,
+		cycleDrainHead,
+		cycleDrainGet,
+		cycleRegistryDecrypt,
+	)
+	defer provider.Close()
+
+	ds, err := provider.DrainList("app1")
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, structs.Drains{
+		structs.Drain{
+			App:      "app1",
+			Name:     "drain1",
+			Services: []string{"web"},
+			Type:     "https",
+			Url:      "https://logs.example.org/ingest",
+		},
+	}, ds)
+}
+
+var cycleDrainDescribeStacks = awsutil.Cycle{
+	Request: awsutil.Request{
+		Method:     "POST",
+		RequestURI: "/",
+		Body:       `Action=DescribeStacks&StackName=convox-app1&Version=2010-05-15`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `<DescribeStacksResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/"><DescribeStacksResult><Stacks><member>
+			<Tags>
+				<member><Key>Name</Key><Value>app1</Value></member>
+				<member><Key>Type</Key><Value>app</Value></member>
+				<member><Key>System</Key><Value>convox</Value></member>
+				<member><Key>Rack</Key><Value>convox</Value></member>
+				<member><Key>Generation</Key><Value>2</Value></member>
+			</Tags>
+			<StackName>convox-app1</StackName>
+			<StackStatus>UPDATE_COMPLETE</StackStatus>
+			<Outputs>
+				<member><OutputKey>LogGroup</OutputKey><OutputValue>convox-app1-LogGroup</OutputValue></member>
+			</Outputs>
+		</member></Stacks></DescribeStacksResult></DescribeStacksResponse>`,
+	},
+}
+
+var cycleDrainPutSubscriptionFilterError = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "Logs_20140328.PutSubscriptionFilter",
+		Body:       `{"destinationArn":"arn:aws:lambda:us-test-1:123456789012:function:convox-drain","filterName":"convox-drains:app1","filterPattern":"","logGroupName":"convox-app1-LogGroup"}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 400,
+		Body:       `{"__type":"LimitExceededException","message":"too many filters"}`,
+	},
+}
+
+var cycleDrainGet = awsutil.Cycle{
+	awsutil.Request{
+		Method:     "GET",
+		RequestURI: "/convox-settings/system/drains/app1/drain1",
+	},
+	awsutil.Response{
+		StatusCode: 200,
+		Body:       `{"c":"rs+ltEZ2kqtwyzdTeqmMMKzbCvaL3ntEib1Iyc/J3qdHqwKrv8U/XkwiWtw8h2UF+6JRKB2jPIJtCdDBSVhXotodnrQlISKir0MW53bjMW26JKc9Uld/aky6p7jJLD33gLh2vWlTNpCqnf/Xpem8YQIJPoAr29jG","k":"AQEBAHhZfaDM9nag/rJj14qS3jZ+uhrcVvAPT3gOpF4GL4TYAAAAAH4wfAYJKoZIhvcNAQcGoG8wbQIBADBoBgkqhkiG9w0BBwEwHgYJYIZIAWUDBAEuMBEEDO0S9xrGCJKPJdHpDQIBEIA7m12OymJ0sCDdru7RxWOQkbnZtR2XO5WMoFUZW1QL9oU31InZ2Gg+NLqYgT5TgZjz1JhPPXur3kku4CU=","n":"AyExwRSvnP1WWaPmCvGy6+AcpCMIWanJ"}`,
+	},
+}
+
+var cycleDrainHead = awsutil.Cycle{
+	awsutil.Request{
+		Method:     "HEAD",
+		RequestURI: "/convox-settings/system/drains/app1/drain1",
+	},
+	awsutil.Response{
+		StatusCode: 200,
+		Body:       "",
+	},
+}
+
+var cycleDrainHeadMissing = awsutil.Cycle{
+	awsutil.Request{
+		Method:     "HEAD",
+		RequestURI: "/convox-settings/system/drains/app1/drain1",
+	},
+	awsutil.Response{
+		StatusCode: 404,
+		Body:       "",
+	},
+}
+
+var cycleDrainList = awsutil.Cycle{
+	awsutil.Request{
+		Method:     "GET",
+		RequestURI: "/convox-settings?delimiter=%2F&list-type=2&prefix=system%2Fdrains%2Fapp1%2F",
+	},
+	awsutil.Response{
+		StatusCode: 200,
+		Body: `
+			<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+				<Name>convox-settings</Name>
+				<Prefix>system/drains/app1/</Prefix>
+				<KeyCount>1</KeyCount>
+				<MaxKeys>1000</MaxKeys>
+				<Delimiter>/</Delimiter>
+				<IsTruncated>false</IsTruncated>
+				<Contents>
+					<Key>system/drains/app1/drain1</Key>
+					<LastModified>2021-01-02T03:04:05.000Z</LastModified>
+					<ETag>&quot;97469e3ca4f6cbec29d79000e1d60054-1&quot;</ETag>
+					<Size>161</Size>
+					<StorageClass>STANDARD</StorageClass>
+				</Contents>
+			</ListBucketResult>
+		`,
+	},
+}
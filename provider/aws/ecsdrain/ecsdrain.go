@@ -0,0 +1,176 @@
+// Package ecsdrain moves the tasks off an ECS container instance before the
+// instance is terminated
+package ecsdrain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+const (
+	DefaultPoll    = 5 * time.Second
+	DefaultTimeout = 5 * time.Minute
+)
+
+// Drainer drains container instances in an ECS cluster
+type Drainer struct {
+	Cluster string
+	ECS     *ecs.ECS
+	Poll    time.Duration
+}
+
+func New(e *ecs.ECS, cluster string) *Drainer {
+	return &Drainer{
+		Cluster: cluster,
+		ECS:     e,
+		Poll:    DefaultPoll,
+	}
+}
+
+// ContainerInstance returns the container instance running on an ec2 instance
+func (d *Drainer) ContainerInstance(id string) (*ecs.ContainerInstance, error) {
+	req := &ecs.ListContainerInstancesInput{
+		Cluster: aws.String(d.Cluster),
+	}
+
+	for {
+		lres, err := d.ECS.ListContainerInstances(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(lres.ContainerInstanceArns) > 0 {
+			dres, err := d.ECS.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+				Cluster:            aws.String(d.Cluster),
+				ContainerInstances: lres.ContainerInstanceArns,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, ci := range dres.ContainerInstances {
+				if aws.StringValue(ci.Ec2InstanceId) == id {
+					return ci, nil
+				}
+			}
+		}
+
+		if lres.NextToken == nil {
+			break
+		}
+
+		req.NextToken = lres.NextToken
+	}
+
+	return nil, fmt.Errorf("could not find cluster instance: %s", id)
+}
+
+// Start sets a container instance to DRAINING so ECS reschedules its service
+// tasks elsewhere, and stops the tasks on it that do not belong to a service
+func (d *Drainer) Start(arn string) error {
+	res, err := d.ECS.UpdateContainerInstancesState(&ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(d.Cluster),
+		ContainerInstances: []*string{aws.String(arn)},
+		Status:             aws.String("DRAINING"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(res.Failures) > 0 {
+		return fmt.Errorf("unable to drain instance: %s - %s", arn, aws.StringValue(res.Failures[0].Reason))
+	}
+
+	return d.stopServicelessTasks(arn)
+}
+
+// Drained returns true once no tasks are left on a container instance
+func (d *Drainer) Drained(arn string) (bool, error) {
+	res, err := d.ECS.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(d.Cluster),
+		ContainerInstances: []*string{aws.String(arn)},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if len(res.ContainerInstances) < 1 {
+		return true, nil
+	}
+
+	ci := res.ContainerInstances[0]
+
+	return aws.Int64Value(ci.RunningTasksCount)+aws.Int64Value(ci.PendingTasksCount) == 0, nil
+}
+
+// Wait polls a container instance until it is drained, returning the error
+// from ctx if it is done first
+func (d *Drainer) Wait(ctx context.Context, arn string) error {
+	for {
+		drained, err := d.Drained(arn)
+		if err != nil {
+			return err
+		}
+
+		if drained {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.Poll):
+		}
+	}
+}
+
+// stopServicelessTasks stops one-off tasks that do not belong to an ECS
+// service, for example a timer or a process started with convox run, as ECS
+// will not move them
+func (d *Drainer) stopServicelessTasks(arn string) error {
+	arns := []*string{}
+
+	err := d.ECS.ListTasksPages(&ecs.ListTasksInput{
+		Cluster:           aws.String(d.Cluster),
+		ContainerInstance: aws.String(arn),
+		DesiredStatus:     aws.String("RUNNING"),
+	}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+		arns = append(arns, page.TaskArns...)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(arns); i += 100 {
+		res, err := d.ECS.DescribeTasks(&ecs.DescribeTasksInput{
+			Cluster: aws.String(d.Cluster),
+			Tasks:   arns[i:min(i+100, len(arns))],
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, t := range res.Tasks {
+			if strings.HasPrefix(aws.StringValue(t.Group), "service:") || strings.HasPrefix(aws.StringValue(t.StartedBy), "ecs-svc") {
+				continue
+			}
+
+			_, err := d.ECS.StopTask(&ecs.StopTaskInput{
+				Cluster: aws.String(d.Cluster),
+				Reason:  aws.String("draining instance for termination"),
+				Task:    t.TaskArn,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package ecsdrain_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/convox/rack/provider/aws/ecsdrain"
+	"github.com/stretchr/testify/require"
+)
+
+const testArn = "arn:aws:ecs:us-east-1:123456789012:container-instance/ci-1"
+
+func testDrainer(cycles ...awsutil.Cycle) (*ecsdrain.Drainer, func()) {
+	s := httptest.NewServer(awsutil.NewHandler(cycles))
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials("test-access", "test-secret", ""),
+		Endpoint:    aws.String(s.URL),
+		Region:      aws.String("us-test-1"),
+	}))
+
+	d := ecsdrain.New(ecs.New(sess), "cluster-test")
+	d.Poll = time.Millisecond
+
+	return d, s.Close
+}
+
+func TestContainerInstance(t *testing.T) {
+	d, closer := testDrainer(
+		cycleECS("ListContainerInstances", `{"cluster":"cluster-test"}`, `{"containerInstanceArns":["`+testArn+`"]}`),
+		cycleECS("DescribeContainerInstances", `{"cluster":"cluster-test","containerInstances":["`+testArn+`"]}`, `{"containerInstances":[{"containerInstanceArn":"`+testArn+`","ec2InstanceId":"i-1"}]}`),
+		cycleECS("ListContainerInstances", `{"cluster":"cluster-test"}`, `{"containerInstanceArns":[]}`),
+	)
+	defer closer()
+
+	ci, err := d.ContainerInstance("i-1")
+	require.NoError(t, err)
+	require.Equal(t, testArn, aws.StringValue(ci.ContainerInstanceArn))
+
+	_, err = d.ContainerInstance("i-2")
+	require.EqualError(t, err, "could not find cluster instance: i-2")
+}
+
+func TestStart(t *testing.T) {
+	d, closer := testDrainer(
+		cycleECS("UpdateContainerInstancesState", `{"cluster":"cluster-test","containerInstances":["`+testArn+`"],"status":"DRAINING"}`, `{"containerInstances":[]}`),
+		cycleECS("ListTasks", `{"cluster":"cluster-test","containerInstance":"`+testArn+`","desiredStatus":"RUNNING"}`, `{"taskArns":["task-1","task-2"]}`),
+		cycleECS("DescribeTasks", `{"cluster":"cluster-test","tasks":["task-1","task-2"]}`, `{"tasks":[{"taskArn":"task-1","group":"service:web"},{"taskArn":"task-2","group":"family:app-timer"}]}`),
+		cycleECS("StopTask", `{"cluster":"cluster-test","reason":"draining instance for termination","task":"task-2"}`, `{}`),
+	)
+	defer closer()
+
+	require.NoError(t, d.Start(testArn))
+}
+
+func TestStartFailure(t *testing.T) {
+	d, closer := testDrainer(
+		cycleECS("UpdateContainerInstancesState", `{"cluster":"cluster-test","containerInstances":["`+testArn+`"],"status":"DRAINING"}`, `{"failures":[{"arn":"`+testArn+`","reason":"MISSING"}]}`),
+	)
+	defer closer()
+
+	require.EqualError(t, d.Start(testArn), "unable to drain instance: "+testArn+" - MISSING")
+}
+
+func TestWait(t *testing.T) {
+	d, closer := testDrainer(
+		cycleDescribe(1, 1),
+		cycleDescribe(1, 0),
+		cycleDescribe(0, 0),
+	)
+	defer closer()
+
+	require.NoError(t, d.Wait(context.Background(), testArn))
+}
+
+func TestWaitCanceled(t *testing.T) {
+	d, closer := testDrainer(
+		cycleDescribe(1, 0),
+	)
+	defer closer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Equal(t, context.Canceled, d.Wait(ctx, testArn))
+}
+
+func cycleDescribe(running, pending int) awsutil.Cycle {
+	return cycleECS(
+		"DescribeContainerInstances",
+		`{"cluster":"cluster-test","containerInstances":["`+testArn+`"]}`,
+		`{"containerInstances":[{"containerInstanceArn":"`+testArn+`","pendingTasksCount":`+strconv.Itoa(pending)+`,"runningTasksCount":`+strconv.Itoa(running)+`}]}`,
+	)
+}
+
+func cycleECS(operation, req, res string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "AmazonEC2ContainerServiceV20141113." + operation,
+			Body:       req,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       res,
+		},
+	}
+}
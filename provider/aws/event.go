@@ -2,13 +2,21 @@ package aws
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/convox/rack/pkg/helpers"
 	"github.com/convox/rack/pkg/structs"
 )
 
+// events are kept in the history table for this long
+const eventRetention = 30 * 24 * time.Hour
+
 // EventSend publishes an important message out to the world.
 //
 // On AWS messages are published to SNS. The Rack has an HTTP endpoint that is an SNS
@@ -20,6 +28,8 @@ import (
 //
 // Because these are important system events, they are also published to Segment
 // for operational metrics.
+//
+// Every event is also recorded in the rack event history, see EventList.
 
 type event struct {
 	Action    string            `json:"action"`
@@ -69,5 +79,125 @@ func (p *Provider) EventSend(action string, opts structs.EventSendOptions) error
 		return err
 	}
 
+	if err := p.eventSave(e); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// EventList returns the newest events in the time range that match the filters, oldest first
+func (p *Provider) EventList(opts structs.EventListOptions) (structs.Events, error) {
+	if p.DynamoEvents == "" {
+		return nil, fmt.Errorf("event history requires a rack update")
+	}
+
+	limit := 100
+	if opts.Limit != nil {
+		limit = *opts.Limit
+	}
+
+	since := 24 * time.Hour
+	if opts.Since != nil {
+		since = *opts.Since
+	}
+
+	now := helpers.TimeNow().UTC()
+
+	start := now.Add(-since)
+	end := now
+
+	if opts.Until != nil {
+		end = now.Add(-*opts.Until)
+	}
+
+	req := &dynamodb.QueryInput{
+		KeyConditions: map[string]*dynamodb.Condition{
+			"rack": {
+				AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String(p.Rack)}},
+				ComparisonOperator: aws.String("EQ"),
+			},
+			"created": {
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{S: aws.String(start.Format(sortableTime))},
+					{S: aws.String(end.Format(sortableTime))},
+				},
+				ComparisonOperator: aws.String("BETWEEN"),
+			},
+		},
+		IndexName:        aws.String("rack.created"),
+		ScanIndexForward: aws.Bool(false),
+		TableName:        aws.String(p.DynamoEvents),
+	}
+
+	es := structs.Events{}
+
+	err := p.dynamodb().QueryPages(req, func(res *dynamodb.QueryOutput, last bool) bool {
+		for _, item := range res.Items {
+			e := eventFromItem(item)
+
+			if !e.Match(opts) {
+				continue
+			}
+
+			es = append(es, e)
+
+			if len(es) >= limit {
+				return false
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(es, es.Less)
+
+	return es, nil
+}
+
+func (p *Provider) eventSave(e event) error {
+	if p.DynamoEvents == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.dynamodb().PutItem(&dynamodb.PutItemInput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"id":      {S: aws.String(generateId("E", 10))},
+			"action":  {S: aws.String(e.Action)},
+			"created": {S: aws.String(e.Timestamp.Format(sortableTime))},
+			"data":    {B: data},
+			"expires": {N: aws.String(strconv.FormatInt(e.Timestamp.Add(eventRetention).Unix(), 10))},
+			"rack":    {S: aws.String(p.Rack)},
+			"status":  {S: aws.String(e.Status)},
+		},
+		TableName: aws.String(p.DynamoEvents),
+	})
+
+	return err
+}
+
+func eventFromItem(item map[string]*dynamodb.AttributeValue) structs.Event {
+	created, _ := time.Parse(sortableTime, coalesce(item["created"], ""))
+
+	data := map[string]string{}
+
+	if item["data"] != nil {
+		json.Unmarshal(item["data"].B, &data)
+	}
+
+	return structs.Event{
+		Id:        coalesce(item["id"], ""),
+		Action:    coalesce(item["action"], ""),
+		Data:      data,
+		Status:    coalesce(item["status"], ""),
+		Timestamp: created,
+	}
+}
@@ -0,0 +1,113 @@
+package aws_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/helpers"
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventListUnsupported(t *testing.T) {
+	provider := StubAwsProvider()
+	defer provider.Close()
+
+	_, err := provider.EventList(structs.EventListOptions{})
+
+	assert.EqualError(t, err, "event history requires a rack update")
+}
+
+func TestEventList(t *testing.T) {
+	now := helpers.TimeNow
+	helpers.TimeNow = func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { helpers.TimeNow = now }()
+
+	provider := StubAwsProvider(
+		cycleEventQuery(``, `{"Items":[`+eventItem("E3", "release:promote", "app1", "20210102.030000.000000000")+`,`+eventItem("E2", "app:create", "app2", "20210102.020000.000000000")+`],"LastEvaluatedKey":{"id":{"S":"E2"}}}`),
+		cycleEventQuery(`,"ExclusiveStartKey":{"id":{"S":"E2"}}`, `{"Items":[`+eventItem("E1", "release:create", "app1", "20210102.010000.000000000")+`,`+eventItem("E0", "release:create", "app1", "20210102.000000.000000000")+`]}`),
+	)
+	defer provider.Close()
+
+	provider.DynamoEvents = "convox-events"
+
+	// app2 is filtered out and the search stops at the limit on the second page
+	es, err := provider.EventList(structs.EventListOptions{
+		Action: options.String("release:*"),
+		App:    options.String("app1"),
+		Limit:  options.Int(2),
+		Since:  options.Duration(4 * time.Hour),
+		Until:  options.Duration(time.Hour),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, structs.Events{
+		{Id: "E1", Action: "release:create", Data: map[string]string{"app": "app1"}, Status: "success", Timestamp: time.Date(2021, 1, 2, 1, 0, 0, 0, time.UTC)},
+		{Id: "E3", Action: "release:promote", Data: map[string]string{"app": "app1"}, Status: "success", Timestamp: time.Date(2021, 1, 2, 3, 0, 0, 0, time.UTC)},
+	}, es)
+}
+
+func TestEventSendHistory(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleEventPublish,
+		cycleEventPutItem,
+	)
+	defer provider.Close()
+
+	provider.DynamoEvents = "convox-events"
+
+	err := provider.EventSend("app:create", structs.EventSendOptions{Data: map[string]string{"app": "app1"}})
+
+	assert.NoError(t, err)
+}
+
+func cycleEventQuery(start, items string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.Query",
+			Body:       `{"IndexName":"rack.created","KeyConditions":{"created":{"AttributeValueList":[{"S":"20210101.230405.000000000"},{"S":"20210102.020405.000000000"}],"ComparisonOperator":"BETWEEN"},"rack":{"AttributeValueList":[{"S":"convox"}],"ComparisonOperator":"EQ"}},"ScanIndexForward":false,"TableName":"convox-events"` + start + `}`,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       items,
+		},
+	}
+}
+
+// eventItem returns a history record with data {"app":app}
+func eventItem(id, action, app, created string) string {
+	data := map[string]string{
+		"app1": "eyJhcHAiOiJhcHAxIn0=",
+		"app2": "eyJhcHAiOiJhcHAyIn0=",
+	}
+
+	return `{"action":{"S":"` + action + `"},"created":{"S":"` + created + `"},"data":{"B":"` + data[app] + `"},"id":{"S":"` + id + `"},"rack":{"S":"convox"},"status":{"S":"success"}}`
+}
+
+var cycleEventPublish = awsutil.Cycle{
+	Request: awsutil.Request{
+		Method:     "POST",
+		RequestURI: "/",
+		Body:       `Action=Publish&Message=%7B%22action%22%3A%22app%3Acreate%22%2C%22data%22%3A%7B%22app%22%3A%22app1%22%2C%22rack%22%3A%22convox%22%7D%2C%22status%22%3A%22success%22%2C%22timestamp%22%3A%220001-01-01T00%3A00%3A00Z%22%7D&Subject=app%3Acreate&TargetArn=&Version=2010-03-31`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body:       `<PublishResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/"><PublishResult><MessageId>m1</MessageId></PublishResult></PublishResponse>`,
+	},
+}
+
+// the history record id is random
+var cycleEventPutItem = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "DynamoDB_20120810.PutItem",
+		Body:       `/^\{"Item":\{"action":\{"S":"app:create"\},"created":\{"S":"00010101.000000.000000000"\},"data":\{"B":"eyJhcHAiOiJhcHAxIiwicmFjayI6ImNvbnZveCJ9"\},"expires":\{"N":"-62133004800"\},"id":\{"S":"E[A-Z]{10}"\},"rack":\{"S":"convox"\},"status":\{"S":"success"\}\},"TableName":"convox-events"\}$/`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body:       `{}`,
+	},
+}
@@ -0,0 +1,136 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/convox/rack/pkg/cache"
+)
+
+const (
+	// generationInterval is how long a process trusts a generation it read,
+	// it bounds how long an invalidated entry is still served
+	generationInterval = 1 * time.Second
+
+	// generationRetention keeps the generation of a scope with no events
+	generationRetention = 30 * 24 * time.Hour
+
+	// generationTTL is how long entries keyed on a generation are cached,
+	// events bump the generation long before they expire
+	generationTTL = 2 * time.Minute
+)
+
+// generationKey returns the cache key and ttl of an entry that events for
+// scope invalidate. The key includes the current generation of the scope so
+// that a bump from any process, like the monitor handling events, replaces
+// the entry in every process. Without a generation the entry is cached for
+// ttl as before.
+func (p *Provider) generationKey(scope string, key interface{}, ttl time.Duration) (interface{}, time.Duration) {
+	if p.SkipCache {
+		return key, ttl
+	}
+
+	gen, err := p.generation(scope)
+	if err != nil || gen == "" {
+		return key, ttl
+	}
+
+	return []interface{}{key, gen}, generationTTL
+}
+
+// generation returns the current generation of scope, racks without a leases
+// table have none
+func (p *Provider) generation(scope string) (string, error) {
+	if p.DynamoLeases == "" {
+		return "", nil
+	}
+
+	v, err := cache.Load("generation", scope, generationInterval, func() (interface{}, error) {
+		res, err := p.dynamodb().GetItem(&dynamodb.GetItemInput{
+			ConsistentRead: aws.Bool(true),
+			Key: map[string]*dynamodb.AttributeValue{
+				"name": {S: aws.String(generationName(scope))},
+			},
+			TableName: aws.String(p.DynamoLeases),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if gen, ok := res.Item["generation"]; ok && gen.N != nil {
+			return *gen.N, nil
+		}
+
+		return "0", nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	gen, _ := v.(string)
+
+	return gen, nil
+}
+
+// bumpGeneration invalidates the entries cached for scope in every process
+func (p *Provider) bumpGeneration(scope string) error {
+	if p.DynamoLeases == "" {
+		return nil
+	}
+
+	_, err := p.dynamodb().UpdateItem(&dynamodb.UpdateItemInput{
+		ExpressionAttributeNames: map[string]*string{
+			"#expires":    aws.String("expires"),
+			"#generation": aws.String("generation"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":expires": {N: aws.String(strconv.FormatInt(time.Now().Add(generationRetention).Unix(), 10))},
+			":one":     {N: aws.String("1")},
+		},
+		Key: map[string]*dynamodb.AttributeValue{
+			"name": {S: aws.String(generationName(scope))},
+		},
+		TableName:        aws.String(p.DynamoLeases),
+		UpdateExpression: aws.String("ADD #generation :one SET #expires = :expires"),
+	})
+	if err != nil {
+		return err
+	}
+
+	// this process sees the new generation without waiting for the interval
+	cache.Clear("generation", scope)
+
+	return nil
+}
+
+func generationName(scope string) string {
+	return fmt.Sprintf("generation/%s", scope)
+}
+
+// stackScope returns the generation scope of a stack given its name or id,
+// a nil stack is the list of all stacks
+func stackScope(stack *string) string {
+	if stack == nil {
+		return "stacks"
+	}
+
+	name := *stack
+
+	// arn:aws:cloudformation:region:account:stack/name/uuid
+	if strings.HasPrefix(name, "arn:") {
+		if parts := strings.Split(name, "/"); len(parts) == 3 {
+			name = parts[1]
+		}
+	}
+
+	return fmt.Sprintf("stack/%s", name)
+}
+
+// tasksScope returns the generation scope of the tasks of an app
+func tasksScope(app string) string {
+	return fmt.Sprintf("tasks/%s", app)
+}
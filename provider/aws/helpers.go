@@ -307,18 +307,6 @@ func randomString(size int) string {
 	return string(b)
 }
 
-func recoverWith(f func(err error)) {
-	if r := recover(); r != nil {
-		// coerce r to error type
-		err, ok := r.(error)
-		if !ok {
-			err = fmt.Errorf("%v", r)
-		}
-
-		f(err)
-	}
-}
-
 func remarshal(v interface{}, w interface{}) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -657,32 +645,38 @@ func (p *Provider) describeServices(input *ecs.DescribeServicesInput) (*ecs.Desc
 }
 
 func (p *Provider) describeStacks(input *cloudformation.DescribeStacksInput) ([]*cloudformation.Stack, error) {
-	var stacks []*cloudformation.Stack
-	stacks, ok := cache.Get("describeStacks", input.StackName).([]*cloudformation.Stack)
+	if p.SkipCache {
+		return p.describeStacksPages(input)
+	}
 
-	if ok {
-		return stacks, nil
+	key, ttl := p.generationKey(stackScope(input.StackName), input.StackName, 5*time.Second)
+
+	// concurrent misses for the same stack share a single api call
+	v, err := cache.Load("describeStacks", key, ttl, func() (interface{}, error) {
+		return p.describeStacksPages(input)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	stacks, _ := v.([]*cloudformation.Stack)
+
+	return stacks, nil
+}
+
+func (p *Provider) describeStacksPages(input *cloudformation.DescribeStacksInput) ([]*cloudformation.Stack, error) {
+	var stacks []*cloudformation.Stack
+
 	err := p.cloudformation().DescribeStacksPages(input,
 		func(page *cloudformation.DescribeStacksOutput, lastPage bool) bool {
-			for _, stack := range page.Stacks {
-				stacks = append(stacks, stack)
-			}
+			stacks = append(stacks, page.Stacks...)
 			return true
 		},
 	)
-
 	if err != nil {
 		return nil, err
 	}
 
-	if !p.SkipCache {
-		if err := cache.Set("describeStacks", input.StackName, stacks, 5*time.Second); err != nil {
-			return nil, err
-		}
-	}
-
 	return stacks, nil
 }
 
@@ -704,7 +698,9 @@ func (p *Provider) describeStack(name string) (*cloudformation.Stack, error) {
 }
 
 func (p *Provider) describeStackEvents(input *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error) {
-	res, ok := cache.Get("describeStackEvents", input.StackName).(*cloudformation.DescribeStackEventsOutput)
+	key, ttl := p.generationKey(stackScope(input.StackName), input.StackName, 5*time.Second)
+
+	res, ok := cache.Get("describeStackEvents", key).(*cloudformation.DescribeStackEventsOutput)
 
 	if ok {
 		return res, nil
@@ -716,7 +712,7 @@ func (p *Provider) describeStackEvents(input *cloudformation.DescribeStackEvents
 	}
 
 	if !p.SkipCache {
-		if err := cache.Set("describeStackEvents", input.StackName, res, 5*time.Second); err != nil {
+		if err := cache.Set("describeStackEvents", key, res, ttl); err != nil {
 			return nil, err
 		}
 	}
@@ -725,7 +721,7 @@ func (p *Provider) describeStackEvents(input *cloudformation.DescribeStackEvents
 }
 
 func (p *Provider) describeStackResource(input *cloudformation.DescribeStackResourceInput) (*cloudformation.DescribeStackResourceOutput, error) {
-	key := fmt.Sprintf("%s.%s", *input.StackName, *input.LogicalResourceId)
+	key, ttl := p.generationKey(stackScope(input.StackName), fmt.Sprintf("%s.%s", *input.StackName, *input.LogicalResourceId), 5*time.Second)
 
 	res, ok := cache.Get("describeStackResource", key).(*cloudformation.DescribeStackResourceOutput)
 
@@ -739,7 +735,7 @@ func (p *Provider) describeStackResource(input *cloudformation.DescribeStackReso
 	}
 
 	if !p.SkipCache {
-		if err := cache.Set("describeStackResource", key, res, 5*time.Second); err != nil {
+		if err := cache.Set("describeStackResource", key, res, ttl); err != nil {
 			return nil, err
 		}
 	}
@@ -748,7 +744,9 @@ func (p *Provider) describeStackResource(input *cloudformation.DescribeStackReso
 }
 
 func (p *Provider) describeStackResources(input *cloudformation.DescribeStackResourcesInput) (*cloudformation.DescribeStackResourcesOutput, error) {
-	res, ok := cache.Get("describeStackResources", input.StackName).(*cloudformation.DescribeStackResourcesOutput)
+	key, ttl := p.generationKey(stackScope(input.StackName), input.StackName, 5*time.Second)
+
+	res, ok := cache.Get("describeStackResources", key).(*cloudformation.DescribeStackResourcesOutput)
 
 	if ok {
 		return res, nil
@@ -760,7 +758,7 @@ func (p *Provider) describeStackResources(input *cloudformation.DescribeStackRes
 	}
 
 	if !p.SkipCache {
-		if err := cache.Set("describeStackResources", input.StackName, res, 5*time.Second); err != nil {
+		if err := cache.Set("describeStackResources", key, res, ttl); err != nil {
 			return nil, err
 		}
 	}
@@ -783,7 +781,9 @@ func (p *Provider) stackTemplate(stack string) ([]byte, error) {
 }
 
 func (p *Provider) listStackResources(stack string) ([]*cloudformation.StackResourceSummary, error) {
-	res, ok := cache.Get("listStackResources", stack).([]*cloudformation.StackResourceSummary)
+	key, ttl := p.generationKey(stackScope(&stack), stack, 5*time.Second)
+
+	res, ok := cache.Get("listStackResources", key).([]*cloudformation.StackResourceSummary)
 	if ok {
 		return res, nil
 	}
@@ -810,7 +810,7 @@ func (p *Provider) listStackResources(stack string) ([]*cloudformation.StackReso
 	}
 
 	if !p.SkipCache {
-		if err := cache.Set("listStackResources", stack, srs, 5*time.Second); err != nil {
+		if err := cache.Set("listStackResources", key, srs, ttl); err != nil {
 			return nil, err
 		}
 	}
@@ -1010,7 +1010,20 @@ func (p *Provider) describeTaskDefinition(input *ecs.DescribeTaskDefinitionInput
 }
 
 func (p *Provider) describeTasks(input *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
-	res, ok := cache.Get("describeTasks", input).(*ecs.DescribeTasksOutput)
+	return p.describeAppTasks("", input)
+}
+
+// describeAppTasks describes tasks of an app, they are cached until a task of
+// the app changes state
+func (p *Provider) describeAppTasks(app string, input *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	var key interface{} = input
+	ttl := 10 * time.Second
+
+	if app != "" {
+		key, ttl = p.generationKey(tasksScope(app), input, ttl)
+	}
+
+	res, ok := cache.Get("describeTasks", key).(*ecs.DescribeTasksOutput)
 
 	if ok {
 		return res, nil
@@ -1023,7 +1036,7 @@ func (p *Provider) describeTasks(input *ecs.DescribeTasksInput) (*ecs.DescribeTa
 	}
 
 	if !p.SkipCache && len(res.Failures) == 0 {
-		if err := cache.Set("describeTasks", input, res, 10*time.Second); err != nil {
+		if err := cache.Set("describeTasks", key, res, ttl); err != nil {
 			return nil, err
 		}
 	}
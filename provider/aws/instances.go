@@ -5,16 +5,26 @@ import (
 	"io"
 	"math/rand"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/provider/aws/ecsdrain"
 	"golang.org/x/crypto/ssh"
 )
 
+const (
+	// container instance attributes for an instance draining to be terminated
+	drainDeadlineAttribute  = "convox.drain.deadline"
+	drainDecrementAttribute = "convox.drain.decrement"
+	drainTerminateAttribute = "convox.drain.terminate"
+)
+
 func (p *Provider) InstanceKeyroll() error {
 	key := fmt.Sprintf("%s-keypair-%d", p.Rack, (rand.Intn(8999) + 1000))
 
@@ -216,32 +226,101 @@ func (p *Provider) InstanceShell(id string, rw io.ReadWriter, opts structs.Insta
 }
 
 func (p *Provider) InstanceTerminate(id string) error {
+	if err := p.instanceExists(id); err != nil {
+		return err
+	}
+
+	return p.terminateInstance(id, false)
+}
+
+// InstanceDrain moves the tasks off an instance. With Terminate set the
+// instance-drain worker terminates it once its tasks have been rescheduled or
+// the timeout passes.
+func (p *Provider) InstanceDrain(id string, opts structs.InstanceDrainOptions) error {
+	if err := p.instanceExists(id); err != nil {
+		return err
+	}
+
+	timeout := ecsdrain.DefaultTimeout
+
+	if opts.Timeout != nil {
+		timeout = time.Duration(*opts.Timeout) * time.Second
+	}
+
+	return p.drainInstance(id, cb(opts.Terminate, false), false, timeout)
+}
+
+func (p *Provider) instanceExists(id string) error {
 	instances, err := p.InstanceList()
 	if err != nil {
 		return err
 	}
 
-	found := false
-
 	for _, i := range instances {
 		if i.Id == id {
-			found = true
-			break
+			return nil
 		}
 	}
 
-	if !found {
-		return errorNotFound(fmt.Sprintf("instance not found: %s", id))
+	return errorNotFound(fmt.Sprintf("instance not found: %s", id))
+}
+
+// drainInstance sets an instance to draining and, when terminating, records
+// on its container instance when the instance-drain worker should give up
+// waiting and whether the autoscaling group should shrink with it
+func (p *Provider) drainInstance(id string, terminate, decrement bool, timeout time.Duration) error {
+	d := ecsdrain.New(p.ecs(), p.Cluster)
+
+	ci, err := d.ContainerInstance(id)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Start(*ci.ContainerInstanceArn); err != nil {
+		return err
 	}
 
-	_, err = p.autoscaling().TerminateInstanceInAutoScalingGroup(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
+	if terminate {
+		attrs := map[string]string{
+			drainDeadlineAttribute:  strconv.FormatInt(time.Now().Add(timeout).Unix(), 10),
+			drainDecrementAttribute: strconv.FormatBool(decrement),
+			drainTerminateAttribute: "true",
+		}
+
+		req := &ecs.PutAttributesInput{
+			Cluster: aws.String(p.Cluster),
+		}
+
+		for _, k := range []string{drainDeadlineAttribute, drainDecrementAttribute, drainTerminateAttribute} {
+			req.Attributes = append(req.Attributes, &ecs.Attribute{
+				Name:       aws.String(k),
+				TargetId:   ci.ContainerInstanceArn,
+				TargetType: aws.String("container-instance"),
+				Value:      aws.String(attrs[k]),
+			})
+		}
+
+		if _, err := p.ecs().PutAttributes(req); err != nil {
+			return err
+		}
+	}
+
+	p.EventSend("instance:drain", structs.EventSendOptions{Data: map[string]string{"id": id, "terminate": strconv.FormatBool(terminate)}})
+
+	return nil
+}
+
+func (p *Provider) terminateInstance(id string, decrement bool) error {
+	_, err := p.autoscaling().TerminateInstanceInAutoScalingGroup(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
 		InstanceId:                     aws.String(id),
-		ShouldDecrementDesiredCapacity: aws.Bool(false),
+		ShouldDecrementDesiredCapacity: aws.Bool(decrement),
 	})
 	if err != nil {
 		return err
 	}
 
+	p.EventSend("instance:terminate", structs.EventSendOptions{Data: map[string]string{"id": id}})
+
 	return nil
 }
 
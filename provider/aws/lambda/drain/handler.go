@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/mweagle/Sparta/aws/cloudwatchlogs"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+const (
+	filterPrefix = "convox-drains:"
+	retries      = 3
+)
+
+// envelope matches the rack setting encryption format
+type envelope struct {
+	Ciphertext   []byte `json:"c"`
+	EncryptedKey []byte `json:"k"`
+	Nonce        []byte `json:"n"`
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+func Handler(ctx context.Context, event cloudwatchlogs.Event) error {
+	d, err := event.AWSLogs.DecodedData()
+	if err != nil {
+		return err
+	}
+
+	app := filterApp(d.SubscriptionFilters)
+	if app == "" {
+		return fmt.Errorf("no drain subscription filter for group: %s", d.LogGroup)
+	}
+
+	s, err := session.NewSession()
+	if err != nil {
+		return err
+	}
+
+	drains, err := loadDrains(s, os.Getenv("SETTINGS_BUCKET"), app)
+	if err != nil {
+		return err
+	}
+
+	id := fmt.Sprintf("%d", time.Now().UnixNano())
+
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		id = lc.AwsRequestID
+	}
+
+	for _, dr := range drains {
+		es, err := entries(dr, d)
+		if err != nil {
+			fmt.Printf("app=%s drain=%s error=%q\n", app, dr.Name, err)
+			continue
+		}
+
+		if len(es) == 0 {
+			continue
+		}
+
+		var failures, successes int
+
+		if err := retry(ctx, func() error { return send(s, dr, id, es) }); err != nil {
+			fmt.Printf("app=%s drain=%s error=%q\n", app, dr.Name, err)
+			failures = len(es)
+		} else {
+			successes = len(es)
+		}
+
+		fmt.Printf("app=%s drain=%s type=%s stream=%s events=%d success=%d failure=%d\n", app, dr.Name, dr.Type, d.LogStream, len(es), successes, failures)
+
+		if err := putMetrics(s, dr, successes, failures); err != nil {
+			fmt.Printf("error=%q\n", err)
+		}
+	}
+
+	return nil
+}
+
+// entries selects the events for a drain, test events written to
+// drain/<name>/test only go to the named drain
+func entries(dr structs.Drain, d *cloudwatchlogs.CloudWatchLogEvent) ([]structs.LogEntry, error) {
+	parts := strings.SplitN(d.LogStream, "/", 3)
+
+	test := len(parts) == 3 && parts[0] == "drain"
+
+	if test && parts[1] != dr.Name {
+		return nil, nil
+	}
+
+	es := []structs.LogEntry{}
+
+	for _, le := range d.LogEvents {
+		if !test {
+			ok, err := dr.Match(d.LogStream, le.Message)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		e := structs.LogEntry{
+			App:       dr.App,
+			Message:   strings.TrimRight(le.Message, "\n"),
+			Timestamp: time.UnixMilli(le.Timestamp).UTC(),
+		}
+
+		if len(parts) == 3 {
+			e.Service = parts[1]
+			e.Process = parts[2]
+		}
+
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+func filterApp(filters []string) string {
+	for _, f := range filters {
+		if strings.HasPrefix(f, filterPrefix) {
+			return strings.TrimPrefix(f, filterPrefix)
+		}
+	}
+
+	return ""
+}
+
+func loadDrains(s *session.Session, bucket, app string) (structs.Drains, error) {
+	S3 := s3.New(s)
+
+	res, err := S3.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(fmt.Sprintf("system/drains/%s/", app)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ds := structs.Drains{}
+
+	for _, item := range res.Contents {
+		ores, err := S3.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: item.Key})
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(ores.Body)
+		ores.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		dec, err := decrypt(s, data)
+		if err != nil {
+			return nil, err
+		}
+
+		var d structs.Drain
+
+		if err := json.Unmarshal(dec, &d); err != nil {
+			return nil, err
+		}
+
+		ds = append(ds, d)
+	}
+
+	return ds, nil
+}
+
+func decrypt(s *session.Session, data []byte) ([]byte, error) {
+	var e envelope
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	if len(e.EncryptedKey) == 0 || len(e.Nonce) < 24 {
+		return nil, fmt.Errorf("invalid ciphertext")
+	}
+
+	res, err := kms.New(s).Decrypt(&kms.DecryptInput{CiphertextBlob: e.EncryptedKey})
+	if err != nil {
+		return nil, err
+	}
+
+	var key [32]byte
+	copy(key[:], res.Plaintext)
+
+	var nonce [24]byte
+	copy(nonce[:], e.Nonce)
+
+	dec, ok := secretbox.Open(nil, e.Ciphertext, &nonce, &key)
+	if !ok {
+		return nil, fmt.Errorf("failed decryption")
+	}
+
+	return dec, nil
+}
+
+func putMetrics(s *session.Session, dr structs.Drain, successes, failures int) error {
+	dimensions := []*cloudwatch.Dimension{
+		{Name: aws.String("App"), Value: aws.String(dr.App)},
+		{Name: aws.String("Drain"), Value: aws.String(dr.Name)},
+	}
+
+	_, err := cloudwatch.New(s).PutMetricData(&cloudwatch.PutMetricDataInput{
+		Namespace: aws.String("Convox/Drains"),
+		MetricData: []*cloudwatch.MetricDatum{
+			{MetricName: aws.String("Failures"), Dimensions: dimensions, Unit: aws.String("Count"), Value: aws.Float64(float64(failures))},
+			{MetricName: aws.String("Successes"), Dimensions: dimensions, Unit: aws.String("Count"), Value: aws.Float64(float64(successes))},
+		},
+	})
+
+	return err
+}
+
+// retry calls fn up to retries more times with exponential backoff
+func retry(ctx context.Context, fn func() error) error {
+	var err error
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(100<<uint(attempt-1)) * time.Millisecond):
+			}
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func send(s *session.Session, dr structs.Drain, id string, es []structs.LogEntry) error {
+	u, err := url.Parse(dr.Url)
+	if err != nil {
+		return err
+	}
+
+	switch dr.Type {
+	case "https":
+		return sendHTTPS(u, es)
+	case "s3":
+		return sendS3(s, u, id, es)
+	case "syslog":
+		return sendSyslog(u, es)
+	default:
+		return fmt.Errorf("unknown drain type: %s", dr.Type)
+	}
+}
+
+func sendHTTPS(u *url.URL, es []structs.LogEntry) error {
+	data, err := json.Marshal(es)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Post(u.String(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("response status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func sendS3(s *session.Session, u *url.URL, id string, es []structs.LogEntry) error {
+	var buf bytes.Buffer
+
+	for _, e := range es {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	_, err := s3.New(s).PutObject(&s3.PutObjectInput{
+		Body:        bytes.NewReader(buf.Bytes()),
+		Bucket:      aws.String(u.Host),
+		ContentType: aws.String("application/x-ndjson"),
+		Key:         aws.String(s3Key(u, id, es)),
+	})
+
+	return err
+}
+
+// s3Key partitions objects by app and day, e.g. prefix/app1/2021/01/02/1609556645000-<id>.log
+func s3Key(u *url.URL, id string, es []structs.LogEntry) string {
+	first := es[0]
+
+	name := fmt.Sprintf("%d-%s.log", first.Timestamp.UnixNano()/int64(time.Millisecond), id)
+
+	return path.Join(strings.Trim(u.Path, "/"), first.App, first.Timestamp.Format("2006/01/02"), name)
+}
+
+func sendSyslog(u *url.URL, es []structs.LogEntry) error {
+	d := &net.Dialer{Timeout: 10 * time.Second}
+
+	var cn net.Conn
+	var err error
+
+	switch u.Scheme {
+	case "tcp", "udp":
+		cn, err = d.Dial(u.Scheme, u.Host)
+	case "tcp+tls":
+		cn, err = tls.DialWithDialer(d, "tcp", u.Host, &tls.Config{ServerName: u.Hostname()})
+	default:
+		return fmt.Errorf("invalid scheme: %s", u.Scheme)
+	}
+	if err != nil {
+		return err
+	}
+	defer cn.Close()
+
+	cn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+
+	// udp sends one message per datagram
+	if u.Scheme == "udp" {
+		for _, e := range es {
+			if _, err := cn.Write([]byte(syslogLine(e))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	for _, e := range es {
+		buf.WriteString(syslogLine(e))
+	}
+
+	_, err = cn.Write(buf.Bytes())
+
+	return err
+}
+
+func syslogLine(e structs.LogEntry) string {
+	return fmt.Sprintf("<22>1 %s %s %s %s - - %s\n", e.Timestamp.Format(time.RFC3339), e.App, coalesce(e.Service, "-"), coalesce(e.Process, "-"), e.Message)
+}
+
+func coalesce(ss ...string) string {
+	for _, s := range ss {
+		if s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/structs"
+	"github.com/mweagle/Sparta/aws/cloudwatchlogs"
+)
+
+func TestEntries(t *testing.T) {
+	d := &cloudwatchlogs.CloudWatchLogEvent{
+		LogStream: "service/web/abcdef",
+		LogEvents: []cloudwatchlogs.LogEvent{
+			{Timestamp: 1609556645000, Message: "GET / 200\n"},
+			{Timestamp: 1609556646000, Message: "error: boom\n"},
+		},
+	}
+
+	cases := []struct {
+		drain structs.Drain
+		want  int
+	}{
+		{structs.Drain{App: "app1", Name: "all"}, 2},
+		{structs.Drain{App: "app1", Name: "web", Services: []string{"web"}}, 2},
+		{structs.Drain{App: "app1", Name: "worker", Services: []string{"worker"}}, 0},
+		{structs.Drain{App: "app1", Name: "process", Processes: []string{"abcdef"}}, 2},
+		{structs.Drain{App: "app1", Name: "errors", Regex: "^error"}, 1},
+	}
+
+	for _, tc := range cases {
+		es, err := entries(tc.drain, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(es) != tc.want {
+			t.Errorf("entries(%s) = %d, want %d", tc.drain.Name, len(es), tc.want)
+		}
+	}
+
+	es, _ := entries(structs.Drain{App: "app1", Name: "all"}, d)
+
+	want := structs.LogEntry{App: "app1", Message: "GET / 200", Process: "abcdef", Service: "web", Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	if es[0] != want {
+		t.Errorf("entry = %+v, want %+v", es[0], want)
+	}
+}
+
+func TestEntriesTest(t *testing.T) {
+	d := &cloudwatchlogs.CloudWatchLogEvent{
+		LogStream: "drain/drain1/test",
+		LogEvents: []cloudwatchlogs.LogEvent{{Timestamp: 1609556645000, Message: "convox drain test: app1/drain1"}},
+	}
+
+	if es, _ := entries(structs.Drain{App: "app1", Name: "drain1", Services: []string{"web"}}, d); len(es) != 1 {
+		t.Errorf("test event not sent to named drain")
+	}
+
+	if es, _ := entries(structs.Drain{App: "app1", Name: "drain2"}, d); len(es) != 0 {
+		t.Errorf("test event sent to other drain")
+	}
+}
+
+func TestFilterApp(t *testing.T) {
+	if got := filterApp([]string{"other", "convox-drains:app1"}); got != "app1" {
+		t.Errorf("filterApp = %q, want %q", got, "app1")
+	}
+
+	if got := filterApp([]string{"other"}); got != "" {
+		t.Errorf("filterApp = %q, want blank", got)
+	}
+}
+
+func TestS3Key(t *testing.T) {
+	u, _ := url.Parse("s3://bucket/logs/")
+
+	es := []structs.LogEntry{{App: "app1", Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}}
+
+	if got, want := s3Key(u, "req1", es), "logs/app1/2021/01/02/1609556645000-req1.log"; got != want {
+		t.Errorf("s3Key = %q, want %q", got, want)
+	}
+}
+
+func TestSendHTTPS(t *testing.T) {
+	var got []structs.LogEntry
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &got)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+
+	es := []structs.LogEntry{{App: "app1", Message: "hello", Service: "web"}}
+
+	if err := sendHTTPS(u, es); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].Message != "hello" {
+		t.Errorf("received %+v", got)
+	}
+}
+
+func TestSendHTTPSFailure(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+
+	if err := sendHTTPS(u, []structs.LogEntry{{Message: "hello"}}); err == nil || err.Error() != "response status 503" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestSendSyslog(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	lines := make(chan string, 1)
+
+	go func() {
+		cn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer cn.Close()
+
+		s := bufio.NewScanner(cn)
+
+		for s.Scan() {
+			lines <- s.Text()
+		}
+	}()
+
+	u := &url.URL{Scheme: "tcp", Host: l.Addr().String()}
+
+	es := []structs.LogEntry{{App: "app1", Message: "hello", Process: "abcdef", Service: "web", Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}}
+
+	if err := sendSyslog(u, es); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := <-lines, "<22>1 2021-01-02T03:04:05Z app1 web abcdef - - hello"; got != want {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
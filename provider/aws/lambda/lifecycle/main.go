@@ -15,10 +15,13 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/rack/provider/aws/ecsdrain"
 )
 
 const (
 	MAX_RETRY = 10
+
+	drainMargin = 30 * time.Second
 )
 
 var (
@@ -84,7 +87,7 @@ func Handler(ctx context.Context, event events.SNSEvent) error {
 	for _, r := range event.Records {
 		switch {
 		case strings.HasPrefix(r.SNS.Subject, "Auto Scaling"):
-			if err := handleAutoscaling(r); err != nil {
+			if err := handleAutoscaling(ctx, r); err != nil {
 				fmt.Printf("err = %+v\n", err)
 			}
 		default:
@@ -95,7 +98,7 @@ func Handler(ctx context.Context, event events.SNSEvent) error {
 	return nil
 }
 
-func handleAutoscaling(r events.SNSEventRecord) error {
+func handleAutoscaling(ctx context.Context, r events.SNSEventRecord) error {
 	fmt.Println("handleAutoscaling")
 
 	var m Termination
@@ -107,7 +110,7 @@ func handleAutoscaling(r events.SNSEventRecord) error {
 	fmt.Printf("m = %+v\n", m)
 
 	if m.LifecycleTransition == "autoscaling:EC2_INSTANCE_TERMINATING" {
-		if err := drainInstance(m.EC2InstanceID); err != nil {
+		if err := drainInstance(ctx, m.EC2InstanceID); err != nil {
 			fmt.Printf("err = %+v\n", err)
 		}
 	}
@@ -128,76 +131,37 @@ func handleAutoscaling(r events.SNSEventRecord) error {
 	return nil
 }
 
-func containerInstance(cluster string, id string) (string, error) {
-	lreq := &ecs.ListContainerInstancesInput{
-		Cluster:    aws.String(cluster),
-		MaxResults: aws.Int64(10),
-	}
-
-	for {
-		lres, err := ECS.ListContainerInstances(lreq)
-		if err != nil {
-			return "", err
-		}
-
-		dres, err := ECS.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
-			Cluster:            aws.String(cluster),
-			ContainerInstances: lres.ContainerInstanceArns,
-		})
-		if err != nil {
-			return "", err
-		}
-
-		for _, ci := range dres.ContainerInstances {
-			if *ci.Ec2InstanceId == id {
-				return *ci.ContainerInstanceArn, nil
-			}
-		}
-
-		if lres.NextToken == nil {
-			break
-		}
-
-		lreq.NextToken = lres.NextToken
-	}
-
-	return "", fmt.Errorf("could not find cluster instance: %s", id)
-}
+func drainInstance(ctx context.Context, id string) error {
+	d := ecsdrain.New(ECS, os.Getenv("CLUSTER"))
 
-func drainInstance(id string) error {
-	cluster := os.Getenv("CLUSTER")
-
-	ci, err := containerInstance(cluster, id)
+	ci, err := d.ContainerInstance(id)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("ci = %+v\n", ci)
+	fmt.Printf("ci = %+v\n", *ci.ContainerInstanceArn)
 
-	cis, err := ECS.UpdateContainerInstancesState(&ecs.UpdateContainerInstancesStateInput{
-		ContainerInstances: []*string{
-			aws.String(ci),
-		},
-		Status:  aws.String("DRAINING"),
-		Cluster: aws.String(cluster),
-	})
-	if err != nil {
+	if err := d.Start(*ci.ContainerInstanceArn); err != nil {
 		return err
 	}
 
-	if len(cis.Failures) > 0 {
-		return fmt.Errorf("unable to drain instance: %s - %s", ci, *cis.Failures[0].Reason)
+	// leave time to deregister and complete the lifecycle action before the
+	// lambda times out
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-drainMargin))
+		defer cancel()
 	}
 
-	if err := waitForInstanceDrain(cluster, ci); err != nil {
-		return err
+	if err := d.Wait(ctx, *ci.ContainerInstanceArn); err != nil {
+		fmt.Printf("err = %+v\n", err)
+	} else {
+		fmt.Println("instance has been drained")
 	}
 
-	fmt.Println("instance has been drained")
-
 	_, err = ECS.DeregisterContainerInstance(&ecs.DeregisterContainerInstanceInput{
-		Cluster:           aws.String(cluster),
-		ContainerInstance: aws.String(ci),
+		Cluster:           aws.String(d.Cluster),
+		ContainerInstance: ci.ContainerInstanceArn,
 		Force:             aws.Bool(true),
 	})
 	if err != nil {
@@ -206,81 +170,3 @@ func drainInstance(id string) error {
 
 	return nil
 }
-
-func remarshal(v, w interface{}) error {
-	data, err := json.Marshal(v)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(data, &w)
-}
-
-// stopServicelessTasks stops one-off tasks that do not belog to a ECS service.
-// For example, a scheduled task or running a process
-func stopServicelessTasks(input *ecs.DescribeTasksInput) error {
-	tasks, err := ECS.DescribeTasks(input)
-	if err != nil {
-		return err
-	}
-
-	for _, t := range tasks.Tasks {
-		// if the task isn't part of a service and wasn't started by ECS, stop it
-		if !strings.HasPrefix(*t.Group, "service:") && !strings.HasPrefix(*t.StartedBy, "ecs-svc") {
-			_, err := ECS.StopTask(&ecs.StopTaskInput{
-				Cluster: input.Cluster,
-				Reason:  aws.String("draining instance for termination"),
-				Task:    t.TaskArn,
-			})
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
-func waitForInstanceDrain(cluster, ci string) error {
-	params := &ecs.ListTasksInput{
-		Cluster:           aws.String(cluster),
-		ContainerInstance: aws.String(ci),
-		DesiredStatus:     aws.String("RUNNING"),
-	}
-
-	tasks := []*string{}
-
-	for {
-		resp, err := ECS.ListTasks(params)
-		if err != nil {
-			return err
-		}
-
-		tasks = append(tasks, resp.TaskArns...)
-
-		if resp.NextToken == nil {
-			break
-		}
-
-		params.NextToken = resp.NextToken
-		time.Sleep(1 * time.Second)
-	}
-
-	if len(tasks) == 0 {
-		fmt.Println("no tasks to wait for")
-		return nil
-	}
-
-	input := &ecs.DescribeTasksInput{
-		Cluster: aws.String(cluster),
-		Tasks:   tasks,
-	}
-
-	if err := stopServicelessTasks(input); err != nil {
-		return err
-	}
-
-	fmt.Println("stopped service-less tasks")
-
-	return ECS.WaitUntilTasksStopped(input)
-}
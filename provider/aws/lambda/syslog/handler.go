@@ -1,92 +1,310 @@
-package main
-
-import (
-	"context"
-	"fmt"
-	"net/url"
-	"os"
-	"strconv"
-	"strings"
-	"time"
-
-	syslog "github.com/RackSec/srslog"
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/mweagle/Sparta/aws/cloudwatchlogs"
-)
-
-func Handler(ctx context.Context, event cloudwatchlogs.Event) error {
-	d, err := event.AWSLogs.DecodedData()
-	if err != nil {
-		return err
-	}
-
-	u, err := url.Parse(os.Getenv("SYSLOG_URL"))
-	if err != nil {
-		return err
-	}
-
-	w, err := syslog.Dial(u.Scheme, u.Host, syslog.LOG_INFO, "convox/syslog")
-	if err != nil {
-		return err
-	}
-	defer w.Close()
-
-	w.SetFormatter(contentFormatter(d.LogGroup))
-
-	var failures, successes int
-
-	for _, le := range d.LogEvents {
-		if err := w.Info(fmt.Sprintf("%s %d %s", d.LogStream, le.Timestamp, le.Message)); err != nil {
-			failures++
-		} else {
-			successes++
-		}
-	}
-
-	fmt.Printf("group=%s stream=%s type=%s events=%d success=%d failure=%d\n", d.LogGroup, d.LogStream, d.MessageType, len(d.LogEvents), successes, failures)
-
-	return nil
-}
-
-func contentFormatter(group string) syslog.Formatter {
-	return func(p syslog.Priority, hostname, tag, content string) string {
-		timestamp := time.Now()
-		service := "convox/syslog"
-		container := "unknown"
-		message := content
-
-		if parts := strings.SplitN(content, " ", 3); len(parts) == 3 {
-			if pp := strings.SplitN(parts[0], "/", 3); len(pp) == 3 {
-				service = fmt.Sprintf("%s/%s", pp[0], pp[1])
-				cp := strings.Split(pp[2], "-")
-				container = cp[len(cp)-1]
-			}
-
-			if i, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
-				sec := i / 1000
-				nsec := i - (sec * 1000)
-				timestamp = time.Unix(sec, nsec).UTC()
-			}
-
-			message = parts[2]
-		}
-
-		line := os.Getenv("SYSLOG_FORMAT")
-
-		line = strings.ReplaceAll(line, "{DATE}", timestamp.Format(time.RFC3339))
-		line = strings.ReplaceAll(line, "{GROUP}", group)
-		line = strings.ReplaceAll(line, "{SERVICE}", service)
-		line = strings.ReplaceAll(line, "{CONTAINER}", container)
-		line = strings.ReplaceAll(line, "{MESSAGE}", message)
-
-		for newline := true; newline; newline = strings.HasSuffix(line, "\n") {
-			line = strings.TrimSuffix(line, "\n")
-		}
-
-		return line + "\n"
-	}
-}
-
-func main() {
-	lambda.Start(Handler)
-}
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/mweagle/Sparta/aws/cloudwatchlogs"
+)
+
+type config struct {
+	BatchSize      int
+	CA             string
+	Format         string
+	Framing        string
+	Name           string
+	Payload        string
+	Retries        int
+	StructuredData string
+	URL            *url.URL
+}
+
+type payload struct {
+	Timestamp time.Time `json:"timestamp"`
+	Group     string    `json:"group"`
+	Service   string    `json:"service"`
+	Container string    `json:"container"`
+	Message   string    `json:"message"`
+}
+
+// conn is reused across warm invocations
+var conn net.Conn
+
+func Handler(ctx context.Context, event cloudwatchlogs.Event) error {
+	d, err := event.AWSLogs.DecodedData()
+	if err != nil {
+		return err
+	}
+
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	frames := []string{}
+
+	for _, le := range d.LogEvents {
+		frames = append(frames, c.frame(c.render(d.LogGroup, d.LogStream, le.Timestamp, le.Message)))
+	}
+
+	var failures, successes int
+
+	for _, b := range c.batches(frames) {
+		if err := c.send(ctx, b); err != nil {
+			fmt.Printf("error=%q\n", err)
+			failures += len(b)
+		} else {
+			successes += len(b)
+		}
+	}
+
+	fmt.Printf("group=%s stream=%s type=%s events=%d success=%d failure=%d\n", d.LogGroup, d.LogStream, d.MessageType, len(d.LogEvents), successes, failures)
+
+	if err := c.putMetrics(successes, failures); err != nil {
+		fmt.Printf("error=%q\n", err)
+	}
+
+	return nil
+}
+
+func loadConfig() (*config, error) {
+	u, err := url.Parse(os.Getenv("SYSLOG_URL"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{
+		BatchSize:      envInt("SYSLOG_BATCH_SIZE", 65536),
+		CA:             os.Getenv("SYSLOG_CA"),
+		Format:         os.Getenv("SYSLOG_FORMAT"),
+		Framing:        coalesce(os.Getenv("SYSLOG_FRAMING"), "newline"),
+		Name:           os.Getenv("SYSLOG_NAME"),
+		Payload:        coalesce(os.Getenv("SYSLOG_PAYLOAD"), "text"),
+		Retries:        envInt("SYSLOG_RETRIES", 3),
+		StructuredData: coalesce(os.Getenv("SYSLOG_STRUCTURED_DATA"), "-"),
+		URL:            u,
+	}
+
+	switch c.Framing {
+	case "newline", "octet-counting":
+	default:
+		return nil, fmt.Errorf("invalid framing: %s", c.Framing)
+	}
+
+	switch c.Payload {
+	case "text", "json":
+	default:
+		return nil, fmt.Errorf("invalid payload: %s", c.Payload)
+	}
+
+	return c, nil
+}
+
+// batches groups frames into writes of at most BatchSize bytes, udp sends one frame per datagram
+func (c *config) batches(frames []string) [][]string {
+	bs := [][]string{}
+
+	size := 0
+
+	for _, f := range frames {
+		if n := len(bs); n == 0 || c.URL.Scheme == "udp" || size+len(f) > c.BatchSize {
+			bs = append(bs, []string{})
+			size = 0
+		}
+
+		bs[len(bs)-1] = append(bs[len(bs)-1], f)
+		size += len(f)
+	}
+
+	return bs
+}
+
+func (c *config) dial() (net.Conn, error) {
+	if conn != nil {
+		return conn, nil
+	}
+
+	d := &net.Dialer{Timeout: 10 * time.Second}
+
+	var cn net.Conn
+	var err error
+
+	switch c.URL.Scheme {
+	case "tcp", "udp":
+		cn, err = d.Dial(c.URL.Scheme, c.URL.Host)
+	case "tcp+tls":
+		tc, terr := c.tlsConfig()
+		if terr != nil {
+			return nil, terr
+		}
+		cn, err = tls.DialWithDialer(d, "tcp", c.URL.Host, tc)
+	default:
+		return nil, fmt.Errorf("invalid scheme: %s", c.URL.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn = cn
+
+	return cn, nil
+}
+
+func (c *config) frame(line string) string {
+	switch c.Framing {
+	case "octet-counting":
+		return fmt.Sprintf("%d %s", len(line), line)
+	default:
+		return line + "\n"
+	}
+}
+
+func (c *config) putMetrics(successes, failures int) error {
+	if c.Name == "" {
+		return nil
+	}
+
+	s, err := session.NewSession()
+	if err != nil {
+		return err
+	}
+
+	dimensions := []*cloudwatch.Dimension{
+		{Name: aws.String("Resource"), Value: aws.String(c.Name)},
+	}
+
+	_, err = cloudwatch.New(s).PutMetricData(&cloudwatch.PutMetricDataInput{
+		Namespace: aws.String("Convox/Syslog"),
+		MetricData: []*cloudwatch.MetricDatum{
+			{MetricName: aws.String("Failures"), Dimensions: dimensions, Unit: aws.String("Count"), Value: aws.Float64(float64(failures))},
+			{MetricName: aws.String("Successes"), Dimensions: dimensions, Unit: aws.String("Count"), Value: aws.Float64(float64(successes))},
+		},
+	})
+
+	return err
+}
+
+func (c *config) render(group, stream string, ts int64, message string) string {
+	p := payload{
+		Container: "unknown",
+		Group:     group,
+		Message:   message,
+		Service:   "convox/syslog",
+		Timestamp: time.UnixMilli(ts).UTC(),
+	}
+
+	if pp := strings.SplitN(stream, "/", 3); len(pp) == 3 {
+		p.Service = fmt.Sprintf("%s/%s", pp[0], pp[1])
+		cp := strings.Split(pp[2], "-")
+		p.Container = cp[len(cp)-1]
+	}
+
+	if c.Payload == "json" {
+		data, err := json.Marshal(p)
+		if err == nil {
+			message = string(data)
+		}
+	}
+
+	line := c.Format
+
+	line = strings.ReplaceAll(line, "{DATE}", p.Timestamp.Format(time.RFC3339))
+	line = strings.ReplaceAll(line, "{GROUP}", p.Group)
+	line = strings.ReplaceAll(line, "{SERVICE}", p.Service)
+	line = strings.ReplaceAll(line, "{CONTAINER}", p.Container)
+	line = strings.ReplaceAll(line, "{STRUCTURED_DATA}", c.StructuredData)
+	line = strings.ReplaceAll(line, "{MESSAGE}", message)
+
+	return strings.TrimRight(line, "\n")
+}
+
+// send writes a batch of frames, reconnecting with exponential backoff up to Retries times
+func (c *config) send(ctx context.Context, frames []string) error {
+	data := []byte(strings.Join(frames, ""))
+
+	var err error
+
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(100<<uint(attempt-1)) * time.Millisecond):
+			}
+		}
+
+		var cn net.Conn
+
+		cn, err = c.dial()
+		if err != nil {
+			continue
+		}
+
+		cn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+
+		if _, err = cn.Write(data); err == nil {
+			return nil
+		}
+
+		cn.Close()
+		conn = nil
+	}
+
+	return err
+}
+
+func (c *config) tlsConfig() (*tls.Config, error) {
+	tc := &tls.Config{ServerName: c.URL.Hostname()}
+
+	if c.CA == "" {
+		return tc, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM([]byte(c.CA)) {
+		return nil, fmt.Errorf("invalid ca certificate")
+	}
+
+	tc.RootCAs = pool
+
+	return tc, nil
+}
+
+func coalesce(ss ...string) string {
+	for _, s := range ss {
+		if s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+func envInt(name string, def int) int {
+	if i, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return i
+	}
+
+	return def
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	c := &config{
+		Format:         "<22>1 {DATE} {GROUP} {SERVICE} {CONTAINER} - {STRUCTURED_DATA} {MESSAGE}",
+		Payload:        "text",
+		StructuredData: `[token@41058 tag="convox"]`,
+	}
+
+	got := c.render("rack-app-LogGroup", "service/web/0123-abcdef", 1609556645000, "hello\n")
+	want := `<22>1 2021-01-02T03:04:05Z rack-app-LogGroup service/web abcdef - [token@41058 tag="convox"] hello`
+
+	if got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+
+	c.Format = "{MESSAGE}"
+	c.Payload = "json"
+
+	got = c.render("grp", "strm", 1609556645000, "hello")
+	want = `{"timestamp":"2021-01-02T03:04:05Z","group":"grp","service":"convox/syslog","container":"unknown","message":"hello"}`
+
+	if got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	cases := []struct {
+		framing string
+		want    string
+	}{
+		{"newline", "hello\n"},
+		{"octet-counting", "5 hello"},
+	}
+
+	for _, tc := range cases {
+		c := &config{Framing: tc.framing}
+
+		if got := c.frame("hello"); got != tc.want {
+			t.Errorf("frame(%s) = %q, want %q", tc.framing, got, tc.want)
+		}
+	}
+}
+
+func TestBatches(t *testing.T) {
+	frames := []string{"aaaa", "bbbb", "cccc"}
+
+	c := &config{BatchSize: 8, URL: &url.URL{Scheme: "tcp"}}
+
+	if got := c.batches(frames); len(got) != 2 || len(got[0]) != 2 || len(got[1]) != 1 {
+		t.Errorf("tcp batches = %v", got)
+	}
+
+	c.URL.Scheme = "udp"
+
+	if got := c.batches(frames); len(got) != 3 {
+		t.Errorf("udp batches = %v", got)
+	}
+}
+
+func TestSend(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	lines := make(chan string, 2)
+
+	go func() {
+		cn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer cn.Close()
+
+		s := bufio.NewScanner(cn)
+
+		for s.Scan() {
+			lines <- s.Text()
+		}
+	}()
+
+	conn = nil
+
+	c := &config{Retries: 1, URL: &url.URL{Scheme: "tcp", Host: l.Addr().String()}}
+
+	if err := c.send(context.Background(), []string{"one\n", "two\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"one", "two"} {
+		if got := <-lines; got != want {
+			t.Errorf("received %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSendFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := l.Addr().String()
+
+	l.Close()
+
+	conn = nil
+
+	c := &config{Retries: 2, URL: &url.URL{Scheme: "tcp", Host: addr}}
+
+	if err := c.send(context.Background(), []string{"one\n"}); err == nil {
+		t.Errorf("expected error sending to closed listener")
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/webhook"
+)
+
+const (
+	// keep in sync with provider/aws
+	sortableTime = "20060102.150405.000000000"
+
+	// deliveries are kept for as long as sqs keeps a dead letter
+	retention = 14 * 24 * time.Hour
+
+	maxBackoff = 12 * time.Hour
+)
+
+type config struct {
+	Deliveries  string
+	Filter      *webhook.Filter
+	MaxAttempts int
+	Queue       string
+	Secret      string
+	Template    *template.Template
+	URL         string
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// Handler receives events from the webhook queue one at a time, a returned
+// error leaves the message on the queue to be retried after its visibility
+// timeout, sqs moves it to the dead letter queue after MaxAttempts receives
+func Handler(ctx context.Context, e events.SQSEvent) error {
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	s, err := session.NewSession()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range e.Records {
+		if err := c.deliver(ctx, s, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadConfig() (*config, error) {
+	f, err := webhook.ParseFilter(os.Getenv("WEBHOOK_FILTER"))
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := webhook.NewTemplate(os.Getenv("WEBHOOK_TEMPLATE"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{
+		Deliveries:  os.Getenv("WEBHOOK_DELIVERIES"),
+		Filter:      f,
+		MaxAttempts: envInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		Queue:       os.Getenv("WEBHOOK_QUEUE"),
+		Secret:      os.Getenv("WEBHOOK_SECRET"),
+		Template:    t,
+		URL:         os.Getenv("WEBHOOK_URL"),
+	}
+
+	return c, nil
+}
+
+func (c *config) deliver(ctx context.Context, s *session.Session, m events.SQSMessage) error {
+	attempt, _ := strconv.Atoi(m.Attributes["ApproximateReceiveCount"])
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	var e webhook.Event
+
+	if err := json.Unmarshal([]byte(m.Body), &e); err != nil {
+		fmt.Printf("id=%s error=%q\n", m.MessageId, err)
+		return nil
+	}
+
+	// filtered events are dropped without a delivery record
+	if !c.Filter.Match(e) {
+		fmt.Printf("id=%s action=%s status=filtered\n", m.MessageId, e.Action)
+		return nil
+	}
+
+	payload, err := webhook.Render(c.Template, e)
+	if err != nil {
+		fmt.Printf("id=%s error=%q\n", m.MessageId, err)
+		return nil
+	}
+
+	a := c.post(ctx, m.MessageId, attempt, e.Action, payload)
+
+	status := "success"
+
+	switch {
+	case a.Error == "":
+	case attempt >= c.MaxAttempts:
+		status = "dead-letter"
+	default:
+		status = "retrying"
+	}
+
+	redelivery := ""
+
+	if ma, ok := m.MessageAttributes["Redelivery"]; ok && ma.StringValue != nil {
+		redelivery = *ma.StringValue
+	}
+
+	if err := c.record(s, m.MessageId, redelivery, e.Action, m.Body, status, a); err != nil {
+		fmt.Printf("id=%s error=%q\n", m.MessageId, err)
+	}
+
+	fmt.Printf("id=%s attempt=%d status=%s code=%d error=%q\n", m.MessageId, attempt, status, a.Status, a.Error)
+
+	if a.Error == "" {
+		return nil
+	}
+
+	if status == "retrying" {
+		_, err := sqs.New(s).ChangeMessageVisibility(&sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String(c.Queue),
+			ReceiptHandle:     aws.String(m.ReceiptHandle),
+			VisibilityTimeout: aws.Int64(int64(backoff(attempt) / time.Second)),
+		})
+		if err != nil {
+			fmt.Printf("id=%s error=%q\n", m.MessageId, err)
+		}
+	}
+
+	return fmt.Errorf("delivery %s attempt %d failed: %s", m.MessageId, attempt, a.Error)
+}
+
+func (c *config) post(ctx context.Context, id string, attempt int, action string, body []byte) structs.WebhookAttempt {
+	now := time.Now().UTC()
+
+	a := structs.WebhookAttempt{Attempt: attempt, Time: now}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL, bytes.NewReader(body))
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+
+	req.Header.Set("Content-Type", webhook.ContentType(body))
+	req.Header.Set("User-Agent", "convox-webhook")
+	req.Header.Set("Convox-Delivery", id)
+	req.Header.Set("Convox-Delivery-Attempt", strconv.Itoa(attempt))
+	req.Header.Set("Convox-Event", action)
+	req.Header.Set("Convox-Signature", sign(c.Secret, now, body))
+
+	res, err := client.Do(req)
+
+	a.Duration = time.Since(now)
+
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	a.Status = res.StatusCode
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		a.Error = fmt.Sprintf("response status %d", res.StatusCode)
+	}
+
+	return a
+}
+
+func (c *config) record(s *session.Session, id, redelivery, action, body, status string, a structs.WebhookAttempt) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]*dynamodb.AttributeValue{
+		":action":  {S: aws.String(action)},
+		":attempt": {L: []*dynamodb.AttributeValue{{S: aws.String(string(data))}}},
+		":created": {S: aws.String(a.Time.Format(sortableTime))},
+		":empty":   {L: []*dynamodb.AttributeValue{}},
+		":event":   {S: aws.String(body)},
+		":expires": {N: aws.String(strconv.FormatInt(a.Time.Add(retention).Unix(), 10))},
+		":status":  {S: aws.String(status)},
+		":updated": {S: aws.String(time.Now().UTC().Format(sortableTime))},
+	}
+
+	expr := "SET #action = :action, #attempts = list_append(if_not_exists(#attempts, :empty), :attempt), #created = if_not_exists(#created, :created), #event = :event, #expires = :expires, #status = :status, #updated = :updated"
+
+	names := map[string]*string{
+		"#action":   aws.String("action"),
+		"#attempts": aws.String("attempts"),
+		"#created":  aws.String("created"),
+		"#event":    aws.String("event"),
+		"#expires":  aws.String("expires"),
+		"#status":   aws.String("status"),
+		"#updated":  aws.String("updated"),
+	}
+
+	if redelivery != "" {
+		expr += ", #redelivery = :redelivery"
+		names["#redelivery"] = aws.String("redelivery")
+		values[":redelivery"] = &dynamodb.AttributeValue{S: aws.String(redelivery)}
+	}
+
+	_, err = dynamodb.New(s).UpdateItem(&dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		Key:                       map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}},
+		TableName:                 aws.String(c.Deliveries),
+		UpdateExpression:          aws.String(expr),
+	})
+
+	return err
+}
+
+// backoff doubles from 30s for each failed attempt
+func backoff(attempt int) time.Duration {
+	d := 30 * time.Second
+
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+
+	return d
+}
+
+// sign returns the Convox-Signature header value, receivers should compute
+// hex(hmac-sha256(secret, "<t>.<body>")) and compare it to v1
+func sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func envInt(name string, def int) int {
+	if i, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return i
+	}
+
+	return def
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:  30 * time.Second,
+		1:  30 * time.Second,
+		2:  60 * time.Second,
+		3:  120 * time.Second,
+		8:  3840 * time.Second,
+		20: 12 * time.Hour,
+	}
+
+	for attempt, want := range cases {
+		if got := backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestPost(t *testing.T) {
+	body := `{"action":"release:promote","status":"success"}`
+
+	var headers http.Header
+	var received string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		headers = r.Header
+		received = string(data)
+	}))
+	defer ts.Close()
+
+	c := &config{Secret: "secret", URL: ts.URL}
+
+	a := c.post(context.Background(), "message1", 2, "release:promote", []byte(body))
+
+	if a.Error != "" || a.Status != 200 || a.Attempt != 2 {
+		t.Fatalf("attempt = %+v", a)
+	}
+
+	if received != body {
+		t.Errorf("body = %q, want %q", received, body)
+	}
+
+	for k, v := range map[string]string{"Convox-Delivery": "message1", "Convox-Delivery-Attempt": "2", "Convox-Event": "release:promote"} {
+		if got := headers.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	parts := strings.SplitN(headers.Get("Convox-Signature"), ",", 2)
+	if len(parts) != 2 {
+		t.Fatalf("invalid signature: %q", headers.Get("Convox-Signature"))
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(strings.TrimPrefix(parts[0], "t=") + "." + body))
+
+	if want := "v1=" + hex.EncodeToString(mac.Sum(nil)); parts[1] != want {
+		t.Errorf("signature = %q, want %q", parts[1], want)
+	}
+}
+
+func TestPostError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+
+	c := &config{URL: ts.URL}
+
+	a := c.post(context.Background(), "message1", 1, "app:create", []byte("{}"))
+
+	if a.Status != 503 || a.Error != "response status 503" {
+		t.Errorf("attempt = %+v", a)
+	}
+}
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1609556645, 0)
+
+	if sign("secret", ts, []byte("{}")) != sign("secret", ts, []byte("{}")) {
+		t.Errorf("signature is not deterministic")
+	}
+
+	if sign("secret", ts, []byte("{}")) == sign("other", ts, []byte("{}")) {
+		t.Errorf("signature does not depend on secret")
+	}
+
+	if !strings.HasPrefix(sign("secret", ts, []byte("{}")), "t=1609556645,v1=") {
+		t.Errorf("signature = %q", sign("secret", ts, []byte("{}")))
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("WEBHOOK_FILTER", "action=release:*")
+	t.Setenv("WEBHOOK_TEMPLATE", "slack")
+
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.MaxAttempts != 8 {
+		t.Errorf("MaxAttempts = %d, want 8", c.MaxAttempts)
+	}
+
+	t.Setenv("WEBHOOK_FILTER", "service=web")
+
+	if _, err := loadConfig(); err == nil || err.Error() != "invalid filter key: service" {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// leaseRetention keeps released or expired leases around for debugging
+const leaseRetention = 24 * time.Hour
+
+// dynamoLock is a leader.Lock backed by conditional writes to the rack
+// leases table
+type dynamoLock struct {
+	provider *Provider
+}
+
+func (l *dynamoLock) Acquire(name, holder string, lease time.Duration) (bool, error) {
+	now := time.Now()
+
+	_, err := l.provider.dynamodb().PutItem(&dynamodb.PutItemInput{
+		ConditionExpression: aws.String("attribute_not_exists(#name) OR #holder = :holder OR #lease < :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#holder": aws.String("holder"),
+			"#lease":  aws.String("lease"),
+			"#name":   aws.String("name"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":holder": {S: aws.String(holder)},
+			":now":    {N: aws.String(strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10))},
+		},
+		Item: map[string]*dynamodb.AttributeValue{
+			"name":    {S: aws.String(name)},
+			"expires": {N: aws.String(strconv.FormatInt(now.Add(leaseRetention).Unix(), 10))},
+			"holder":  {S: aws.String(holder)},
+			"lease":   {N: aws.String(strconv.FormatInt(now.Add(lease).UnixNano()/int64(time.Millisecond), 10))},
+		},
+		TableName: aws.String(l.provider.DynamoLeases),
+	})
+	if ae, ok := err.(awserr.Error); ok && ae.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (l *dynamoLock) Release(name, holder string) error {
+	_, err := l.provider.dynamodb().DeleteItem(&dynamodb.DeleteItemInput{
+		ConditionExpression: aws.String("#holder = :holder"),
+		ExpressionAttributeNames: map[string]*string{
+			"#holder": aws.String("holder"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":holder": {S: aws.String(holder)},
+		},
+		Key: map[string]*dynamodb.AttributeValue{
+			"name": {S: aws.String(name)},
+		},
+		TableName: aws.String(l.provider.DynamoLeases),
+	})
+	if ae, ok := err.(awserr.Error); ok && ae.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/require"
+)
+
+// testInternalProvider returns a provider for tests of unexported methods
+// that replays cycles against a stub server
+func testInternalProvider(cycles ...awsutil.Cycle) (*Provider, func()) {
+	s := httptest.NewServer(awsutil.NewHandler(cycles))
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "test-access")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret")
+
+	p := &Provider{
+		Region:       "us-test-1",
+		Endpoint:     s.URL,
+		Cluster:      "cluster-test",
+		DynamoLeases: "convox-leases",
+		Rack:         "convox",
+		SkipCache:    true,
+	}
+
+	return p, s.Close
+}
+
+func TestDynamoLockAcquire(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleLeasePutItem(200, `{}`),
+		cycleLeasePutItem(400, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`),
+		cycleLeasePutItem(400, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`),
+	)
+	defer closer()
+
+	l := &dynamoLock{provider: p}
+
+	ok, err := l.Acquire("workers", "h1", 30*time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = l.Acquire("workers", "h1", 30*time.Second)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = l.Acquire("workers", "h1", 30*time.Second)
+	require.EqualError(t, err, "ResourceNotFoundException: Requested resource not found")
+	require.False(t, ok)
+}
+
+func TestDynamoLockRelease(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleLeaseDeleteItem(200, `{}`),
+		cycleLeaseDeleteItem(400, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`),
+	)
+	defer closer()
+
+	l := &dynamoLock{provider: p}
+
+	require.NoError(t, l.Release("workers", "h1"))
+	require.NoError(t, l.Release("workers", "h1"))
+}
+
+func cycleLeasePutItem(code int, body string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.PutItem",
+			Body:       `/"ConditionExpression":"attribute_not_exists\(#name\) OR #holder = :holder OR #lease < :now".*"holder":{"S":"h1"}.*"name":{"S":"workers"}.*"TableName":"convox-leases"/`,
+		},
+		Response: awsutil.Response{
+			StatusCode: code,
+			Body:       body,
+		},
+	}
+}
+
+func cycleLeaseDeleteItem(code int, body string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.DeleteItem",
+			Body: `{
+				"ConditionExpression": "#holder = :holder",
+				"ExpressionAttributeNames": {
+					"#holder": "holder"
+				},
+				"ExpressionAttributeValues": {
+					":holder": {
+						"S": "h1"
+					}
+				},
+				"Key": {
+					"name": {
+						"S": "workers"
+					}
+				},
+				"TableName": "convox-leases"
+			}`,
+		},
+		Response: awsutil.Response{
+			StatusCode: code,
+			Body:       body,
+		},
+	}
+}
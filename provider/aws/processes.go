@@ -131,7 +131,7 @@ func (p *Provider) ProcessGet(app, pid string) (*structs.Process, error) {
 
 	for _, t := range tasks {
 		if strings.HasSuffix(t, pid) {
-			pss, err := p.taskProcesses([]string{t})
+			pss, err := p.taskProcesses(app, []string{t})
 			if err != nil {
 				return nil, log.Error(err)
 			}
@@ -154,7 +154,7 @@ func (p *Provider) ProcessList(app string, opts structs.ProcessListOptions) (str
 		return nil, log.Error(err)
 	}
 
-	ps, err := p.taskProcesses(tasks)
+	ps, err := p.taskProcesses(app, tasks)
 	if err != nil {
 		return nil, log.Error(err)
 	}
@@ -227,7 +227,9 @@ func (p *Provider) ProcessList(app string, opts structs.ProcessListOptions) (str
 }
 
 func (p *Provider) ProcessLogs(app, pid string, opts structs.LogsOptions) (io.ReadCloser, error) {
-	return nil, fmt.Errorf("unimplemented")
+	opts.Process = aws.String(pid)
+
+	return p.AppLogs(app, opts)
 }
 
 func (p *Provider) stackTasks(stack string) ([]string, error) {
@@ -285,7 +287,27 @@ func (p *Provider) stackTasks(stack string) ([]string, error) {
 
 // appTaskARNs retuns a list of ECS Task (aka process) ARNs that correspond to an app
 // This includes one-off processes, build tasks, etc.
+// The list is cached until a task of the app changes state
 func (p *Provider) appTaskARNs(app string) ([]string, error) {
+	if p.SkipCache {
+		return p.listAppTaskARNs(app)
+	}
+
+	key, ttl := p.generationKey(tasksScope(app), app, 5*time.Second)
+
+	v, err := cache.Load("appTaskARNs", key, ttl, func() (interface{}, error) {
+		return p.listAppTaskARNs(app)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, _ := v.([]string)
+
+	return tasks, nil
+}
+
+func (p *Provider) listAppTaskARNs(app string) ([]string, error) {
 	tasks, err := p.stackTasks(fmt.Sprintf("%s-%s", p.Rack, app))
 	if ae, ok := err.(awserr.Error); ok && ae.Code() == "ValidationError" {
 		return nil, errorNotFound(fmt.Sprintf("app not found: %s", app))
@@ -327,7 +349,9 @@ func (p *Provider) appTaskARNs(app string) ([]string, error) {
 
 const describeTasksPageSize = 100
 
-func (p *Provider) taskProcesses(tasks []string) (structs.Processes, error) {
+// taskProcesses returns the processes of tasks, which belong to app unless it
+// is empty
+func (p *Provider) taskProcesses(app string, tasks []string) (structs.Processes, error) {
 	log := Logger.At("taskProcesses").Namespace("tasks=%q", tasks).Start()
 
 	pss := structs.Processes{}
@@ -357,7 +381,7 @@ func (p *Provider) taskProcesses(tasks []string) (structs.Processes, error) {
 
 		ecsTasks := make([]*ecs.Task, 0, len(iptasks))
 
-		primaryTasks, err := p.fetchTasks(p.Cluster, iptasks)
+		primaryTasks, err := p.fetchTasks(app, p.Cluster, iptasks)
 
 		if err != nil {
 			log.Error(err)
@@ -369,7 +393,7 @@ func (p *Provider) taskProcesses(tasks []string) (structs.Processes, error) {
 		}
 
 		if p.Cluster != p.BuildCluster {
-			buildTasks, err := p.fetchTasks(p.BuildCluster, iptasks)
+			buildTasks, err := p.fetchTasks(app, p.BuildCluster, iptasks)
 
 			if err != nil {
 				log.Error(err)
@@ -421,7 +445,7 @@ func (p *Provider) taskProcesses(tasks []string) (structs.Processes, error) {
 	return pss, nil
 }
 
-func (p *Provider) fetchTasks(cluster string, tasks []*string) ([]*ecs.Task, error) {
+func (p *Provider) fetchTasks(app, cluster string, tasks []*string) ([]*ecs.Task, error) {
 	filteredTasks := []*string{}
 
 	for _, task := range tasks {
@@ -433,7 +457,7 @@ func (p *Provider) fetchTasks(cluster string, tasks []*string) ([]*ecs.Task, err
 	store := make([]*ecs.Task, 0, len(filteredTasks))
 
 	if len(filteredTasks) > 0 {
-		tres, err := p.describeTasks(&ecs.DescribeTasksInput{
+		tres, err := p.describeAppTasks(app, &ecs.DescribeTasksInput{
 			Cluster: aws.String(cluster),
 			Tasks:   filteredTasks,
 		})
@@ -1173,11 +1197,24 @@ func (p *Provider) generateTaskDefinition2(app, service string, opts structs.Pro
 		})
 	}
 
+	images, err := p.verifyReleaseImages(app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	image := fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s:%s.%s", aid, p.Region, reg, service, r.Build)
+
+	// a verified image is run by its digest rather than the tag it was
+	// verified through
+	if digest, ok := images[service]; ok {
+		image = fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s@%s", aid, p.Region, reg, digest)
+	}
+
 	cd := &ecs.ContainerDefinition{
 		DockerLabels:      labels,
 		Environment:       cenv,
 		Essential:         aws.Bool(true),
-		Image:             aws.String(fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s:%s.%s", aid, p.Region, reg, service, r.Build)),
+		Image:             aws.String(image),
 		MemoryReservation: aws.Int64(512),
 		MountPoints:       mps,
 		Name:              aws.String(service),
@@ -1304,6 +1341,11 @@ func (p *Provider) resolveRelease(app, release string) (string, error) {
 }
 
 func (p *Provider) runTask(req *ecs.RunTaskInput) (*ecs.Task, error) {
+	// the new task should be listed without waiting for its state change event
+	if !p.SkipCache && req.StartedBy != nil && strings.HasPrefix(*req.StartedBy, "convox.") {
+		defer p.bumpGeneration(tasksScope(strings.TrimPrefix(*req.StartedBy, "convox.")))
+	}
+
 	res, err := p.ecs().RunTask(req)
 	switch {
 	case err != nil:
@@ -48,6 +48,11 @@ func (p *Provider) ReleaseCreate(app string, opts structs.ReleaseCreateOptions)
 			return nil, err
 		}
 
+		// a build cancelled as it finished is never released
+		if opts.Build != nil && b.Status == "cancelled" {
+			return nil, fmt.Errorf("build %s is cancelled", b.Id)
+		}
+
 		r.Description = b.Description
 		r.Manifest = b.Manifest
 	}
@@ -66,6 +71,12 @@ func (p *Provider) ReleaseCreate(app string, opts structs.ReleaseCreateOptions)
 		r.Description = *opts.Description
 	}
 
+	if opts.Build != nil {
+		if _, err := p.verifyReleaseImages(app, r); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := p.releaseSave(r); err != nil {
 		return nil, err
 	}
@@ -180,6 +191,11 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		return err
 	}
 
+	images, err := p.verifyReleaseImages(app, r)
+	if err != nil {
+		return err
+	}
+
 	switch a.Tags["Generation"] {
 	case "", "1":
 		return p.releasePromoteGeneration1(a, r)
@@ -379,6 +395,7 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 			"Autoscale":                          autoscale,
 			"Build":                              tp["Build"],
 			"DeploymentMin":                      min,
+			"Digest":                             images[s.Name],
 			"DeploymentMax":                      max,
 			"Manifest":                           tp["Manifest"],
 			"Password":                           p.Password,
@@ -407,6 +424,7 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		ttp := map[string]interface{}{
 			"App":       r.App,
 			"Build":     tp["Build"],
+			"Images":    images,
 			"Manifest":  tp["Manifest"],
 			"Password":  p.Password,
 			"Release":   tp["Release"],
@@ -452,7 +452,7 @@ func (p *Provider) SystemResourceTypes() (structs.ResourceTypes, error) {
 		for k, p := range r.Parameters {
 			def := p.Default
 
-			if k == "Password" {
+			if k == "Password" || (name == "webhook" && k == "Secret") {
 				def = "(generated)"
 			}
 
@@ -555,11 +555,27 @@ func (p *Provider) createResource(s *structs.Resource) (*cloudformation.CreateSt
 		s.Parameters["Password"] = pw
 	}
 
+	// webhook deliveries are signed with a per-webhook secret
+	if s.Type == "webhook" && s.Parameters["Secret"] == "" {
+		secret, err := generatePassword()
+		if err != nil {
+			return nil, err
+		}
+
+		s.Parameters["Secret"] = secret
+	}
+
 	// reapply manually-specified parameters
 	for k, v := range params {
 		s.Parameters[k] = v
 	}
 
+	if s.Type == "webhook" {
+		if err := validateWebhookParameters(s.Parameters); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := filterFormationParameters(s, formation); err != nil {
 		return nil, err
 	}
@@ -714,7 +730,21 @@ func (p *Provider) updateResource(s *structs.Resource, params map[string]string)
 
 	// inject webhook url for backwards-compatibility
 	if s.Type == "webhook" {
+		if err := validateWebhookParameters(params); err != nil {
+			return err
+		}
+
 		params["Url"] = s.Url
+
+		// webhooks created before signing get a secret on their next update
+		if s.Parameters["Secret"] == "" && params["Secret"] == "" {
+			secret, err := generatePassword()
+			if err != nil {
+				return err
+			}
+
+			params["Secret"] = secret
+		}
 	}
 
 	tags := map[string]string{
@@ -265,6 +265,8 @@ func (p *Provider) ServiceUpdate(app, name string, opts structs.ServiceUpdateOpt
 		return err
 	}
 
+	p.EventSend("service:update", structs.EventSendOptions{Data: map[string]string{"app": a.Name, "name": name, "count": parts[0], "cpu": parts[1], "memory": parts[2]}})
+
 	return nil
 }
 
@@ -0,0 +1,314 @@
+package aws
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/convox/rack/pkg/manifest"
+	"github.com/convox/rack/pkg/signing"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/google/go-containerregistry/pkg/authn"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// registryManifestTypes are the manifests the rack reads from an app registry
+var registryManifestTypes = []*string{
+	aws.String(string(types.DockerManifestSchema2)),
+	aws.String(string(types.DockerManifestList)),
+	aws.String(string(types.OCIManifestSchema1)),
+	aws.String(string(types.OCIImageIndex)),
+}
+
+// imageSigningKeys returns the keys the rack trusts image signatures from,
+// its own signing key and any in ImageSigningTrustedKeys
+func (p *Provider) imageSigningKeys() ([]*ecdsa.PublicKey, error) {
+	keys, err := signing.ParsePublicKeys(p.ImageSigningTrustedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.ImageSigningKey != "" {
+		res, err := p.kms().GetPublicKey(&kms.GetPublicKeyInput{KeyId: aws.String(p.ImageSigningKey)})
+		if err != nil {
+			return nil, err
+		}
+
+		k, err := signing.ParsePublicKeyDER(res.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// registryImage returns the image with id in a registry repository, or nil if
+// it does not exist
+func (p *Provider) registryImage(repo string, id *ecr.ImageIdentifier) (*ecr.Image, error) {
+	res, err := p.ecr().BatchGetImage(&ecr.BatchGetImageInput{
+		AcceptedMediaTypes: registryManifestTypes,
+		ImageIds:           []*ecr.ImageIdentifier{id},
+		RepositoryName:     aws.String(repo),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range res.Failures {
+		switch aws.StringValue(f.FailureCode) {
+		case ecr.ImageFailureCodeImageNotFound, ecr.ImageFailureCodeImageTagDoesNotMatchDigest:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get image: %s", aws.StringValue(f.FailureReason))
+		}
+	}
+
+	if len(res.Images) < 1 {
+		return nil, nil
+	}
+
+	return res.Images[0], nil
+}
+
+// registryBlob downloads the blob with digest from a registry repository
+func (p *Provider) registryBlob(repo, digest string) ([]byte, error) {
+	res, err := p.ecr().GetDownloadUrlForLayer(&ecr.GetDownloadUrlForLayerInput{
+		LayerDigest:    aws.String(digest),
+		RepositoryName: aws.String(repo),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hres, err := http.Get(aws.StringValue(res.DownloadUrl))
+	if err != nil {
+		return nil, err
+	}
+	defer hres.Body.Close()
+
+	if hres.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", digest, hres.Status)
+	}
+
+	return io.ReadAll(hres.Body)
+}
+
+// imageSignatures returns the signatures pushed for the image with digest in a
+// registry repository
+func (p *Provider) imageSignatures(repo, digest string) ([]signing.Signature, error) {
+	img, err := p.registryImage(repo, &ecr.ImageIdentifier{ImageTag: aws.String(signing.Tag(digest))})
+	if err != nil {
+		return nil, err
+	}
+	if img == nil {
+		return nil, nil
+	}
+
+	return signing.Signatures([]byte(aws.StringValue(img.ImageManifest)), func(d string) ([]byte, error) {
+		return p.registryBlob(repo, d)
+	})
+}
+
+// verifyBuildImages returns the digest of the image of each service of a
+// build keyed by service, or an error unless each is signed by one of keys
+func (p *Provider) verifyBuildImages(repo, build string, services []string, keys []*ecdsa.PublicKey) (map[string]string, error) {
+	digests := map[string]string{}
+
+	for _, s := range services {
+		tag := fmt.Sprintf("%s.%s", s, build)
+
+		img, err := p.registryImage(repo, &ecr.ImageIdentifier{ImageTag: aws.String(tag)})
+		if err != nil {
+			return nil, err
+		}
+		if img == nil {
+			return nil, fmt.Errorf("service %s: image not found: %s:%s", s, repo, tag)
+		}
+
+		digest := aws.StringValue(img.ImageId.ImageDigest)
+
+		sigs, err := p.imageSignatures(repo, digest)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := signing.Verify(sigs, digest, keys); err != nil {
+			return nil, fmt.Errorf("service %s: %w", s, err)
+		}
+
+		digests[s] = digest
+	}
+
+	return digests, nil
+}
+
+// verifyReleaseImages returns an error if the rack requires signed images and
+// an image of the release build is not signed by a trusted key. Otherwise it
+// returns the digest of each verified image keyed by service, task
+// definitions reference the images by these digests so that an image pushed
+// over the tag later is never run. Only generation 2 builds sign their images
+// so generation 1 apps are not checked and no digests are returned for them.
+func (p *Provider) verifyReleaseImages(app string, r *structs.Release) (map[string]string, error) {
+	if !p.ImageSignatureRequired || r.Build == "" {
+		return nil, nil
+	}
+
+	a, err := p.AppGet(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Tags["Generation"] != "2" {
+		return nil, nil
+	}
+
+	services, err := releaseServices(r)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := p.appRepository(a.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := p.imageSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.verifyBuildImages(repo.Name, r.Build, services, keys)
+}
+
+// releaseServices returns the name of each service in the generation 2
+// manifest of a release, in order
+func releaseServices(r *structs.Release) ([]string, error) {
+	services := []string{}
+
+	env := structs.Environment{}
+
+	if err := env.Load([]byte(r.Env)); err != nil {
+		return nil, err
+	}
+
+	m, err := manifest.Load([]byte(r.Manifest), env)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range m.Services {
+		services = append(services, s.Name)
+	}
+
+	sort.Strings(services)
+
+	return services, nil
+}
+
+// exportedSignatures carries the signatures of an exported image with the
+// manifests that tie them to the image docker saved
+type exportedSignatures struct {
+	Digest     string              `json:"digest"`
+	Manifests  map[string][]byte   `json:"manifests"`
+	Signatures []signing.Signature `json:"signatures"`
+}
+
+// buildSignatures returns the signatures of the image of each service of a
+// build that has any, keyed by service
+func (p *Provider) buildSignatures(repo, build string, services []string) (map[string]exportedSignatures, error) {
+	ess := map[string]exportedSignatures{}
+
+	for _, s := range services {
+		img, err := p.registryImage(repo, &ecr.ImageIdentifier{ImageTag: aws.String(fmt.Sprintf("%s.%s", s, build))})
+		if err != nil {
+			return nil, err
+		}
+		if img == nil {
+			continue
+		}
+
+		digest := aws.StringValue(img.ImageId.ImageDigest)
+
+		sigs, err := p.imageSignatures(repo, digest)
+		if err != nil {
+			return nil, err
+		}
+		if len(sigs) == 0 {
+			continue
+		}
+
+		manifests := map[string][]byte{digest: []byte(aws.StringValue(img.ImageManifest))}
+
+		// docker saves the image for one platform of an index, its manifest
+		// is carried as well
+		if types.MediaType(aws.StringValue(img.ImageManifestMediaType)).IsIndex() {
+			idx, err := v1.ParseIndexManifest(strings.NewReader(aws.StringValue(img.ImageManifest)))
+			if err != nil {
+				return nil, err
+			}
+
+			for _, m := range idx.Manifests {
+				pimg, err := p.registryImage(repo, &ecr.ImageIdentifier{ImageDigest: aws.String(m.Digest.String())})
+				if err != nil {
+					return nil, err
+				}
+				if pimg != nil {
+					manifests[m.Digest.String()] = []byte(aws.StringValue(pimg.ImageManifest))
+				}
+			}
+		}
+
+		ess[s] = exportedSignatures{Digest: digest, Manifests: manifests, Signatures: sigs}
+	}
+
+	return ess, nil
+}
+
+// verifyImportedImage returns an error unless the signatures carried for the
+// image of a service are from one of keys and name the image docker loaded
+// with config
+func verifyImportedImage(service, config string, es exportedSignatures, keys []*ecdsa.PublicKey) error {
+	if err := signing.Verify(es.Signatures, es.Digest, keys); err != nil {
+		return fmt.Errorf("service %s: %w", service, err)
+	}
+
+	if err := signing.VerifyConfig(es.Manifests, es.Digest, config); err != nil {
+		return fmt.Errorf("service %s: %w", service, err)
+	}
+
+	return nil
+}
+
+// signImage signs the image pushed to tag in an app registry with the rack
+// signing key
+func (p *Provider) signImage(repo *appRepository, tag string) error {
+	img, err := p.registryImage(repo.Name, &ecr.ImageIdentifier{ImageTag: aws.String(tag)})
+	if err != nil {
+		return err
+	}
+	if img == nil {
+		return fmt.Errorf("image not found: %s:%s", repo.URI, tag)
+	}
+
+	digest := aws.StringValue(img.ImageId.ImageDigest)
+
+	sig, err := signing.Sign(signing.NewKMSSigner(p.kms(), p.ImageSigningKey), repo.URI, digest)
+	if err != nil {
+		return err
+	}
+
+	// the rack has logged in to its registry with docker
+	return signing.Push(context.Background(), repo.URI, digest, []signing.Signature{*sig}, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+}
@@ -0,0 +1,237 @@
+package aws
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/convox/rack/pkg/signing"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/require"
+)
+
+const signingTestDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+const signingTestManifestTypes = `"acceptedMediaTypes":["application/vnd.docker.distribution.manifest.v2+json","application/vnd.docker.distribution.manifest.list.v2+json","application/vnd.oci.image.manifest.v1+json","application/vnd.oci.image.index.v1+json"]`
+
+func TestVerifyBuildImages(t *testing.T) {
+	key := signingTestKey(t)
+
+	cycles, closer := cycleSignedImage(t, key)
+	defer closer()
+
+	p, pcloser := testInternalProvider(cycles...)
+	defer pcloser()
+
+	p.ImageSigningTrustedKeys = signingTestPublicKey(t, key)
+
+	keys, err := p.imageSigningKeys()
+	require.NoError(t, err)
+
+	digests, err := p.verifyBuildImages("convox-app1-registry", "B1", []string{"web"}, keys)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"web": signingTestDigest}, digests)
+}
+
+func TestVerifyBuildImagesUntrusted(t *testing.T) {
+	cycles, closer := cycleSignedImage(t, signingTestKey(t))
+	defer closer()
+
+	p, pcloser := testInternalProvider(cycles...)
+	defer pcloser()
+
+	other := signingTestKey(t)
+
+	_, err := p.verifyBuildImages("convox-app1-registry", "B1", []string{"web"}, []*ecdsa.PublicKey{&other.PublicKey})
+	require.EqualError(t, err, "service web: image is not signed by a trusted key")
+}
+
+func TestVerifyBuildImagesUnsigned(t *testing.T) {
+	key := signingTestKey(t)
+
+	p, closer := testInternalProvider(
+		cycleSigningBatchGetImage(`{"imageTag":"web.B1"}`, fmt.Sprintf(`{"images":[{"imageId":{"imageDigest":%q,"imageTag":"web.B1"},"imageManifest":"{}"}]}`, signingTestDigest)),
+		cycleSigningBatchGetImage(fmt.Sprintf(`{"imageTag":%q}`, signing.Tag(signingTestDigest)), `{"failures":[{"failureCode":"ImageNotFound","failureReason":"Requested image not found"}],"images":[]}`),
+	)
+	defer closer()
+
+	_, err := p.verifyBuildImages("convox-app1-registry", "B1", []string{"web"}, []*ecdsa.PublicKey{&key.PublicKey})
+	require.EqualError(t, err, "service web: image is not signed")
+}
+
+func TestVerifyBuildImagesMissing(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleSigningBatchGetImage(`{"imageTag":"web.B1"}`, `{"failures":[{"failureCode":"ImageNotFound","failureReason":"Requested image not found"}],"images":[]}`),
+	)
+	defer closer()
+
+	_, err := p.verifyBuildImages("convox-app1-registry", "B1", []string{"web"}, nil)
+	require.EqualError(t, err, "service web: image not found: convox-app1-registry:web.B1")
+}
+
+func TestVerifyReleaseImagesNotRequired(t *testing.T) {
+	p, closer := testInternalProvider()
+	defer closer()
+
+	digests, err := p.verifyReleaseImages("app1", &structs.Release{Build: "B1"})
+	require.NoError(t, err)
+	require.Nil(t, digests)
+}
+
+func TestVerifyReleaseImagesGeneration1(t *testing.T) {
+	p, closer := testInternalProvider(
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				Method:     "POST",
+				RequestURI: "/",
+				Body:       `Action=DescribeStacks&StackName=convox-app1&Version=2010-05-15`,
+			},
+			Response: awsutil.Response{
+				StatusCode: 200,
+				Body: `<DescribeStacksResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/"><DescribeStacksResult><Stacks><member>
+					<Tags>
+						<member><Key>Name</Key><Value>app1</Value></member>
+						<member><Key>Type</Key><Value>app</Value></member>
+						<member><Key>System</Key><Value>convox</Value></member>
+						<member><Key>Rack</Key><Value>convox</Value></member>
+					</Tags>
+					<StackName>convox-app1</StackName>
+					<StackStatus>UPDATE_COMPLETE</StackStatus>
+				</member></Stacks></DescribeStacksResult></DescribeStacksResponse>`,
+			},
+		},
+	)
+	defer closer()
+
+	p.ImageSignatureRequired = true
+
+	// generation 1 builds are never signed so their releases are not checked
+	digests, err := p.verifyReleaseImages("app1", &structs.Release{Build: "B1", Manifest: "web:\n  image: httpd\n"})
+	require.NoError(t, err)
+	require.Nil(t, digests)
+}
+
+func TestImageSigningKeysRackKey(t *testing.T) {
+	key := signingTestKey(t)
+	trusted := signingTestKey(t)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	p, closer := testInternalProvider(
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				RequestURI: "/",
+				Operation:  "TrentService.GetPublicKey",
+				Body:       `{"KeyId":"key-1"}`,
+			},
+			Response: awsutil.Response{
+				StatusCode: 200,
+				Body:       fmt.Sprintf(`{"KeyId":"key-1","PublicKey":%q}`, base64.StdEncoding.EncodeToString(der)),
+			},
+		},
+	)
+	defer closer()
+
+	p.ImageSigningKey = "key-1"
+	p.ImageSigningTrustedKeys = signingTestPublicKey(t, trusted)
+
+	keys, err := p.imageSigningKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, trusted.PublicKey.Equal(keys[0]))
+	require.True(t, key.PublicKey.Equal(keys[1]))
+}
+
+func TestVerifyImportedImage(t *testing.T) {
+	key := signingTestKey(t)
+
+	image := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":1,"digest":"sha256:4f3c0a37c1e0f8e7c3b9f2a4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708"},"layers":[]}`)
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(image))
+
+	sig, err := signing.Sign(signingTestSigner(key), "registry.example.org/app", digest)
+	require.NoError(t, err)
+
+	es := exportedSignatures{
+		Digest:     digest,
+		Manifests:  map[string][]byte{digest: image},
+		Signatures: []signing.Signature{*sig},
+	}
+
+	require.NoError(t, verifyImportedImage("web", "4f3c0a37c1e0f8e7c3b9f2a4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708.json", es, []*ecdsa.PublicKey{&key.PublicKey}))
+
+	require.EqualError(t, verifyImportedImage("web", "ffff.json", es, []*ecdsa.PublicKey{&key.PublicKey}), fmt.Sprintf("service web: manifest %s does not describe image: ffff", digest))
+
+	other := signingTestKey(t)
+
+	require.EqualError(t, verifyImportedImage("web", "blobs/sha256/4f3c0a37c1e0f8e7c3b9f2a4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708", es, []*ecdsa.PublicKey{&other.PublicKey}), "service web: image is not signed by a trusted key")
+}
+
+// cycleSignedImage returns the cycles that fetch the image of service web in
+// build B1 with a signature by key, the payload is served by a test server
+func cycleSignedImage(t *testing.T, key *ecdsa.PrivateKey) ([]awsutil.Cycle, func()) {
+	sig, err := signing.Sign(signingTestSigner(key), "registry.example.org/app", signingTestDigest)
+	require.NoError(t, err)
+
+	img, err := signing.Image([]signing.Signature{*sig})
+	require.NoError(t, err)
+
+	manifest, err := img.RawManifest()
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(sig.Payload)
+	}))
+
+	images, err := json.Marshal(map[string]interface{}{
+		"images": []map[string]interface{}{
+			{"imageId": map[string]string{"imageDigest": "sha256:sig"}, "imageManifest": string(manifest)},
+		},
+	})
+	require.NoError(t, err)
+
+	layer := fmt.Sprintf("sha256:%x", sha256.Sum256(sig.Payload))
+
+	return []awsutil.Cycle{
+		cycleSigningBatchGetImage(`{"imageTag":"web.B1"}`, fmt.Sprintf(`{"images":[{"imageId":{"imageDigest":%q,"imageTag":"web.B1"},"imageManifest":"{}"}]}`, signingTestDigest)),
+		cycleSigningBatchGetImage(fmt.Sprintf(`{"imageTag":%q}`, signing.Tag(signingTestDigest)), string(images)),
+		cycleECR("GetDownloadUrlForLayer",
+			fmt.Sprintf(`{"layerDigest":%q,"repositoryName":"convox-app1-registry"}`, layer),
+			fmt.Sprintf(`{"downloadUrl":%q,"layerDigest":%q}`, s.URL+"/payload", layer),
+		),
+	}, s.Close
+}
+
+func cycleSigningBatchGetImage(id, res string) awsutil.Cycle {
+	return cycleECR("BatchGetImage",
+		fmt.Sprintf(`{%s,"imageIds":[%s],"repositoryName":"convox-app1-registry"}`, signingTestManifestTypes, id),
+		res,
+	)
+}
+
+func signingTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func signingTestPublicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func signingTestSigner(key *ecdsa.PrivateKey) signing.Signer {
+	return signing.SignerFunc(func(d []byte) ([]byte, error) {
+		return ecdsa.SignASN1(rand.Reader, key, d)
+	})
+}
@@ -437,7 +437,7 @@ func (p *Provider) SystemProcesses(opts structs.SystemProcessesOptions) (structs
 		}
 	}
 
-	ps, err := p.taskProcesses(tasks)
+	ps, err := p.taskProcesses("", tasks)
 	if err != nil {
 		return nil, err
 	}
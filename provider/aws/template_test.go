@@ -5,6 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/convox/rack/pkg/manifest"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/stretchr/testify/require"
 )
 
 // TestServiceTemplateParses verifies service.json.tmpl parses cleanly with the
@@ -40,3 +44,50 @@ func TestAppTemplateParses(t *testing.T) {
 		t.Fatalf("app.json.tmpl failed to parse: %v", err)
 	}
 }
+
+// a release pins its task definitions to the digests its images were verified
+// by, an image pushed over the tag later is never deployed
+func TestReleaseTemplatesImageDigest(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir("../.."))
+	defer os.Chdir(wd)
+
+	m, err := manifest.Load([]byte("services:\n  web:\n    build: .\ntimers:\n  cleanup:\n    command: bin/cleanup\n    schedule: \"0 * * * ?\"\n    service: web\n"), map[string]string{})
+	require.NoError(t, err)
+
+	s, err := m.Service("web")
+	require.NoError(t, err)
+
+	b := &structs.Build{Id: "B1"}
+	r := &structs.Release{Build: "B1", Id: "R1"}
+
+	render := func(name string, params map[string]interface{}) string {
+		for k, v := range map[string]interface{}{"App": "app1", "Build": b, "Manifest": m, "Release": r} {
+			params[k] = v
+		}
+
+		data, err := formationTemplate(name, params)
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	pinned := "${Registry}@" + signingTestDigest
+	tagged := "${Registry}:web.B1"
+
+	data := render("service", map[string]interface{}{"Digest": signingTestDigest, "Service": s})
+	require.Contains(t, data, pinned)
+	require.NotContains(t, data, tagged)
+
+	data = render("timer", map[string]interface{}{"Images": map[string]string{"web": signingTestDigest}, "Timer": m.Timers[0]})
+	require.Contains(t, data, pinned)
+	require.NotContains(t, data, tagged)
+
+	// without required signatures images are still referenced by tag
+	data = render("service", map[string]interface{}{"Service": s})
+	require.Contains(t, data, tagged)
+
+	data = render("timer", map[string]interface{}{"Images": map[string]string(nil), "Timer": m.Timers[0]})
+	require.Contains(t, data, tagged)
+}
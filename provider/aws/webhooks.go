@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/webhook"
+)
+
+func (p *Provider) WebhookDeliveryList(name string, opts structs.WebhookDeliveryListOptions) (structs.WebhookDeliveries, error) {
+	outputs, err := p.webhookOutputs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := structs.WebhookDeliveries{}
+
+	req := &dynamodb.ScanInput{
+		TableName: aws.String(outputs["Deliveries"]),
+	}
+
+	err = p.dynamodb().ScanPages(req, func(res *dynamodb.ScanOutput, last bool) bool {
+		for _, item := range res.Items {
+			d := webhookDeliveryFromItem(item)
+
+			if opts.Status != nil && d.Status != *opts.Status {
+				continue
+			}
+
+			ds = append(ds, d)
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ds, ds.Less)
+
+	limit := 20
+
+	if opts.Limit != nil {
+		limit = *opts.Limit
+	}
+
+	if len(ds) > limit {
+		ds = ds[0:limit]
+	}
+
+	return ds, nil
+}
+
+// WebhookPreview renders a sample event with the filter and template of a
+// webhook, options override the event fields and the webhook parameters
+func (p *Provider) WebhookPreview(name string, opts structs.WebhookPreviewOptions) (*structs.WebhookPreview, error) {
+	s, err := p.webhookStack(name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := stackParameters(s)
+
+	f, err := webhook.ParseFilter(cs(opts.Filter, params["Filter"]))
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := webhook.NewTemplate(cs(opts.Template, params["Template"]))
+	if err != nil {
+		return nil, err
+	}
+
+	e := webhook.Event{
+		Action: cs(opts.Action, "release:promote"),
+		Data: map[string]string{
+			"app":  cs(opts.App, "example"),
+			"id":   "RABCDEFGHIJ",
+			"rack": p.Rack,
+		},
+		Status:    cs(opts.Status, "success"),
+		Timestamp: time.Now().UTC(),
+	}
+
+	if p.IsTest() {
+		e.Timestamp = time.Time{}
+	}
+
+	payload, err := webhook.Render(t, e)
+	if err != nil {
+		return nil, err
+	}
+
+	wp := &structs.WebhookPreview{
+		ContentType: webhook.ContentType(payload),
+		Matched:     f.Match(e),
+		Payload:     string(payload),
+	}
+
+	return wp, nil
+}
+
+// WebhookRedeliver queues the original event of a delivery again, it is
+// signed and retried like a new delivery
+func (p *Provider) WebhookRedeliver(name, id string) error {
+	outputs, err := p.webhookOutputs(name)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.dynamodb().GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		TableName: aws.String(outputs["Deliveries"]),
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.Item == nil {
+		return errorNotFound(fmt.Sprintf("delivery not found: %s", id))
+	}
+
+	_, err = p.sqs().SendMessage(&sqs.SendMessageInput{
+		MessageAttributes: map[string]*sqs.MessageAttributeValue{
+			"Redelivery": {DataType: aws.String("String"), StringValue: aws.String(id)},
+		},
+		MessageBody: aws.String(coalesce(res.Item["event"], "")),
+		QueueUrl:    aws.String(outputs["Queue"]),
+	})
+
+	return err
+}
+
+func (p *Provider) webhookOutputs(name string) (map[string]string, error) {
+	s, err := p.webhookStack(name)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := stackOutputs(s)
+
+	if outputs["Deliveries"] == "" || outputs["Queue"] == "" {
+		return nil, fmt.Errorf("webhook has no delivery log, update the rack: %s", name)
+	}
+
+	return outputs, nil
+}
+
+func (p *Provider) webhookStack(name string) (*cloudformation.Stack, error) {
+	s, err := p.describeStack(p.rackStack(name))
+	if awsError(err) == "ValidationError" {
+		return nil, errorNotFound(fmt.Sprintf("resource not found: %s", name))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stackTags(s)["Resource"] != "webhook" {
+		return nil, fmt.Errorf("resource is not a webhook: %s", name)
+	}
+
+	return s, nil
+}
+
+func webhookDeliveryFromItem(item map[string]*dynamodb.AttributeValue) structs.WebhookDelivery {
+	created, _ := time.Parse(sortableTime, coalesce(item["created"], ""))
+	updated, _ := time.Parse(sortableTime, coalesce(item["updated"], ""))
+
+	d := structs.WebhookDelivery{
+		Id:         coalesce(item["id"], ""),
+		Action:     coalesce(item["action"], ""),
+		Attempts:   []structs.WebhookAttempt{},
+		Created:    created,
+		Event:      coalesce(item["event"], ""),
+		Redelivery: coalesce(item["redelivery"], ""),
+		Status:     coalesce(item["status"], ""),
+		Updated:    updated,
+	}
+
+	// each attempt is appended as a json string
+	if item["attempts"] != nil {
+		for _, av := range item["attempts"].L {
+			var a structs.WebhookAttempt
+
+			if av.S != nil && json.Unmarshal([]byte(*av.S), &a) == nil {
+				d.Attempts = append(d.Attempts, a)
+			}
+		}
+	}
+
+	return d
+}
+
+// validateWebhookParameters rejects filters and templates the forwarder could not use
+func validateWebhookParameters(params map[string]string) error {
+	if _, err := webhook.ParseFilter(params["Filter"]); err != nil {
+		return err
+	}
+
+	if _, err := webhook.NewTemplate(params["Template"]); err != nil {
+		return err
+	}
+
+	return nil
+}
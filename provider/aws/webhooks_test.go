@@ -0,0 +1,166 @@
+package aws_test
+
+import (
+	"testing"
+
+	"github.com/convox/rack/pkg/options"
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDeliveryList(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleWebhookDescribeStacks,
+		cycleWebhookScan,
+	)
+	defer provider.Close()
+
+	ds, err := provider.WebhookDeliveryList("hook1", structs.WebhookDeliveryListOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, ds, 2)
+	assert.Equal(t, "delivery2", ds[0].Id)
+	assert.Equal(t, "delivery1", ds[1].Id)
+	assert.Equal(t, []structs.WebhookAttempt{{Attempt: 1, Error: "response status 503", Status: 503}}, ds[0].Attempts)
+}
+
+func TestWebhookDeliveryListNotWebhook(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleResourceDescribeStacks,
+	)
+	defer provider.Close()
+
+	_, err := provider.WebhookDeliveryList("syslog", structs.WebhookDeliveryListOptions{})
+
+	assert.EqualError(t, err, "resource is not a webhook: syslog")
+}
+
+func TestWebhookPreview(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleWebhookDescribeStacks,
+	)
+	defer provider.Close()
+
+	wp, err := provider.WebhookPreview("hook1", structs.WebhookPreviewOptions{
+		Filter:   options.String("status=error"),
+		Template: options.String("text"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &structs.WebhookPreview{
+		ContentType: "text/plain",
+		Matched:     false,
+		Payload:     "[convox] release:promote success app=example id=RABCDEFGHIJ",
+	}, wp)
+}
+
+func TestWebhookPreviewInvalidTemplate(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleWebhookDescribeStacks,
+	)
+	defer provider.Close()
+
+	_, err := provider.WebhookPreview("hook1", structs.WebhookPreviewOptions{Template: options.String("{{ .Action")})
+
+	assert.Error(t, err)
+}
+
+func TestWebhookRedeliverNotFound(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleWebhookDescribeStacks,
+		cycleWebhookGetNoItem,
+	)
+	defer provider.Close()
+
+	err := provider.WebhookRedeliver("hook1", "delivery3")
+
+	assert.EqualError(t, err, "delivery not found: delivery3")
+}
+
+var cycleWebhookDescribeStacks = awsutil.Cycle{
+	Request: awsutil.Request{Method: "POST", RequestURI: "/", Operation: "", Body: `Action=DescribeStacks&StackName=convox-hook1&Version=2010-05-15`},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `<DescribeStacksResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/">
+			<DescribeStacksResult>
+				<Stacks>
+					<member>
+						<Outputs>
+							<member>
+								<OutputKey>Deliveries</OutputKey>
+								<OutputValue>convox-hook1-Deliveries</OutputValue>
+							</member>
+							<member>
+								<OutputKey>Queue</OutputKey>
+								<OutputValue>https://sqs.us-east-1.amazonaws.com/778743527532/convox-hook1-Queue</OutputValue>
+							</member>
+						</Outputs>
+						<StackName>convox-hook1</StackName>
+						<StackStatus>UPDATE_COMPLETE</StackStatus>
+						<Tags>
+							<member>
+								<Value>webhook</Value>
+								<Key>Resource</Key>
+							</member>
+							<member>
+								<Value>resource</Value>
+								<Key>Type</Key>
+							</member>
+						</Tags>
+					</member>
+				</Stacks>
+			</DescribeStacksResult>
+		</DescribeStacksResponse>`,
+	},
+}
+
+var cycleWebhookGetNoItem = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "DynamoDB_20120810.GetItem",
+		Body: `{
+			"ConsistentRead": true,
+			"Key": {
+				"id": {
+					"S": "delivery3"
+				}
+			},
+			"TableName": "convox-hook1-Deliveries"
+		}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body:       `{}`,
+	},
+}
+
+var cycleWebhookScan = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "DynamoDB_20120810.Scan",
+		Body:       `{"TableName":"convox-hook1-Deliveries"}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `{
+			"Count": 2,
+			"Items": [
+				{
+					"id": {"S": "delivery1"},
+					"action": {"S": "app:create"},
+					"created": {"S": "20160101.000000.000000000"},
+					"status": {"S": "success"}
+				},
+				{
+					"id": {"S": "delivery2"},
+					"action": {"S": "release:promote"},
+					"attempts": {"L": [{"S": "{\"attempt\":1,\"duration\":0,\"error\":\"response status 503\",\"status\":503,\"time\":\"0001-01-01T00:00:00Z\"}"}]},
+					"created": {"S": "20160102.000000.000000000"},
+					"status": {"S": "retrying"}
+				}
+			],
+			"ScannedCount": 2
+		}`,
+	},
+}
@@ -1,12 +1,209 @@
 package aws
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/convox/logger"
+	"github.com/convox/rack/pkg/leader"
+	"github.com/convox/rack/pkg/scheduler"
+	"github.com/convox/rack/pkg/structs"
+)
+
+const (
+	// workers are saved to the settings bucket so the api can report them
+	workersKey = "system/workers.json"
+
+	// saving worker state is skipped when the last save was more recent
+	workersSaveInterval = 10 * time.Second
+)
+
+// Workers consumes the rack event queues in every process and runs the
+// scheduled jobs only in the process holding the workers lease
 func (p *Provider) Workers() error {
-	go p.workerCleanup()
-	go p.workerEvents()
-	go p.workerHeartbeat()
-	go p.workerMonitor()
-	go p.workerSpotReplace()
-	go p.workerSyncInstanceIPs()
+	log := logger.New("ns=workers")
+
+	p.workerEvents()
+
+	// racks without a leases table run jobs in every process
+	if p.DynamoLeases == "" {
+		s, err := p.workerScheduler(log)
+		if err != nil {
+			return log.Error(err)
+		}
+
+		s.Start()
+
+		return nil
+	}
+
+	e := leader.New(&dynamoLock{provider: p}, "workers")
+
+	go e.Run(context.Background(), func(ctx context.Context) {
+		log.Logf("leader=%s state=leading", e.Holder)
+
+		s, err := p.workerScheduler(log)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		s.Start()
+
+		<-ctx.Done()
+
+		s.Stop()
+
+		log.Logf("leader=%s state=stopped", e.Holder)
+	})
 
 	return nil
 }
+
+func (p *Provider) workerScheduler(log *logger.Logger) (*scheduler.Scheduler, error) {
+	s := scheduler.New()
+
+	intervals, err := scheduler.ParseIntervals(p.WorkerIntervals)
+	if err != nil {
+		log.Error(err)
+	}
+
+	interval := func(name string, def time.Duration) time.Duration {
+		if d, ok := intervals[name]; ok {
+			return d
+		}
+		return def
+	}
+
+	disconnected := map[string]struct{}{}
+
+	spotReplace := interval("spot-replace", 60*time.Second)
+
+	if !p.SpotInstances {
+		spotReplace = 0
+	}
+
+	jobs := []scheduler.Job{
+		{
+			Name:     "builds",
+			Fn:       func(ctx context.Context) error { return p.expireBuilds(ctx, logger.New("ns=workers.builds")) },
+			Interval: interval("builds", 1*time.Minute),
+			Jitter:   5 * time.Second,
+			Timeout:  50 * time.Second,
+		},
+		{
+			Name:     "cleanup",
+			Fn:       func(ctx context.Context) error { return p.cleanupBuilds(ctx, logger.New("ns=workers.cleanup")) },
+			Interval: interval("cleanup", 1*time.Hour),
+			Jitter:   5 * time.Minute,
+			Timeout:  30 * time.Minute,
+		},
+		{
+			Name:     "ecs-events",
+			Fn:       func(ctx context.Context) error { return p.pollECSEvents(ctx) },
+			Interval: interval("ecs-events", time.Duration(p.EcsPollInterval)*time.Second),
+			Timeout:  1 * time.Minute,
+		},
+		{
+			Name:      "heartbeat",
+			Fn:        func(ctx context.Context) error { return p.heartbeat() },
+			Immediate: true,
+			Interval:  interval("heartbeat", 1*time.Hour),
+			Jitter:    5 * time.Minute,
+			Timeout:   5 * time.Minute,
+		},
+		{
+			Name:     "instance-drain",
+			Fn:       func(ctx context.Context) error { return p.drainInstances(ctx) },
+			Interval: interval("instance-drain", 30*time.Second),
+			Timeout:  2 * time.Minute,
+		},
+		{
+			Name:     "monitor",
+			Fn:       func(ctx context.Context) error { return p.monitor(ctx, disconnected) },
+			Interval: interval("monitor", 5*time.Minute),
+			Jitter:   30 * time.Second,
+			Timeout:  4 * time.Minute,
+		},
+		{
+			Name:     "spot-replace",
+			Fn:       func(ctx context.Context) error { return p.spotReplace(ctx) },
+			Interval: spotReplace,
+			Jitter:   5 * time.Second,
+			Timeout:  50 * time.Second,
+		},
+		{
+			Name:     "sync-instance-ips",
+			Fn:       func(ctx context.Context) error { return p.SyncInstancesIpInSecurityGroup() },
+			Interval: interval("sync-instance-ips", 30*time.Minute),
+			Jitter:   1 * time.Minute,
+			Timeout:  10 * time.Minute,
+		},
+	}
+
+	for _, j := range jobs {
+		if err := s.Register(j); err != nil {
+			return nil, err
+		}
+	}
+
+	s.Report = p.workersSaver(log)
+
+	return s, nil
+}
+
+// SystemWorkers returns the state of the background workers as last saved by
+// the rack monitor
+func (p *Provider) SystemWorkers() (structs.Workers, error) {
+	exists, err := p.s3Exists(p.SettingsBucket, workersKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return structs.Workers{}, nil
+	}
+
+	data, err := p.s3Get(p.SettingsBucket, workersKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var ws structs.Workers
+
+	if err := json.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("invalid worker state: %s", err)
+	}
+
+	return ws, nil
+}
+
+func (p *Provider) workersSaver(log *logger.Logger) func(structs.Workers) {
+	var lock sync.Mutex
+	var saved time.Time
+
+	return func(ws structs.Workers) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if time.Since(saved) < workersSaveInterval {
+			return
+		}
+
+		data, err := json.Marshal(ws)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		if err := p.s3Put(p.SettingsBucket, workersKey, data, false); err != nil {
+			log.Error(err)
+			return
+		}
+
+		saved = time.Now()
+	}
+}
@@ -1,54 +1,32 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/convox/logger"
-	"github.com/convox/rack/pkg/helpers"
 	"github.com/convox/rack/pkg/options"
 	"github.com/convox/rack/pkg/structs"
 )
 
 const CONVOX_INSTANCE_MANAGED = "CONVOX_INSTANCE"
 
-func (p *Provider) workerCleanup() {
-	log := logger.New("ns=workers.cleanup")
-
-	defer recoverWith(func(err error) {
-		helpers.Error(log, err)
-	})
-
-	for range time.Tick(1 * time.Hour) {
-		p.cleanupBuilds(log)
-	}
-}
-
-func (p *Provider) workerSyncInstanceIPs() {
-	log := logger.New("ns=workers.syncInstanceips")
-
-	defer recoverWith(func(err error) {
-		helpers.Error(log, err)
-	})
-
-	for range time.Tick(30 * time.Minute) {
-		err := p.SyncInstancesIpInSecurityGroup()
-		log.Error(err)
-	}
-}
-
-func (p *Provider) cleanupBuilds(log *logger.Logger) error {
+func (p *Provider) cleanupBuilds(ctx context.Context, log *logger.Logger) error {
 	as, err := p.AppList()
 	if err != nil {
 		return log.Error(err)
 	}
 
 	for _, a := range as {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		log = log.Replace("app", a.Name)
 
 		log = log.At("images")
@@ -64,6 +42,13 @@ func (p *Provider) cleanupBuilds(log *logger.Logger) error {
 		} else {
 			log.Logf("expired=%d", count)
 		}
+
+		log = log.At("cache")
+		if count, err := p.cleanupAppBuildCache(a); err != nil {
+			log.Error(err)
+		} else {
+			log.Logf("expired=%d", count)
+		}
 	}
 
 	return nil
@@ -160,7 +145,11 @@ func (p *Provider) cleanupAppImages(a structs.App) (int, error) {
 			continue
 		}
 
-		if _, ok := bh[parts[1]]; !ok && parts[1] != active {
+		// builds for several platforms also push a tag for each platform
+		// suffixed with it
+		id := strings.SplitN(parts[1], "-", 2)[0]
+
+		if _, ok := bh[id]; !ok && id != active {
 			remove = append(remove, tag)
 		}
 	}
@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/logger"
+	"github.com/convox/rack/provider/aws/ecsdrain"
+)
+
+// drainInstances terminates instances that were drained to be terminated once
+// their tasks have been rescheduled or their drain deadline has passed
+func (p *Provider) drainInstances(ctx context.Context) error {
+	log := logger.New("ns=workers.drain").At("drainInstances")
+
+	res, err := p.listAndDescribeContainerInstances()
+	if err != nil {
+		return log.Error(err)
+	}
+
+	for _, cci := range res.ContainerInstances {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if cs(cci.Status, "") != "DRAINING" {
+			continue
+		}
+
+		attrs := map[string]string{}
+
+		for _, a := range cci.Attributes {
+			attrs[cs(a.Name, "")] = cs(a.Value, "")
+		}
+
+		if attrs[drainTerminateAttribute] != "true" {
+			continue
+		}
+
+		id := cs(cci.Ec2InstanceId, "")
+		tasks := ci(cci.RunningTasksCount, 0) + ci(cci.PendingTasksCount, 0)
+
+		deadline, err := strconv.ParseInt(attrs[drainDeadlineAttribute], 10, 64)
+		if err != nil {
+			// a missing or malformed deadline restarts the drain timeout rather
+			// than terminating the instance with its tasks still running
+			log.Logf("id=%s error=%q state=reset", id, "invalid drain deadline")
+
+			if err := p.resetDrainDeadline(cci); err != nil {
+				return log.Error(err)
+			}
+
+			continue
+		}
+
+		if tasks > 0 && time.Now().Unix() < deadline {
+			log.Logf("id=%s tasks=%d state=draining", id, tasks)
+			continue
+		}
+
+		log.Logf("id=%s tasks=%d state=terminating", id, tasks)
+
+		if err := p.terminateInstance(id, attrs[drainDecrementAttribute] == "true"); err != nil {
+			return log.Error(err)
+		}
+
+		// terminate only once, the container instance lingers until the
+		// lifecycle handler deregisters it
+		req := &ecs.DeleteAttributesInput{
+			Cluster: aws.String(p.Cluster),
+		}
+
+		for _, k := range []string{drainDeadlineAttribute, drainDecrementAttribute, drainTerminateAttribute} {
+			req.Attributes = append(req.Attributes, &ecs.Attribute{
+				Name:       aws.String(k),
+				TargetId:   cci.ContainerInstanceArn,
+				TargetType: aws.String("container-instance"),
+			})
+		}
+
+		if _, err := p.ecs().DeleteAttributes(req); err != nil {
+			return log.Error(err)
+		}
+	}
+
+	return log.Success()
+}
+
+// resetDrainDeadline gives a draining instance a new drain deadline
+func (p *Provider) resetDrainDeadline(cci *ecs.ContainerInstance) error {
+	_, err := p.ecs().PutAttributes(&ecs.PutAttributesInput{
+		Attributes: []*ecs.Attribute{
+			{
+				Name:       aws.String(drainDeadlineAttribute),
+				TargetId:   cci.ContainerInstanceArn,
+				TargetType: aws.String("container-instance"),
+				Value:      aws.String(strconv.FormatInt(time.Now().Add(ecsdrain.DefaultTimeout).Unix(), 10)),
+			},
+		},
+		Cluster: aws.String(p.Cluster),
+	})
+
+	return err
+}
@@ -0,0 +1,246 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainInstances(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleDrainListContainerInstances,
+		cycleDrainDescribeContainerInstances,
+		cycleDrainTerminateInstance("i-1", true),
+		cycleDrainPublish,
+		cycleDrainDeleteAttributes("ci-1"),
+		cycleDrainTerminateInstance("i-3", false),
+		cycleDrainPublish,
+		cycleDrainDeleteAttributes("ci-3"),
+	)
+	defer closer()
+
+	require.NoError(t, p.drainInstances(context.Background()))
+}
+
+func TestDrainInstancesCancelled(t *testing.T) {
+	p, closer := testInternalProvider(
+		cycleDrainListContainerInstances,
+		cycleDrainDescribeContainerInstances,
+	)
+	defer closer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Equal(t, context.Canceled, p.drainInstances(ctx))
+}
+
+func TestDrainInstancesInvalidDeadline(t *testing.T) {
+	p, closer := testInternalProvider(
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				RequestURI: "/",
+				Operation:  "AmazonEC2ContainerServiceV20141113.ListContainerInstances",
+				Body:       `{"cluster": "cluster-test", "nextToken": ""}`,
+			},
+			Response: awsutil.Response{
+				StatusCode: 200,
+				Body:       `{"containerInstanceArns": ["arn:aws:ecs:us-east-1:123456789012:container-instance/ci-5"]}`,
+			},
+		},
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				RequestURI: "/",
+				Operation:  "AmazonEC2ContainerServiceV20141113.DescribeContainerInstances",
+				Body:       `{"cluster": "cluster-test", "containerInstances": ["arn:aws:ecs:us-east-1:123456789012:container-instance/ci-5"]}`,
+			},
+			Response: awsutil.Response{
+				StatusCode: 200,
+				Body: `{
+					"containerInstances": [
+						{
+							"attributes": [
+								{"name": "convox.drain.deadline", "value": "soon"},
+								{"name": "convox.drain.terminate", "value": "true"}
+							],
+							"containerInstanceArn": "arn:aws:ecs:us-east-1:123456789012:container-instance/ci-5",
+							"ec2InstanceId": "i-5",
+							"pendingTasksCount": 0,
+							"runningTasksCount": 3,
+							"status": "DRAINING"
+						}
+					]
+				}`,
+			},
+		},
+		awsutil.Cycle{
+			Request: awsutil.Request{
+				RequestURI: "/",
+				Operation:  "AmazonEC2ContainerServiceV20141113.PutAttributes",
+				Body:       `/^\{"attributes":\[\{"name":"convox.drain.deadline","targetId":"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-5","targetType":"container-instance","value":"[0-9]+"\}\],"cluster":"cluster-test"\}$/`,
+			},
+			Response: awsutil.Response{
+				StatusCode: 200,
+				Body:       `{"attributes": []}`,
+			},
+		},
+	)
+	defer closer()
+
+	require.NoError(t, p.drainInstances(context.Background()))
+}
+
+func cycleDrainDeleteAttributes(ci string) awsutil.Cycle {
+	target := "arn:aws:ecs:us-east-1:123456789012:container-instance/" + ci
+
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "AmazonEC2ContainerServiceV20141113.DeleteAttributes",
+			Body: `{
+				"attributes": [
+					{"name": "convox.drain.deadline", "targetId": "` + target + `", "targetType": "container-instance"},
+					{"name": "convox.drain.decrement", "targetId": "` + target + `", "targetType": "container-instance"},
+					{"name": "convox.drain.terminate", "targetId": "` + target + `", "targetType": "container-instance"}
+				],
+				"cluster": "cluster-test"
+			}`,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       `{"attributes": []}`,
+		},
+	}
+}
+
+var cycleDrainDescribeContainerInstances = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "AmazonEC2ContainerServiceV20141113.DescribeContainerInstances",
+		Body: `{
+			"cluster": "cluster-test",
+			"containerInstances": [
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-1",
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-2",
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-3",
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-4"
+			]
+		}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `{
+			"containerInstances": [
+				{
+					"attributes": [
+						{"name": "convox.drain.deadline", "value": "4102444800"},
+						{"name": "convox.drain.decrement", "value": "true"},
+						{"name": "convox.drain.terminate", "value": "true"}
+					],
+					"containerInstanceArn": "arn:aws:ecs:us-east-1:123456789012:container-instance/ci-1",
+					"ec2InstanceId": "i-1",
+					"pendingTasksCount": 0,
+					"runningTasksCount": 0,
+					"status": "DRAINING"
+				},
+				{
+					"attributes": [
+						{"name": "convox.drain.deadline", "value": "4102444800"},
+						{"name": "convox.drain.decrement", "value": "false"},
+						{"name": "convox.drain.terminate", "value": "true"}
+					],
+					"containerInstanceArn": "arn:aws:ecs:us-east-1:123456789012:container-instance/ci-2",
+					"ec2InstanceId": "i-2",
+					"pendingTasksCount": 0,
+					"runningTasksCount": 2,
+					"status": "DRAINING"
+				},
+				{
+					"attributes": [
+						{"name": "convox.drain.deadline", "value": "946684800"},
+						{"name": "convox.drain.decrement", "value": "false"},
+						{"name": "convox.drain.terminate", "value": "true"}
+					],
+					"containerInstanceArn": "arn:aws:ecs:us-east-1:123456789012:container-instance/ci-3",
+					"ec2InstanceId": "i-3",
+					"pendingTasksCount": 0,
+					"runningTasksCount": 1,
+					"status": "DRAINING"
+				},
+				{
+					"containerInstanceArn": "arn:aws:ecs:us-east-1:123456789012:container-instance/ci-4",
+					"ec2InstanceId": "i-4",
+					"pendingTasksCount": 0,
+					"runningTasksCount": 0,
+					"status": "DRAINING"
+				}
+			]
+		}`,
+	},
+}
+
+var cycleDrainListContainerInstances = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Operation:  "AmazonEC2ContainerServiceV20141113.ListContainerInstances",
+		Body:       `{"cluster": "cluster-test", "nextToken": ""}`,
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `{
+			"containerInstanceArns": [
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-1",
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-2",
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-3",
+				"arn:aws:ecs:us-east-1:123456789012:container-instance/ci-4"
+			]
+		}`,
+	},
+}
+
+var cycleDrainPublish = awsutil.Cycle{
+	Request: awsutil.Request{
+		RequestURI: "/",
+		Body:       "ignore",
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body: `
+			<PublishResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/">
+				<PublishResult>
+					<MessageId>94f20ce6-13c5-43a0-9a9e-ca52d816e90b</MessageId>
+				</PublishResult>
+			</PublishResponse>
+		`,
+	},
+}
+
+func cycleDrainTerminateInstance(id string, decrement bool) awsutil.Cycle {
+	d := "false"
+
+	if decrement {
+		d = "true"
+	}
+
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Body:       "Action=TerminateInstanceInAutoScalingGroup&InstanceId=" + id + "&ShouldDecrementDesiredCapacity=" + d + "&Version=2011-01-01",
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body: `
+				<TerminateInstanceInAutoScalingGroupResponse xmlns="http://autoscaling.amazonaws.com/doc/2011-01-01/">
+					<TerminateInstanceInAutoScalingGroupResult>
+						<Activity>
+							<ActivityId>cczc44a87-7d04-dsa15-31-d27c219864c5</ActivityId>
+							<StatusCode>InProgress</StatusCode>
+						</Activity>
+					</TerminateInstanceInAutoScalingGroupResult>
+				</TerminateInstanceInAutoScalingGroupResponse>
+			`,
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -29,7 +30,7 @@ type queueHandler func(body string) error
 type ecsEvent struct {
 	Account    string
 	DetailType string `json:"detail-type"`
-	Detail     interface{}
+	Detail     json.RawMessage
 	ID         string
 	Region     string
 	Resources  []string
@@ -61,7 +62,6 @@ type detailTaskStateChange struct {
 func (p *Provider) workerEvents() {
 	go p.handleAccountEvents()
 	go p.handleCloudformationEvents()
-	go p.handleECSEvents()
 }
 
 func (p *Provider) handleAccountEvents() {
@@ -72,6 +72,18 @@ func (p *Provider) handleAccountEvents() {
 			return err
 		}
 
+		if e.DetailType == "ECS Task State Change" {
+			var d detailTaskStateChange
+
+			if err := json.Unmarshal(e.Detail, &d); err != nil {
+				return err
+			}
+
+			if err := p.invalidateTask(d); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -102,6 +114,10 @@ func (p *Provider) handleCloudformationEvents() {
 
 		stack := message["StackName"]
 
+		if err := p.invalidateStack(stack); err != nil {
+			fmt.Fprintf(os.Stderr, "invalidateStack %s error: %s\n", stack, err)
+		}
+
 		group, err := p.getStackLogGroup(stack)
 		if err != nil {
 			return err
@@ -162,17 +178,7 @@ func (p *Provider) handleCloudformationEvents() {
 	}
 }
 
-func (p *Provider) handleECSEvents() {
-	for {
-		time.Sleep(time.Duration(p.EcsPollInterval) * time.Second)
-
-		if err := p.pollECSEvents(); err != nil {
-			fmt.Printf("err = %+v\n", err)
-		}
-	}
-}
-
-func (p *Provider) pollECSEvents() error {
+func (p *Provider) pollECSEvents(ctx context.Context) error {
 	prefix := fmt.Sprintf("%s-", p.Rack)
 	isRackApiServiceRegex := regexp.MustCompile(p.RackApiServiceName)
 
@@ -181,6 +187,10 @@ func (p *Provider) pollECSEvents() error {
 	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		lres, err := p.ecs().ListServices(lreq)
 		if err != nil {
 			break
@@ -259,6 +269,46 @@ func (p *Provider) pollECSEvents() error {
 	return nil
 }
 
+// invalidateStack replaces the cached descriptions of a stack, and the list
+// of all stacks that includes it, in every process after any of its events
+func (p *Provider) invalidateStack(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	if err := p.bumpGeneration(stackScope(&name)); err != nil {
+		return err
+	}
+
+	return p.bumpGeneration(stackScope(nil))
+}
+
+// invalidateTask replaces the cached task list and task descriptions of the
+// app a task belongs to in every process when its state changes
+func (p *Provider) invalidateTask(d detailTaskStateChange) error {
+	app := p.taskApp(d)
+	if app == "" {
+		return nil
+	}
+
+	return p.bumpGeneration(tasksScope(app))
+}
+
+// taskApp returns the app of a task started by a service or as a one-off process
+func (p *Provider) taskApp(d detailTaskStateChange) string {
+	if strings.HasPrefix(d.StartedBy, "convox.") {
+		return strings.TrimPrefix(d.StartedBy, "convox.")
+	}
+
+	prefix := fmt.Sprintf("service:%s-", p.Rack)
+
+	if strings.HasPrefix(d.Group, prefix) && strings.Contains(d.Group, "-Service") {
+		return strings.Split(strings.TrimPrefix(d.Group, prefix), "-Service")[0]
+	}
+
+	return ""
+}
+
 func (p *Provider) getStackLogGroup(stack string) (string, error) {
 	if group, ok := cache.Get("stackLogGroup", stack).(string); ok {
 		return group, nil
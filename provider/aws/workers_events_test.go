@@ -0,0 +1,154 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskApp(t *testing.T) {
+	p := &Provider{Rack: "convox"}
+
+	cases := []struct {
+		detail detailTaskStateChange
+		app    string
+	}{
+		{detailTaskStateChange{Group: "service:convox-app1-ServiceWeb-1A2B3C4D5E6F", StartedBy: "ecs-svc/1234567890"}, "app1"},
+		{detailTaskStateChange{Group: "service:convox-my-app-ServiceWorker-1A2B3C4D5E6F"}, "my-app"},
+		{detailTaskStateChange{Group: "family:convox-app1-web", StartedBy: "convox.app1"}, "app1"},
+		{detailTaskStateChange{Group: "service:other-app1-ServiceWeb-1A2B3C4D5E6F"}, ""},
+		{detailTaskStateChange{Group: "family:convox-app1-web"}, ""},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.app, p.taskApp(c.detail), c.detail.Group)
+	}
+}
+
+func TestStackScope(t *testing.T) {
+	require.Equal(t, "stacks", stackScope(nil))
+	require.Equal(t, "stack/convox-app1", stackScope(aws.String("convox-app1")))
+	require.Equal(t, "stack/convox-app1", stackScope(aws.String("arn:aws:cloudformation:us-east-1:123456789012:stack/convox-app1/d0a8c2e0-1a2b-11ef-9f4c-0a1b2c3d4e5f")))
+}
+
+// the monitor handles events in its own process, the entries cached by the
+// api process are replaced through the generation it bumps
+func TestInvalidateTaskEvent(t *testing.T) {
+	api, closer := testInternalProvider(
+		cycleGenerationGet("tasks/events1", "1"),
+		cycleEventsDescribeTasks("RUNNING"),
+		cycleGenerationBump("tasks/events1"),
+		cycleGenerationGet("tasks/events1", "2"),
+		cycleEventsDescribeTasks("STOPPED"),
+	)
+	defer closer()
+
+	api.SkipCache = false
+
+	monitor := &Provider{Region: api.Region, Endpoint: api.Endpoint, DynamoLeases: api.DynamoLeases, Rack: api.Rack}
+
+	input := &ecs.DescribeTasksInput{Cluster: aws.String("cluster-test"), Tasks: []*string{aws.String("arn:aws:ecs:us-east-1:123456789012:task/cluster-test/events1")}}
+
+	for i := 0; i < 2; i++ {
+		res, err := api.describeAppTasks("events1", input)
+		require.NoError(t, err)
+		require.Equal(t, "RUNNING", *res.Tasks[0].LastStatus)
+	}
+
+	require.NoError(t, monitor.invalidateTask(detailTaskStateChange{Group: "service:convox-events1-ServiceWeb-1A2B3C4D5E6F"}))
+
+	res, err := api.describeAppTasks("events1", input)
+	require.NoError(t, err)
+	require.Equal(t, "STOPPED", *res.Tasks[0].LastStatus)
+}
+
+func TestInvalidateStackEvent(t *testing.T) {
+	api, closer := testInternalProvider(
+		cycleGenerationGet("stack/convox-events2", "1"),
+		cycleEventsDescribeStacks("UPDATE_IN_PROGRESS"),
+		cycleGenerationBump("stack/convox-events2"),
+		cycleGenerationBump("stacks"),
+		cycleGenerationGet("stack/convox-events2", "2"),
+		cycleEventsDescribeStacks("UPDATE_COMPLETE"),
+	)
+	defer closer()
+
+	api.SkipCache = false
+
+	monitor := &Provider{Region: api.Region, Endpoint: api.Endpoint, DynamoLeases: api.DynamoLeases, Rack: api.Rack}
+
+	input := &cloudformation.DescribeStacksInput{StackName: aws.String("convox-events2")}
+
+	for i := 0; i < 2; i++ {
+		ss, err := api.describeStacks(input)
+		require.NoError(t, err)
+		require.Equal(t, "UPDATE_IN_PROGRESS", *ss[0].StackStatus)
+	}
+
+	require.NoError(t, monitor.invalidateStack("convox-events2"))
+
+	ss, err := api.describeStacks(input)
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE_COMPLETE", *ss[0].StackStatus)
+}
+
+func cycleGenerationGet(scope, generation string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.GetItem",
+			Body:       fmt.Sprintf(`{"ConsistentRead":true,"Key":{"name":{"S":"generation/%s"}},"TableName":"convox-leases"}`, scope),
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       fmt.Sprintf(`{"Item":{"name":{"S":"generation/%s"},"generation":{"N":"%s"}}}`, scope, generation),
+		},
+	}
+}
+
+func cycleGenerationBump(scope string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "DynamoDB_20120810.UpdateItem",
+			Body:       fmt.Sprintf(`/"Key":{"name":{"S":"generation/%s"}},"TableName":"convox-leases","UpdateExpression":"ADD #generation :one SET #expires = :expires"/`, scope),
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       `{}`,
+		},
+	}
+}
+
+func cycleEventsDescribeTasks(status string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			RequestURI: "/",
+			Operation:  "AmazonEC2ContainerServiceV20141113.DescribeTasks",
+			Body:       `{"cluster":"cluster-test","tasks":["arn:aws:ecs:us-east-1:123456789012:task/cluster-test/events1"]}`,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       fmt.Sprintf(`{"tasks":[{"taskArn":"arn:aws:ecs:us-east-1:123456789012:task/cluster-test/events1","lastStatus":"%s"}],"failures":[]}`, status),
+		},
+	}
+}
+
+func cycleEventsDescribeStacks(status string) awsutil.Cycle {
+	return awsutil.Cycle{
+		Request: awsutil.Request{
+			Method:     "POST",
+			RequestURI: "/",
+			Body:       `Action=DescribeStacks&StackName=convox-events2&Version=2010-05-15`,
+		},
+		Response: awsutil.Response{
+			StatusCode: 200,
+			Body:       fmt.Sprintf(`<DescribeStacksResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/"><DescribeStacksResult><Stacks><member><StackName>convox-events2</StackName><StackStatus>%s</StackStatus></member></Stacks></DescribeStacksResult></DescribeStacksResponse>`, status),
+		},
+	}
+}
@@ -2,31 +2,23 @@ package aws
 
 import (
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/convox/logger"
-	"github.com/convox/rack/pkg/helpers"
 )
 
-func (p *Provider) workerHeartbeat() {
-	helpers.Tick(1*time.Hour, p.heartbeat)
-}
-
-func (p *Provider) heartbeat() {
+func (p *Provider) heartbeat() error {
 	var log = logger.New("ns=workers.heartbeat")
 
 	s, err := p.SystemGet()
 	if err != nil {
-		log.Error(err)
-		return
+		return log.Error(err)
 	}
 
 	as, err := p.AppList()
 	if err != nil {
-		log.Error(err)
-		return
+		return log.Error(err)
 	}
 
 	req := &ec2.DescribeInstancesInput{
@@ -53,8 +45,7 @@ func (p *Provider) heartbeat() {
 		return true
 	})
 	if err != nil {
-		log.Error(err)
-		return
+		return log.Error(err)
 	}
 
 	ms := map[string]interface{}{
@@ -78,7 +69,8 @@ func (p *Provider) heartbeat() {
 	}
 
 	if err := p.Metrics.Post("heartbeat", ms); err != nil {
-		log.Error(err)
-		return
+		return log.Error(err)
 	}
+
+	return nil
 }
@@ -1,13 +1,12 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/convox/rack/pkg/helpers"
-
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/convox/logger"
@@ -28,69 +27,68 @@ type instances map[string]instance
 
 var lastASGActivity = time.Now()
 
-func (p *Provider) workerMonitor() {
+// monitor marks instances unhealthy when their ECS agent is disconnected for
+// two runs in a row, disconnected holds the instances seen on the last run
+func (p *Provider) monitor(ctx context.Context, disconnected map[string]struct{}) error {
 	var log = logger.New("ns=workers.monitor")
 
-	defer recoverWith(func(err error) {
-		helpers.Error(log, err)
-	})
+	log.Logf("tick")
 
-	disconnectedInstances := map[string]struct{}{}
-	for range time.Tick(5 * time.Minute) {
-		log.Logf("tick")
+	ii := instances{}
 
-		ii := instances{}
+	if err := p.describeASG(&ii); err != nil {
+		return log.Error(err)
+	}
 
-		if err := p.describeASG(&ii); err != nil {
-			log.Error(err)
-			continue
+	if err := p.describeECS(&ii); err != nil {
+		return log.Error(err)
+	}
+
+	// Test if ASG Instance is registered and connected in ECS cluster
+	for k, i := range ii {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		if err := p.describeECS(&ii); err != nil {
-			log.Error(err)
+		if !i.ASG {
+			// TODO: Rogue instance?! Terminate?
 			continue
 		}
 
-		// Test if ASG Instance is registered and connected in ECS cluster
-		for k, i := range ii {
-			if !i.ASG {
-				// TODO: Rogue instance?! Terminate?
+		if !i.ECS {
+			_, seenBefore := disconnected[i.Id]
+
+			if !seenBefore {
+				disconnected[i.Id] = struct{}{}
+				fmt.Printf("who=\"convox/monitor\" what=\"instance %s missed it's first heartbeat\" why=\"ECS reported agent disconnected\"\n", i.Id)
 				continue
 			}
-
-			if !i.ECS {
-				_, seenBefore := disconnectedInstances[i.Id]
-
-				if !seenBefore {
-					disconnectedInstances[i.Id] = struct{}{}
-					fmt.Printf("who=\"convox/monitor\" what=\"instance %s missed it's first heartbeat\" why=\"ECS reported agent disconnected\"\n", i.Id)
-					continue
-				}
-				// Not registered or not connected => set Unhealthy
-				_, err := p.autoscaling().SetInstanceHealth(
-					&autoscaling.SetInstanceHealthInput{
-						HealthStatus:             aws.String("Unhealthy"),
-						InstanceId:               aws.String(i.Id),
-						ShouldRespectGracePeriod: aws.Bool(true),
-					},
-				)
-
-				i.Unhealthy = true
-				ii[k] = i
-
-				if err != nil {
-					log.Error(err)
-					continue
-				}
-
-				// log for humans
-				fmt.Printf("who=\"convox/monitor\" what=\"marked instance %s unhealthy\" why=\"ECS reported agent disconnected\"\n", i.Id)
+			// Not registered or not connected => set Unhealthy
+			_, err := p.autoscaling().SetInstanceHealth(
+				&autoscaling.SetInstanceHealthInput{
+					HealthStatus:             aws.String("Unhealthy"),
+					InstanceId:               aws.String(i.Id),
+					ShouldRespectGracePeriod: aws.Bool(true),
+				},
+			)
+
+			i.Unhealthy = true
+			ii[k] = i
+
+			if err != nil {
+				log.Error(err)
+				continue
 			}
-			delete(disconnectedInstances, i.Id)
-		}
 
-		log.Logf("%s", ii.log())
+			// log for humans
+			fmt.Printf("who=\"convox/monitor\" what=\"marked instance %s unhealthy\" why=\"ECS reported agent disconnected\"\n", i.Id)
+		}
+		delete(disconnected, i.Id)
 	}
+
+	log.Logf("%s", ii.log())
+
+	return nil
 }
 
 func (p *Provider) describeASG(ii *instances) error {
@@ -1,41 +1,25 @@
 package aws
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strconv"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/convox/logger"
+	"github.com/convox/rack/provider/aws/ecsdrain"
 )
 
 const (
 	haInstanceCountParam   = "InstanceCount"
 	noHaInstanceCountParam = "NoHaInstanceCount"
-	spotTick               = 60 * time.Second
 )
 
-// Main worker function
-func (p *Provider) workerSpotReplace() {
-	log := logger.New("ns=workers.spotreplace").At("spotReplace")
-
-	if !p.SpotInstances {
-		return
-	}
-
-	tick := time.Tick(spotTick)
-
-	for range tick {
-		if err := p.spotReplace(); err != nil {
-			fmt.Printf("err = %+v\n", err)
-			log.Error(err)
-		}
-	}
-}
-
-func (p *Provider) spotReplace() error {
+func (p *Provider) spotReplace(ctx context.Context) error {
 	log := logger.New("ns=workers.spotreplace").At("spotReplace")
 
 	system, err := p.SystemGet()
@@ -90,6 +74,10 @@ func (p *Provider) spotReplace() error {
 		return nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if spc != spotDesired {
 		log.Logf("stack=SpotInstances setDesiredCount=%d", spotDesired)
 
@@ -100,12 +88,90 @@ func (p *Provider) spotReplace() error {
 
 	onDemandDesired := ic - spcr
 
-	if odc != onDemandDesired {
+	switch {
+	case odc < onDemandDesired:
 		log.Logf("stack=Instances setDesiredCount=%d", onDemandDesired)
 
 		if err := p.setAsgResourceDesiredCount("Instances", onDemandDesired); err != nil {
 			return err
 		}
+	case odc > onDemandDesired:
+		// drain the on demand instances being replaced rather than letting the
+		// autoscaling group stop them with their tasks still running
+		log.Logf("stack=Instances drainCount=%d", odc-onDemandDesired)
+
+		if err := p.drainAsgResourceInstances(ctx, "Instances", odc-onDemandDesired); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drainAsgResourceInstances drains instances from an autoscaling group to be
+// terminated, shrinking the group as each one goes, until count instances are
+// draining
+func (p *Provider) drainAsgResourceInstances(ctx context.Context, resource string, count int) error {
+	asg, err := p.stackResource(p.Rack, resource)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{asg.PhysicalResourceId},
+	})
+	if err != nil {
+		return err
+	}
+	if len(res.AutoScalingGroups) < 1 {
+		return fmt.Errorf("resource not found: %s", resource)
+	}
+
+	members := map[string]bool{}
+
+	for _, i := range res.AutoScalingGroups[0].Instances {
+		if cs(i.LifecycleState, "") == autoscaling.LifecycleStateInService {
+			members[cs(i.InstanceId, "")] = true
+		}
+	}
+
+	cis, err := p.listAndDescribeContainerInstances()
+	if err != nil {
+		return err
+	}
+
+	candidates := []*ecs.ContainerInstance{}
+
+	for _, cci := range cis.ContainerInstances {
+		if !members[cs(cci.Ec2InstanceId, "")] {
+			continue
+		}
+
+		switch cs(cci.Status, "") {
+		case "ACTIVE":
+			candidates = append(candidates, cci)
+		case "DRAINING":
+			for _, a := range cci.Attributes {
+				if cs(a.Name, "") == drainTerminateAttribute && cs(a.Value, "") == "true" {
+					count--
+				}
+			}
+		}
+	}
+
+	// move as few tasks as possible
+	sort.Slice(candidates, func(i, j int) bool {
+		return ci(candidates[i].RunningTasksCount, 0) < ci(candidates[j].RunningTasksCount, 0)
+	})
+
+	for i := 0; i < count && i < len(candidates); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := p.drainInstance(cs(candidates[i].Ec2InstanceId, ""), true, true, ecsdrain.DefaultTimeout); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,80 @@
+package aws_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/rack/pkg/structs"
+	"github.com/convox/rack/pkg/test/awsutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemWorkers(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleWorkersHead,
+		cycleWorkersGet,
+	)
+	defer provider.Close()
+
+	ws, err := provider.SystemWorkers()
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, structs.Workers{
+		structs.Worker{
+			Name:     "monitor",
+			Duration: 2 * time.Second,
+			Interval: 5 * time.Minute,
+			LastRun:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Runs:     1,
+		},
+		structs.Worker{
+			Name:     "spot-replace",
+			Disabled: true,
+		},
+	}, ws)
+}
+
+func TestSystemWorkersMissing(t *testing.T) {
+	provider := StubAwsProvider(
+		cycleWorkersHeadMissing,
+	)
+	defer provider.Close()
+
+	ws, err := provider.SystemWorkers()
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, structs.Workers{}, ws)
+}
+
+var cycleWorkersGet = awsutil.Cycle{
+	Request: awsutil.Request{
+		Method:     "GET",
+		RequestURI: "/convox-settings/system/workers.json",
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body:       `[{"name":"monitor","duration":2000000000,"interval":300000000000,"last-run":"2026-01-02T03:04:05Z","runs":1},{"name":"spot-replace","disabled":true}]`,
+	},
+}
+
+var cycleWorkersHead = awsutil.Cycle{
+	Request: awsutil.Request{
+		Method:     "HEAD",
+		RequestURI: "/convox-settings/system/workers.json",
+	},
+	Response: awsutil.Response{
+		StatusCode: 200,
+		Body:       "",
+	},
+}
+
+var cycleWorkersHeadMissing = awsutil.Cycle{
+	Request: awsutil.Request{
+		Method:     "HEAD",
+		RequestURI: "/convox-settings/system/workers.json",
+	},
+	Response: awsutil.Response{
+		StatusCode: 404,
+		Body:       "",
+	},
+}
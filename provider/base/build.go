@@ -7,11 +7,15 @@ import (
 	"github.com/convox/rack/pkg/structs"
 )
 
+func (p *Provider) BuildCancel(app, id string) error {
+	return fmt.Errorf("unimplemented")
+}
+
 func (p *Provider) BuildCreate(app, url string, opts structs.BuildCreateOptions) (*structs.Build, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
 
-func (p *Provider) BuildExport(app, id string, w io.Writer) error {
+func (p *Provider) BuildExport(app, id string, w io.Writer, opts structs.BuildExportOptions) error {
 	return fmt.Errorf("unimplemented")
 }
 
@@ -19,7 +23,7 @@ func (p *Provider) BuildGet(app, id string) (*structs.Build, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
 
-func (p *Provider) BuildImport(app string, r io.Reader) (*structs.Build, error) {
+func (p *Provider) BuildImport(app string, r io.Reader, opts structs.BuildImportOptions) (*structs.Build, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
 
@@ -31,6 +35,10 @@ func (p *Provider) BuildList(app string, opts structs.BuildListOptions) (structs
 	return nil, fmt.Errorf("unimplemented")
 }
 
+func (p *Provider) BuildSbom(app, id string, opts structs.BuildSbomOptions) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
 func (p *Provider) BuildUpdate(app, id string, opts structs.BuildUpdateOptions) (*structs.Build, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
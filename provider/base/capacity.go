@@ -9,3 +9,7 @@ import (
 func (p *Provider) CapacityGet() (*structs.Capacity, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
+
+func (p *Provider) CapacityPlan(opts structs.CapacityPlanOptions) (*structs.CapacityPlan, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
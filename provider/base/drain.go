@@ -0,0 +1,23 @@
+package base
+
+import (
+	"fmt"
+
+	"github.com/convox/rack/pkg/structs"
+)
+
+func (p *Provider) DrainCreate(app, name, url string, opts structs.DrainCreateOptions) (*structs.Drain, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) DrainDelete(app, name string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) DrainList(app string) (structs.Drains, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) DrainTest(app, name string) error {
+	return fmt.Errorf("unimplemented")
+}
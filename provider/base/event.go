@@ -6,6 +6,10 @@ import (
 	"github.com/convox/rack/pkg/structs"
 )
 
+func (p *Provider) EventList(opts structs.EventListOptions) (structs.Events, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
 func (p *Provider) EventSend(action string, opts structs.EventSendOptions) error {
 	return fmt.Errorf("unimplemented")
 }
@@ -7,6 +7,10 @@ import (
 	"github.com/convox/rack/pkg/structs"
 )
 
+func (p *Provider) InstanceDrain(id string, opts structs.InstanceDrainOptions) error {
+	return fmt.Errorf("unimplemented")
+}
+
 func (p *Provider) InstanceKeyroll() error {
 	return fmt.Errorf("unimplemented")
 }
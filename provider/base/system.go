@@ -43,6 +43,10 @@ func (p *Provider) SystemUpdate(opts structs.SystemUpdateOptions) error {
 	return fmt.Errorf("unimplemented")
 }
 
+func (p *Provider) SystemWorkers() (structs.Workers, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
 func (p *Provider) SystemJwtSignKey() (string, error) {
 	return "", fmt.Errorf("unimplemented")
 }
@@ -0,0 +1,19 @@
+package base
+
+import (
+	"fmt"
+
+	"github.com/convox/rack/pkg/structs"
+)
+
+func (p *Provider) WebhookDeliveryList(name string, opts structs.WebhookDeliveryListOptions) (structs.WebhookDeliveries, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) WebhookPreview(name string, opts structs.WebhookPreviewOptions) (*structs.WebhookPreview, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) WebhookRedeliver(name, id string) error {
+	return fmt.Errorf("unimplemented")
+}
@@ -3,8 +3,10 @@ package sdk_test
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -86,6 +88,76 @@ func TestBuildCreateUpload(t *testing.T) {
 	})
 }
 
+func TestBuildImportChunked(t *testing.T) {
+	app := "app1"
+	build := structs.Build{
+		Id:  "1",
+		App: app,
+	}
+
+	size := sdk.BuildImportChunkSize
+	sdk.BuildImportChunkSize = 4
+	defer func() { sdk.BuildImportChunkSize = size }()
+
+	// chunks are stored under the sha256 of the archive
+	prefix := "tmp/import/eebf4d63106600df100c5085c1aeec5fe6e08c6100a81e8fb647d9ccba9584d8/"
+
+	// sha256 of the first chunk, which an earlier upload stored
+	first := prefix + "88d4266fd4e6338d13b845fcf289579d209c897823b9217da3e161936f031589"
+
+	stored := map[string]string{}
+
+	s := stdapi.New("api", "api")
+	s.Route("GET", fmt.Sprintf("/apps/%s/objects", app), func(c *stdapi.Context) error {
+		require.Equal(t, prefix, c.Query("prefix"))
+		return c.RenderJSON([]string{first})
+	})
+
+	s.Route("POST", fmt.Sprintf("/apps/%s/objects/{key:.*}", app), func(c *stdapi.Context) error {
+		b, err := io.ReadAll(c.Request().Body)
+		require.NoError(t, err)
+		stored[c.Var("key")] = string(b)
+		return c.RenderJSON(structs.Object{Url: fmt.Sprintf("object://%s/%s", app, c.Var("key"))})
+	})
+
+	s.Route("POST", fmt.Sprintf("/apps/%s/builds/import", app), func(c *stdapi.Context) error {
+		require.Equal(t, "true", c.Query("verify"))
+		require.Equal(t, prefix+"index.json", c.Query("index"))
+
+		var index structs.BuildImportIndex
+		require.NoError(t, json.Unmarshal([]byte(stored[c.Query("index")]), &index))
+		require.Len(t, index.Chunks, 4)
+		require.Equal(t, first, index.Chunks[0].Key)
+		require.Equal(t, int64(2), index.Chunks[3].Size)
+
+		// the stored chunk and the repeated one are not uploaded again
+		require.Len(t, stored, 3)
+
+		data := ""
+		for _, chunk := range index.Chunks[1:] {
+			data += stored[chunk.Key]
+		}
+		require.Equal(t, "testtestte", data)
+
+		return c.RenderJSON(build)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.BuildImportChunked(app, bytes.NewReader([]byte("abcdtesttestte")), structs.BuildImportOptions{Verify: options.Bool(true)})
+		require.NoError(t, err)
+		require.Equal(t, &build, got)
+	})
+
+	// a reader that can not be read twice is the same upload
+	stored = map[string]string{}
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.BuildImportChunked(app, io.MultiReader(strings.NewReader("abcdtest"), strings.NewReader("testte")), structs.BuildImportOptions{Verify: options.Bool(true)})
+		require.NoError(t, err)
+		require.Equal(t, &build, got)
+	})
+}
+
 func TestBuildImportMultipart(t *testing.T) {
 	app := "app1"
 	build := structs.Build{
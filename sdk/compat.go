@@ -2,9 +2,13 @@ package sdk
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math/rand"
+	"os"
 	"strconv"
 
 	"github.com/convox/rack/pkg/structs"
@@ -65,6 +69,145 @@ func (c *Client) BuildCreateUpload(app string, r io.Reader, opts structs.BuildCr
 	return b, nil
 }
 
+// BuildImportChunkSize is the size of the objects BuildImportChunked uploads
+// a build archive as
+var BuildImportChunkSize = 32 * 1024 * 1024
+
+// BuildImportChunked uploads a build archive as a series of objects and then
+// imports it from them. The objects are stored under the checksum of the
+// archive and named after their own so that running an interrupted upload
+// again skips the chunks already stored, while an import of another archive
+// never shares or removes them.
+func (c *Client) BuildImportChunked(app string, r io.Reader, opts structs.BuildImportOptions) (*structs.Build, error) {
+	rs, err := importSeeker(r)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, rs); err != nil {
+		return nil, err
+	}
+
+	if _, err := rs.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("tmp/import/%x/", h.Sum(nil))
+
+	keys, err := c.ObjectList(app, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := map[string]bool{}
+
+	for _, key := range keys {
+		stored[key] = true
+	}
+
+	index := structs.BuildImportIndex{Chunks: []structs.BuildImportChunk{}}
+
+	buf := make([]byte, BuildImportChunkSize)
+
+	for {
+		n, err := io.ReadFull(rs, buf)
+		if n > 0 {
+			sum := sha256.Sum256(buf[:n])
+
+			chunk := structs.BuildImportChunk{
+				Key:    fmt.Sprintf("%s%x", prefix, sum),
+				Sha256: hex.EncodeToString(sum[:]),
+				Size:   int64(n),
+			}
+
+			if !stored[chunk.Key] {
+				if _, err := c.ObjectStore(app, chunk.Key, bytes.NewReader(buf[:n]), structs.ObjectStoreOptions{}); err != nil {
+					return nil, err
+				}
+
+				stored[chunk.Key] = true
+			}
+
+			index.Chunks = append(index.Chunks, chunk)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+
+	key := prefix + "index.json"
+
+	if _, err := c.ObjectStore(app, key, bytes.NewReader(data), structs.ObjectStoreOptions{}); err != nil {
+		return nil, err
+	}
+
+	opts.Index = &key
+
+	return c.BuildImport(app, nil, opts)
+}
+
+// importSeeker returns r if it can be read again from where it is, otherwise
+// it copies r to a temporary file that is removed when closed
+func importSeeker(r io.Reader) (io.ReadSeekCloser, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if _, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			return nopSeekCloser{rs}, nil
+		}
+	}
+
+	fd, err := os.CreateTemp("", "import-")
+	if err != nil {
+		return nil, err
+	}
+
+	tf := tempFile{fd}
+
+	if _, err := io.Copy(fd, r); err != nil {
+		tf.Close()
+		return nil, err
+	}
+
+	if _, err := fd.Seek(0, io.SeekStart); err != nil {
+		tf.Close()
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+type tempFile struct {
+	*os.File
+}
+
+func (t tempFile) Close() error {
+	t.File.Close()
+	return os.Remove(t.Name())
+}
+
 func (c *Client) BuildImportMultipart(app string, r io.Reader) (*structs.Build, error) {
 	data, err := io.ReadAll(r)
 	if err != nil {
@@ -17,6 +17,7 @@ type Interface interface {
 	AppParametersGet(string) (map[string]string, error)
 	AppParametersSet(string, map[string]string) error
 	BuildCreateUpload(string, io.Reader, structs.BuildCreateOptions) (*structs.Build, error)
+	BuildImportChunked(string, io.Reader, structs.BuildImportOptions) (*structs.Build, error)
 	BuildImportMultipart(string, io.Reader) (*structs.Build, error)
 	BuildImportUrl(string, io.Reader) (*structs.Build, error)
 	CertificateCreateClassic(string, string, structs.CertificateCreateOptions) (*structs.Certificate, error)
@@ -118,6 +118,16 @@ func (c *Client) AppUpdate(name string, opts structs.AppUpdateOptions) error {
 	return err
 }
 
+func (c *Client) BuildCancel(app string, id string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Post(fmt.Sprintf("/apps/%s/builds/%s/cancel", app, id), ro, nil)
+
+	return err
+}
+
 func (c *Client) BuildCreate(app string, url string, opts structs.BuildCreateOptions) (*structs.Build, error) {
 	var err error
 
@@ -135,10 +145,13 @@ func (c *Client) BuildCreate(app string, url string, opts structs.BuildCreateOpt
 	return v, err
 }
 
-func (c *Client) BuildExport(app string, id string, w io.Writer) error {
+func (c *Client) BuildExport(app string, id string, w io.Writer, opts structs.BuildExportOptions) error {
 	var err error
 
-	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return err
+	}
 
 	res, err := c.GetStream(fmt.Sprintf("/apps/%s/builds/%s.tgz", app, id), ro)
 	if err != nil {
@@ -166,10 +179,13 @@ func (c *Client) BuildGet(app string, id string) (*structs.Build, error) {
 	return v, err
 }
 
-func (c *Client) BuildImport(app string, r io.Reader) (*structs.Build, error) {
+func (c *Client) BuildImport(app string, r io.Reader, opts structs.BuildImportOptions) (*structs.Build, error) {
 	var err error
 
-	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	ro.Body = r
 
@@ -215,6 +231,26 @@ func (c *Client) BuildLogs(app string, id string, opts structs.LogsOptions) (io.
 	return v, err
 }
 
+func (c *Client) BuildSbom(app string, id string, opts structs.BuildSbomOptions) (io.ReadCloser, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v io.ReadCloser
+
+	res, err := c.GetStream(fmt.Sprintf("/apps/%s/builds/%s/sbom", app, id), ro)
+	if err != nil {
+		return nil, err
+	}
+
+	v = res.Body
+
+	return v, err
+}
+
 func (c *Client) BuildUpdate(app string, id string, opts structs.BuildUpdateOptions) (*structs.Build, error) {
 	var err error
 
@@ -242,6 +278,21 @@ func (c *Client) CapacityGet() (*structs.Capacity, error) {
 	return v, err
 }
 
+func (c *Client) CapacityPlan(opts structs.CapacityPlanOptions) (*structs.CapacityPlan, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *structs.CapacityPlan
+
+	err = c.Get(fmt.Sprintf("/system/capacity/plan"), ro, &v)
+
+	return v, err
+}
+
 func (c *Client) CertificateApply(app string, service string, port int, id string) error {
 	var err error
 
@@ -308,6 +359,71 @@ func (c *Client) CertificateList() (structs.Certificates, error) {
 	return v, err
 }
 
+func (c *Client) DrainCreate(app string, name string, url string, opts structs.DrainCreateOptions) (*structs.Drain, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ro.Params["name"] = name
+	ro.Params["url"] = url
+
+	var v *structs.Drain
+
+	err = c.Post(fmt.Sprintf("/apps/%s/drains", app), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) DrainDelete(app string, name string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Delete(fmt.Sprintf("/apps/%s/drains/%s", app, name), ro, nil)
+
+	return err
+}
+
+func (c *Client) DrainList(app string) (structs.Drains, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v structs.Drains
+
+	err = c.Get(fmt.Sprintf("/apps/%s/drains", app), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) DrainTest(app string, name string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Post(fmt.Sprintf("/apps/%s/drains/%s/test", app, name), ro, nil)
+
+	return err
+}
+
+func (c *Client) EventList(opts structs.EventListOptions) (structs.Events, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v structs.Events
+
+	err = c.Get(fmt.Sprintf("/events"), ro, &v)
+
+	return v, err
+}
+
 func (c *Client) EventSend(action string, opts structs.EventSendOptions) error {
 	var err error
 
@@ -371,6 +487,19 @@ func (c *Client) Initialize(opts structs.ProviderOptions) error {
 	return err
 }
 
+func (c *Client) InstanceDrain(id string, opts structs.InstanceDrainOptions) error {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	err = c.Post(fmt.Sprintf("/instances/%s/drain", id), ro, nil)
+
+	return err
+}
+
 func (c *Client) InstanceKeyroll() error {
 	var err error
 
@@ -1038,6 +1167,58 @@ func (c *Client) SystemUpdate(opts structs.SystemUpdateOptions) error {
 	return err
 }
 
+func (c *Client) SystemWorkers() (structs.Workers, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v structs.Workers
+
+	err = c.Get(fmt.Sprintf("/system/workers"), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) WebhookDeliveryList(name string, opts structs.WebhookDeliveryListOptions) (structs.WebhookDeliveries, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v structs.WebhookDeliveries
+
+	err = c.Get(fmt.Sprintf("/resources/%s/deliveries", name), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) WebhookPreview(name string, opts structs.WebhookPreviewOptions) (*structs.WebhookPreview, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *structs.WebhookPreview
+
+	err = c.Post(fmt.Sprintf("/resources/%s/preview", name), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) WebhookRedeliver(name string, id string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Post(fmt.Sprintf("/resources/%s/deliveries/%s/redeliver", name, id), ro, nil)
+
+	return err
+}
+
 func (c *Client) Workers() error {
 	err := fmt.Errorf("not available via api")
 	return err
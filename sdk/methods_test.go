@@ -199,6 +199,36 @@ func TestAppUpdate(t *testing.T) {
 	})
 }
 
+func TestBuildCancel(t *testing.T) {
+	app := "app1"
+	id := "id1"
+
+	s := stdapi.New("api", "api")
+	s.Route("POST", fmt.Sprintf("/apps/%s/builds/%s/cancel", app, id), func(c *stdapi.Context) error {
+		return c.RenderOK()
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		err := c.BuildCancel(app, id)
+		require.NoError(t, err)
+	})
+}
+
+func TestBuildCancelError(t *testing.T) {
+	app := "app1"
+	id := "id1"
+
+	s := stdapi.New("api", "api")
+	s.Route("POST", fmt.Sprintf("/apps/%s/builds/%s/cancel", app, id), func(c *stdapi.Context) error {
+		return fmt.Errorf("build id1 is complete")
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		err := c.BuildCancel(app, id)
+		require.EqualError(t, err, "build id1 is complete")
+	})
+}
+
 func TestBuildCreate(t *testing.T) {
 	b := &structs.Build{
 		App:         "app1",
@@ -245,7 +275,7 @@ func TestBuildExport(t *testing.T) {
 
 	testServer(t, s, func(c *sdk.Client) {
 		var b bytes.Buffer
-		err := c.BuildExport(app, id, &b)
+		err := c.BuildExport(app, id, &b, structs.BuildExportOptions{})
 		require.NoError(t, err)
 		require.Equal(t, "test", b.String())
 	})
@@ -296,7 +326,7 @@ func TestBuildImport(t *testing.T) {
 	})
 
 	testServer(t, s, func(c *sdk.Client) {
-		got, err := c.BuildImport(bld.App, bytes.NewReader([]byte("test")))
+		got, err := c.BuildImport(bld.App, bytes.NewReader([]byte("test")), structs.BuildImportOptions{})
 		require.NoError(t, err)
 		require.Equal(t, bld, got)
 	})
@@ -350,6 +380,29 @@ func TestBuildLogs(t *testing.T) {
 	})
 }
 
+func TestBuildSbom(t *testing.T) {
+	app := "app1"
+	id := "id1"
+	s := stdapi.New("api", "api")
+	s.Route("GET", "/racks", func(c *stdapi.Context) error {
+		return c.RenderOK()
+	})
+	s.Route("GET", fmt.Sprintf("/apps/%s/builds/%s/sbom", app, id), func(c *stdapi.Context) error {
+		require.Equal(t, "web", c.Query("service"))
+		_, err := c.Write([]byte("sbom"))
+		return err
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.BuildSbom(app, id, structs.BuildSbomOptions{
+			Service: options.String("web"),
+		})
+		require.NoError(t, err)
+		b, _ := io.ReadAll(got)
+		require.Equal(t, "sbom", string(b))
+	})
+}
+
 func TestBuildUpdate(t *testing.T) {
 	b := &structs.Build{
 		App:         "app1",
@@ -405,6 +458,28 @@ func TestCapacityGet(t *testing.T) {
 	})
 }
 
+func TestCapacityPlan(t *testing.T) {
+	cp := &structs.CapacityPlan{
+		Fits:              true,
+		Instances:         3,
+		InstancesRequired: 3,
+		Pending:           structs.CapacityPendings{},
+	}
+
+	s := stdapi.New("api", "api")
+	s.Route("GET", "/system/capacity/plan", func(c *stdapi.Context) error {
+		require.Equal(t, "app1", c.Query("app"))
+		require.Equal(t, "R1234567", c.Query("release"))
+		return c.RenderJSON(cp)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.CapacityPlan(structs.CapacityPlanOptions{App: options.String("app1"), Release: options.String("R1234567")})
+		require.NoError(t, err)
+		require.Equal(t, cp, got)
+	})
+}
+
 func TestCertificateApply(t *testing.T) {
 	app := "app1"
 	service := "web"
@@ -497,6 +572,66 @@ func TestCertificateList(t *testing.T) {
 	})
 }
 
+func TestDrainCreate(t *testing.T) {
+	app := "app1"
+	opts := structs.DrainCreateOptions{
+		Regex:    options.String("error"),
+		Services: &[]string{"web", "worker"},
+	}
+	drain := structs.Drain{App: app, Name: "drain1", Regex: "error", Services: []string{"web", "worker"}, Type: "https", Url: "https://logs.example.org"}
+
+	s := stdapi.New("api", "api")
+	s.Route("POST", fmt.Sprintf("/apps/%s/drains", app), func(c *stdapi.Context) error {
+		require.Equal(t, "drain1", c.Form("name"))
+		require.Equal(t, "https://logs.example.org", c.Form("url"))
+		require.Equal(t, "error", c.Form("regex"))
+		require.Equal(t, "web,worker", c.Form("services"))
+		return c.RenderJSON(drain)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.DrainCreate(app, "drain1", "https://logs.example.org", opts)
+		require.NoError(t, err)
+		require.Equal(t, &drain, got)
+	})
+}
+
+func TestDrainList(t *testing.T) {
+	app := "app1"
+	drains := structs.Drains{{App: app, Name: "drain1", Type: "s3", Url: "s3://bucket/logs"}}
+
+	s := stdapi.New("api", "api")
+	s.Route("GET", fmt.Sprintf("/apps/%s/drains", app), func(c *stdapi.Context) error {
+		return c.RenderJSON(drains)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.DrainList(app)
+		require.NoError(t, err)
+		require.Equal(t, drains, got)
+	})
+}
+
+func TestEventList(t *testing.T) {
+	es := structs.Events{{Id: "event1", Action: "app:create", Data: map[string]string{"name": "app1"}, Status: "success"}}
+
+	s := stdapi.New("api", "api")
+	s.Route("GET", "/events", func(c *stdapi.Context) error {
+		require.Equal(t, "release:*", c.Query("action"))
+		require.Equal(t, "1h0m0s", c.Query("since"))
+		return c.RenderJSON(es)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.EventList(structs.EventListOptions{
+			Action: options.String("release:*"),
+			Since:  options.Duration(time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, es, got)
+	})
+}
+
 func TestEventSend(t *testing.T) {
 	action := "delete"
 	opts := structs.EventSendOptions{
@@ -577,6 +712,20 @@ func TestFilesUpload(t *testing.T) {
 	})
 }
 
+func TestInstanceDrain(t *testing.T) {
+	s := stdapi.New("api", "api")
+	s.Route("POST", "/instances/instance1/drain", func(c *stdapi.Context) error {
+		require.Equal(t, "true", c.Form("terminate"))
+		require.Equal(t, "300", c.Form("timeout"))
+		return c.RenderOK()
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		err := c.InstanceDrain("instance1", structs.InstanceDrainOptions{Terminate: options.Bool(true), Timeout: options.Int(300)})
+		require.NoError(t, err)
+	})
+}
+
 func TestInstanceList(t *testing.T) {
 	is := structs.Instances{{
 		Agent:     true,
@@ -1493,3 +1642,75 @@ func TestSystemUpdate(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestSystemWorkers(t *testing.T) {
+	ws := structs.Workers{{
+		Name:     "monitor",
+		Interval: 5 * time.Minute,
+		LastRun:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Runs:     1,
+	}}
+
+	s := stdapi.New("api", "api")
+	s.Route("GET", "/system/workers", func(c *stdapi.Context) error {
+		return c.RenderJSON(ws)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.SystemWorkers()
+		require.NoError(t, err)
+		require.Equal(t, ws, got)
+	})
+}
+
+func TestWebhookDeliveryList(t *testing.T) {
+	ds := structs.WebhookDeliveries{{Id: "delivery1", Action: "app:create", Attempts: []structs.WebhookAttempt{}, Status: "success"}}
+
+	s := stdapi.New("api", "api")
+	s.Route("GET", fmt.Sprintf("/resources/%s/deliveries", "hook1"), func(c *stdapi.Context) error {
+		require.Equal(t, "5", c.Query("limit"))
+		require.Equal(t, "success", c.Query("status"))
+		return c.RenderJSON(ds)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.WebhookDeliveryList("hook1", structs.WebhookDeliveryListOptions{
+			Limit:  options.Int(5),
+			Status: options.String("success"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, ds, got)
+	})
+}
+
+func TestWebhookPreview(t *testing.T) {
+	wp := &structs.WebhookPreview{ContentType: "text/plain", Matched: true, Payload: "release:promote"}
+
+	s := stdapi.New("api", "api")
+	s.Route("POST", fmt.Sprintf("/resources/%s/preview", "hook1"), func(c *stdapi.Context) error {
+		require.Equal(t, "release:*", c.Form("action"))
+		require.Equal(t, "{{ .Action }}", c.Form("template"))
+		return c.RenderJSON(wp)
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		got, err := c.WebhookPreview("hook1", structs.WebhookPreviewOptions{
+			Action:   options.String("release:*"),
+			Template: options.String("{{ .Action }}"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, wp, got)
+	})
+}
+
+func TestWebhookRedeliver(t *testing.T) {
+	s := stdapi.New("api", "api")
+	s.Route("POST", fmt.Sprintf("/resources/%s/deliveries/%s/redeliver", "hook1", "delivery1"), func(c *stdapi.Context) error {
+		return c.RenderOK()
+	})
+
+	testServer(t, s, func(c *sdk.Client) {
+		err := c.WebhookRedeliver("hook1", "delivery1")
+		require.NoError(t, err)
+	})
+}